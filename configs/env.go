@@ -1,7 +1,11 @@
 package configs
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	models "go_blogs/models"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -11,6 +15,78 @@ var Env models.EnvVar
 func setDefaultConfig(v *viper.Viper) {
 	defaultPort := 8080
 	v.SetDefault("PORT", defaultPort)
+
+	defaultAppURL := "http://localhost:8080"
+	v.SetDefault("APP_URL", defaultAppURL)
+
+	defaultSchedulerInterval := "30s"
+	v.SetDefault("SCHEDULER_INTERVAL", defaultSchedulerInterval)
+
+	defaultTrashRetention := "720h"
+	v.SetDefault("TRASH_RETENTION", defaultTrashRetention)
+
+	defaultSearchBackend := "memory"
+	v.SetDefault("SEARCH_BACKEND", defaultSearchBackend)
+
+	defaultMailer := "log"
+	v.SetDefault("MAILER", defaultMailer)
+
+	defaultMailFrom := "Go Blogs <no-reply@localhost>"
+	v.SetDefault("MAIL_FROM", defaultMailFrom)
+
+	defaultSMTPPort := 587
+	v.SetDefault("SMTP_PORT", defaultSMTPPort)
+
+	defaultEmailVerificationTTL := "48h"
+	v.SetDefault("EMAIL_VERIFICATION_TTL", defaultEmailVerificationTTL)
+
+	defaultRequireVerifiedEmailToPost := true
+	v.SetDefault("REQUIRE_VERIFIED_EMAIL_TO_POST", defaultRequireVerifiedEmailToPost)
+
+	defaultPasswordResetTTL := "1h"
+	v.SetDefault("PASSWORD_RESET_TTL", defaultPasswordResetTTL)
+
+	defaultLoginFailureWindow := "1h"
+	v.SetDefault("LOGIN_FAILURE_WINDOW", defaultLoginFailureWindow)
+
+	defaultLoginBackoffAfter := 3
+	v.SetDefault("LOGIN_BACKOFF_AFTER", defaultLoginBackoffAfter)
+
+	defaultLoginBackoffBase := "1s"
+	v.SetDefault("LOGIN_BACKOFF_BASE", defaultLoginBackoffBase)
+
+	defaultLoginBackoffMax := "5m"
+	v.SetDefault("LOGIN_BACKOFF_MAX", defaultLoginBackoffMax)
+
+	defaultLoginLockoutThreshold := 10
+	v.SetDefault("LOGIN_LOCKOUT_THRESHOLD", defaultLoginLockoutThreshold)
+
+	defaultLoginIPLockoutThreshold := 100
+	v.SetDefault("LOGIN_IP_LOCKOUT_THRESHOLD", defaultLoginIPLockoutThreshold)
+
+	defaultLoginLockoutDuration := "30m"
+	v.SetDefault("LOGIN_LOCKOUT_DURATION", defaultLoginLockoutDuration)
+
+	defaultTOTPIssuer := "Go Blogs"
+	v.SetDefault("TOTP_ISSUER", defaultTOTPIssuer)
+
+	defaultWebAuthnRPID := "localhost"
+	v.SetDefault("WEBAUTHN_RP_ID", defaultWebAuthnRPID)
+
+	defaultWebAuthnRPName := "Go Blogs"
+	v.SetDefault("WEBAUTHN_RP_NAME", defaultWebAuthnRPName)
+
+	defaultAuthMode := "session"
+	v.SetDefault("AUTH_MODE", defaultAuthMode)
+
+	defaultJWTAlgorithm := "HS256"
+	v.SetDefault("JWT_ALGORITHM", defaultJWTAlgorithm)
+
+	defaultJWTAccessTokenTTL := "15m"
+	v.SetDefault("JWT_ACCESS_TOKEN_TTL", defaultJWTAccessTokenTTL)
+
+	defaultJWTRefreshTokenTTL := "720h"
+	v.SetDefault("JWT_REFRESH_TOKEN_TTL", defaultJWTRefreshTokenTTL)
 }
 
 func InitEnv() {
@@ -23,10 +99,85 @@ func InitEnv() {
 	setDefaultConfig(viper.GetViper())
 
 	Env.AppEnv = viper.GetString("APP_ENV")
+	Env.AppURL = viper.GetString("APP_URL")
 	Env.Port = viper.GetInt("PORT")
 
+	Env.SchedulerInterval = viper.GetDuration("SCHEDULER_INTERVAL")
+	Env.TrashRetention = viper.GetDuration("TRASH_RETENTION")
+
+	Env.SearchBackend = viper.GetString("SEARCH_BACKEND")
+
+	Env.CursorSecret = viper.GetString("CURSOR_SECRET")
+	if Env.CursorSecret == "" {
+		Env.CursorSecret = randomSecret()
+		fmt.Println("CURSOR_SECRET is not set, cursors will not work across restarts or replicas")
+	}
+
+	Env.Mailer = viper.GetString("MAILER")
+	Env.MailFrom = viper.GetString("MAIL_FROM")
+	Env.MailOutboxDir = viper.GetString("MAIL_OUTBOX_DIR")
+	Env.SMTPHost = viper.GetString("SMTP_HOST")
+	Env.SMTPPort = viper.GetInt("SMTP_PORT")
+	Env.SMTPUsername = viper.GetString("SMTP_USERNAME")
+	Env.SMTPPassword = viper.GetString("SMTP_PASSWORD")
+
+	Env.EmailTokenSecret = viper.GetString("EMAIL_TOKEN_SECRET")
+	if Env.EmailTokenSecret == "" {
+		Env.EmailTokenSecret = randomSecret()
+		fmt.Println("EMAIL_TOKEN_SECRET is not set, verification links will not work across restarts or replicas")
+	}
+	Env.EmailVerificationTTL = viper.GetDuration("EMAIL_VERIFICATION_TTL")
+	Env.RequireVerifiedEmailToLogin = viper.GetBool("REQUIRE_VERIFIED_EMAIL_TO_LOGIN")
+	Env.RequireVerifiedEmailToPost = viper.GetBool("REQUIRE_VERIFIED_EMAIL_TO_POST")
+	Env.PasswordResetTTL = viper.GetDuration("PASSWORD_RESET_TTL")
+
+	Env.LoginFailureWindow = viper.GetDuration("LOGIN_FAILURE_WINDOW")
+	Env.LoginBackoffAfter = viper.GetInt64("LOGIN_BACKOFF_AFTER")
+	Env.LoginBackoffBase = viper.GetDuration("LOGIN_BACKOFF_BASE")
+	Env.LoginBackoffMax = viper.GetDuration("LOGIN_BACKOFF_MAX")
+	Env.LoginLockoutThreshold = viper.GetInt64("LOGIN_LOCKOUT_THRESHOLD")
+	Env.LoginIPLockoutThreshold = viper.GetInt64("LOGIN_IP_LOCKOUT_THRESHOLD")
+	Env.LoginLockoutDuration = viper.GetDuration("LOGIN_LOCKOUT_DURATION")
+
+	Env.SecretEncryptionKey = viper.GetString("SECRET_ENCRYPTION_KEY")
+	if Env.SecretEncryptionKey == "" {
+		Env.SecretEncryptionKey = randomSecret()
+		fmt.Println("SECRET_ENCRYPTION_KEY is not set, two-factor authentication enrolled now will break on restart")
+	}
+	Env.TOTPIssuer = viper.GetString("TOTP_ISSUER")
+
+	Env.WebAuthnRPID = viper.GetString("WEBAUTHN_RP_ID")
+	Env.WebAuthnRPName = viper.GetString("WEBAUTHN_RP_NAME")
+	// origins default to the app itself
+	Env.WebAuthnRPOrigins = []string{Env.AppURL}
+	if origins := viper.GetString("WEBAUTHN_RP_ORIGINS"); origins != "" {
+		Env.WebAuthnRPOrigins = strings.Split(origins, ",")
+	}
+
+	Env.BootstrapAdminEmail = viper.GetString("BOOTSTRAP_ADMIN_EMAIL")
+
+	Env.AuthMode = viper.GetString("AUTH_MODE")
+	Env.JWTAlgorithm = viper.GetString("JWT_ALGORITHM")
+	// the first secret or key signs and the rest only verify, so that they can be rotated
+	if secrets := viper.GetString("JWT_SECRETS"); secrets != "" {
+		Env.JWTSecrets = strings.Split(secrets, ",")
+	}
+	if keyFiles := viper.GetString("JWT_KEY_FILES"); keyFiles != "" {
+		Env.JWTKeyFiles = strings.Split(keyFiles, ",")
+	}
+	Env.JWTAccessTokenTTL = viper.GetDuration("JWT_ACCESS_TOKEN_TTL")
+	Env.JWTRefreshTokenTTL = viper.GetDuration("JWT_REFRESH_TOKEN_TTL")
+
 	Env.MongoEndpoint = viper.GetString("MONGO_ENDPOINT")
 	Env.MongoUsername = viper.GetString("MONGO_USERNAME")
 	Env.MongoPassword = viper.GetString("MONGO_PASSWORD")
 	Env.MongoDatabase = viper.GetString("MONGO_DATABASE")
 }
+
+func randomSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(secret)
+}
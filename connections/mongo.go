@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"go_blogs/configs"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -36,14 +35,12 @@ func NewMongoCollection(database *mongo.Database, collectionName string) *mongo.
 		panic(err)
 	}
 
-	collection := database.Collection(collectionName)
-	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys: bson.M{
-			"email": 1,
-		},
-	})
+	return database.Collection(collectionName)
+}
+
+func CreateMongoIndexes(collection *mongo.Collection, indexes ...mongo.IndexModel) {
+	_, err := collection.Indexes().CreateMany(context.TODO(), indexes)
 	if err != nil {
 		panic(err)
 	}
-	return collection
 }
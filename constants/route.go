@@ -2,15 +2,81 @@ package constants
 
 type _RouteName struct {
 	// auth
-	LOGIN    string
-	REGISTER string
+	LOGIN                     string
+	LOGIN_TWO_FACTOR          string
+	REGISTER                  string
+	VERIFY_EMAIL              string
+	RESEND_EMAIL_VERIFICATION string
+	FORGOT_PASSWORD           string
+	RESET_PASSWORD            string
+	UNLOCK_LOGIN              string
+	CONFIRM_EMAIL_CHANGE      string
+	REFRESH_TOKEN             string
 
 	// blogs
-	GET_BLOGS      string
-	GET_BLOG_BY_ID string
-	CREATE_BLOG    string
-	UPDATE_BLOG    string
-	DELETE_BLOG    string
+	GET_BLOGS        string
+	SEARCH_BLOGS     string
+	GET_BLOG_BY_ID   string
+	GET_BLOG_BY_SLUG string
+	CREATE_BLOG      string
+	UPDATE_BLOG      string
+	DELETE_BLOG      string
+	SUBMIT_BLOG      string
+	PUBLISH_BLOG     string
+	UNPUBLISH_BLOG   string
+	ARCHIVE_BLOG     string
+	RESTORE_BLOG     string
+
+	// blog revisions
+	GET_BLOG_REVISIONS    string
+	GET_BLOG_REVISION     string
+	DIFF_BLOG_REVISIONS   string
+	RESTORE_BLOG_REVISION string
+
+	// tags
+	GET_TAGS string
+
+	// categories
+	GET_CATEGORIES  string
+	CREATE_CATEGORY string
+	UPDATE_CATEGORY string
+	DELETE_CATEGORY string
+
+	// users
+	GET_USER           string
+	GET_USER_BY_HANDLE string
+	GET_USER_BLOGS     string
+
+	// me
+	GET_MY_BLOGS    string
+	GET_MY_TRASH    string
+	UPDATE_ME       string
+	CHANGE_PASSWORD string
+	CHANGE_EMAIL    string
+	CONFIRM_TOTP    string
+	DISABLE_TOTP    string
+
+	// passkeys
+	FINISH_PASSKEY_REGISTRATION string
+	DELETE_PASSKEY              string
+
+	// sessions
+	DELETE_MY_SESSION  string
+	DELETE_MY_SESSIONS string
+
+	// access tokens
+	CREATE_ACCESS_TOKEN string
+	DELETE_ACCESS_TOKEN string
+
+	// admin
+	GET_USERS            string
+	GET_USER_STATS       string
+	UPDATE_USER_ROLE     string
+	SUSPEND_USER         string
+	UNSUSPEND_USER       string
+	FORCE_PASSWORD_RESET string
+	IMPERSONATE_USER     string
+	GET_AUDIT_LOGS       string
 }
 
 var RouteName _RouteName
@@ -18,14 +84,80 @@ var RouteName _RouteName
 func init() {
 	RouteName = _RouteName{
 		// auth
-		LOGIN:    "login",
-		REGISTER: "register",
+		LOGIN:                     "login",
+		LOGIN_TWO_FACTOR:          "login_two_factor",
+		REGISTER:                  "register",
+		VERIFY_EMAIL:              "verify_email",
+		RESEND_EMAIL_VERIFICATION: "resend_email_verification",
+		FORGOT_PASSWORD:           "forgot_password",
+		RESET_PASSWORD:            "reset_password",
+		UNLOCK_LOGIN:              "unlock_login",
+		CONFIRM_EMAIL_CHANGE:      "confirm_email_change",
+		REFRESH_TOKEN:             "refresh_token",
 
 		// blogs
-		GET_BLOGS:      "get_blogs",
-		GET_BLOG_BY_ID: "get_blog_by_id",
-		CREATE_BLOG:    "create_blog",
-		UPDATE_BLOG:    "update_blog",
-		DELETE_BLOG:    "delete_blog",
+		GET_BLOGS:        "get_blogs",
+		SEARCH_BLOGS:     "search_blogs",
+		GET_BLOG_BY_ID:   "get_blog_by_id",
+		GET_BLOG_BY_SLUG: "get_blog_by_slug",
+		CREATE_BLOG:      "create_blog",
+		UPDATE_BLOG:      "update_blog",
+		DELETE_BLOG:      "delete_blog",
+		SUBMIT_BLOG:      "submit_blog",
+		PUBLISH_BLOG:     "publish_blog",
+		UNPUBLISH_BLOG:   "unpublish_blog",
+		ARCHIVE_BLOG:     "archive_blog",
+		RESTORE_BLOG:     "restore_blog",
+
+		// blog revisions
+		GET_BLOG_REVISIONS:    "get_blog_revisions",
+		GET_BLOG_REVISION:     "get_blog_revision",
+		DIFF_BLOG_REVISIONS:   "diff_blog_revisions",
+		RESTORE_BLOG_REVISION: "restore_blog_revision",
+
+		// tags
+		GET_TAGS: "get_tags",
+
+		// categories
+		GET_CATEGORIES:  "get_categories",
+		CREATE_CATEGORY: "create_category",
+		UPDATE_CATEGORY: "update_category",
+		DELETE_CATEGORY: "delete_category",
+
+		// users
+		GET_USER:           "get_user",
+		GET_USER_BY_HANDLE: "get_user_by_handle",
+		GET_USER_BLOGS:     "get_user_blogs",
+
+		// me
+		GET_MY_BLOGS:    "get_my_blogs",
+		GET_MY_TRASH:    "get_my_trash",
+		UPDATE_ME:       "update_me",
+		CHANGE_PASSWORD: "change_password",
+		CHANGE_EMAIL:    "change_email",
+		CONFIRM_TOTP:    "confirm_totp",
+		DISABLE_TOTP:    "disable_totp",
+
+		// passkeys
+		FINISH_PASSKEY_REGISTRATION: "finish_passkey_registration",
+		DELETE_PASSKEY:              "delete_passkey",
+
+		// sessions
+		DELETE_MY_SESSION:  "delete_my_session",
+		DELETE_MY_SESSIONS: "delete_my_sessions",
+
+		// access tokens
+		CREATE_ACCESS_TOKEN: "create_access_token",
+		DELETE_ACCESS_TOKEN: "delete_access_token",
+
+		// admin
+		GET_USERS:            "get_users",
+		GET_USER_STATS:       "get_user_stats",
+		UPDATE_USER_ROLE:     "update_user_role",
+		SUSPEND_USER:         "suspend_user",
+		UNSUSPEND_USER:       "unsuspend_user",
+		FORCE_PASSWORD_RESET: "force_password_reset",
+		IMPERSONATE_USER:     "impersonate_user",
+		GET_AUDIT_LOGS:       "get_audit_logs",
 	}
 }
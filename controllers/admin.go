@@ -0,0 +1,558 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/libs"
+	"go_blogs/mailer"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type adminController interface {
+	GetUsers(c *fiber.Ctx) error
+	GetUserStats(c *fiber.Ctx) error
+	UpdateUserRole(c *fiber.Ctx) error
+	SuspendUser(c *fiber.Ctx) error
+	UnsuspendUser(c *fiber.Ctx) error
+	ForcePasswordReset(c *fiber.Ctx) error
+	ImpersonateUser(c *fiber.Ctx) error
+	StopImpersonation(c *fiber.Ctx) error
+	GetAuditLogs(c *fiber.Ctx) error
+}
+
+type AdminController struct {
+	MongoUserColl     *mongo.Collection
+	MongoBlogColl     *mongo.Collection
+	MongoPasskeyColl  *mongo.Collection
+	MongoAuditLogColl *mongo.Collection
+}
+
+func NewAdminControllers() adminController {
+	database := connections.MongoClient.Database(configs.Env.MongoDatabase)
+
+	userColl := database.Collection("users")
+	connections.CreateMongoIndexes(
+		userColl,
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "createdAt", Value: -1}},
+		},
+	)
+
+	auditLogColl := database.Collection("audit_logs")
+	connections.CreateMongoIndexes(
+		auditLogColl,
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "createdAt", Value: -1}},
+		},
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "actorId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "targetId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	)
+
+	migrateUserRoles(userColl)
+	bootstrapAdmin(userColl, configs.Env.BootstrapAdminEmail)
+
+	return &AdminController{
+		MongoUserColl:     userColl,
+		MongoBlogColl:     database.Collection("blogs"),
+		MongoPasskeyColl:  database.Collection("passkeys"),
+		MongoAuditLogColl: auditLogColl,
+	}
+}
+
+// migrateUserRoles gives users registered before roles existed the user role
+func migrateUserRoles(userColl *mongo.Collection) {
+	filter := bson.M{
+		"role": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"role": models.RoleUser},
+	}
+	if _, err := userColl.UpdateMany(context.TODO(), filter, update); err != nil {
+		panic(err)
+	}
+}
+
+// bootstrapAdmin makes the user with email an admin, so that a new deployment has someone to assign roles.
+// Register does the same for a user who signs up with the email later
+func bootstrapAdmin(userColl *mongo.Collection, email string) {
+	if email == "" {
+		return
+	}
+	result, err := userColl.UpdateOne(context.TODO(), bson.M{"email": email}, bson.M{"$set": bson.M{"role": models.RoleAdmin}})
+	if err != nil {
+		panic(err)
+	}
+	if result.ModifiedCount > 0 {
+		fmt.Printf("%s has been made an admin\n", email)
+	}
+}
+
+// findUser finds the user with userID, responding 404 when there is none. The user is nil when it has responded
+func (ctr *AdminController) findUser(ctx context.Context, c *fiber.Ctx, userID string) (*models.User, error) {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, utils.NewAppError(err)
+	}
+	var user *models.User
+	err = ctr.MongoUserColl.FindOne(ctx, bson.M{"_id": userObjectID}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "User not found",
+		})
+	} else if err != nil {
+		return nil, utils.NewAppError(err)
+	}
+	return user, nil
+}
+
+// findManageableUser finds the user with userID like findUser, responding 403 when they have a permission admin
+// lacks, so that those granted some of the permissions of admins cannot turn them against admins
+func (ctr *AdminController) findManageableUser(ctx context.Context, c *fiber.Ctx, admin *models.UserSessionData, userID string) (*models.User, error) {
+	user, err := ctr.findUser(ctx, c, userID)
+	if user == nil {
+		return nil, err
+	}
+	if !admin.CanAll(user.EffectivePermissions()) {
+		return nil, c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Message: "You cannot manage a user with permissions you do not have",
+		})
+	}
+	return user, nil
+}
+
+// @summary		Get users
+// @description	Search users, newest first
+// @id				GetUsers
+// @tags			admin
+// @accept			json
+// @produce		json
+// @param			q		query		string	false	"text in the email, name or handle"	maxlength(100)
+// @param			role	query		string	false	"role"								Enums(user, editor, moderator, admin)
+// @param			status	query		string	false	"account status"					Enums(active, suspended)
+// @param			from	query		int		false	"user offset"						default(0)	minimum(0)
+// @param			limit	query		int		false	"number of users"					default(10)	minimum(1)	maximum(50)
+// @success		200		{object}	models.PaginatedResponse[models.AdminUser]
+// @failure		401		{object}	models.ErrorResponse			"unauthorized"
+// @failure		403		{object}	models.ErrorResponse			"access denied"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/admin/users [get]
+func (ctr *AdminController) GetUsers(c *fiber.Ctx) error {
+	query := c.Locals("query").(*validators.GetUsersQuery)
+
+	ctx := context.TODO()
+
+	filter := bson.M{}
+	if query.Q != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query.Q), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"email": pattern},
+			bson.M{"name": pattern},
+			bson.M{"handle": pattern},
+		}
+	}
+	if query.Role != "" {
+		filter["role"] = query.Role
+	}
+	switch query.Status {
+	case "active":
+		filter["suspendedAt"] = nil
+	case "suspended":
+		filter["suspendedAt"] = bson.M{"$ne": nil}
+	}
+
+	total, totalIsEstimate, err := countPageTotal(ctx, ctr.MongoUserColl, filter)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	limit := pageLimit(query.Limit)
+	opts := options.Find().SetSkip(int64(query.From)).SetLimit(int64(limit)).SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := ctr.MongoUserColl.Find(ctx, filter, opts)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return utils.NewAppError(err)
+	}
+	adminUsers := make([]models.AdminUser, 0, len(users))
+	for i := range users {
+		adminUsers = append(adminUsers, models.NewAdminUser(&users[i]))
+	}
+
+	return respondPage(c, newOffsetPage(c, adminUsers, query.From, limit, total, totalIsEstimate))
+}
+
+// @summary		Get user stats
+// @description	Get the blogs by status, passkeys and live sessions of a user
+// @id				GetUserStats
+// @tags			admin
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"user's ID"
+// @success		200	{object}	models.UserStats
+// @failure		401	{object}	models.ErrorResponse			"unauthorized"
+// @failure		403	{object}	models.ErrorResponse			"access denied"
+// @failure		404	{object}	models.ErrorResponse			"user not found"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/admin/users/:id/stats [get]
+func (ctr *AdminController) GetUserStats(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.AdminUserParams)
+
+	ctx := context.TODO()
+
+	user, err := ctr.findUser(ctx, c, params.ID)
+	if user == nil {
+		return err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"createdBy": user.ID,
+			"deletedAt": nil,
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$status",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+	cursor, err := ctr.MongoBlogColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	var statusCounts []struct {
+		Status models.BlogStatus `bson:"_id"`
+		Count  int64             `bson:"count"`
+	}
+	if err = cursor.All(ctx, &statusCounts); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	stats := models.UserStats{
+		UserID: user.ID,
+		Blogs:  map[models.BlogStatus]int64{},
+	}
+	for _, statusCount := range statusCounts {
+		stats.Blogs[statusCount.Status] = statusCount.Count
+	}
+
+	stats.TrashedBlogs, err = ctr.MongoBlogColl.CountDocuments(ctx, bson.M{
+		"createdBy": user.ID,
+		"deletedAt": bson.M{"$ne": nil},
+	})
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	stats.Passkeys, err = ctr.MongoPasskeyColl.CountDocuments(ctx, bson.M{"userId": user.ID})
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	sessions, err := libs.GetUserSessions(ctx, user.ID, "")
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	stats.Sessions = len(sessions)
+	// sessions come most recently seen first
+	if len(sessions) > 0 {
+		stats.LastSeenAt = sessions[0].LastSeenAt
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stats)
+}
+
+// @summary		Update user role
+// @description	Assign a role to a user, along with permissions granted on top of the role. Takes effect on the user's
+// @description	sessions right away. Admins cannot grant permissions they lack, nor change the role of users who have
+// @description	permissions they lack
+// @id				UpdateUserRole
+// @tags			admin
+// @accept			json
+// @produce		json
+// @param			id			path		string		true	"user's ID"
+// @param			role		body		string		true	"role"	Enums(user, editor, moderator, admin)
+// @param			permissions	body		[]string	false	"permissions granted on top of the role"
+// @success		200			{object}	models.UserSessionData
+// @failure		401			{object}	models.ErrorResponse			"unauthorized"
+// @failure		403			{object}	models.ErrorResponse			"access denied"
+// @failure		404			{object}	models.ErrorResponse			"user not found"
+// @failure		409			{object}	models.ErrorResponse			"cannot change own role"
+// @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500			{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/admin/users/:id/role [put]
+func (ctr *AdminController) UpdateUserRole(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.AdminUserParams)
+	payload := c.Locals("payload").(*validators.UpdateUserRolePayload)
+	admin := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	// an admin demoting themselves could leave nobody to assign roles
+	if params.ID == admin.ID {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "You cannot change your own role",
+		})
+	}
+
+	user, err := ctr.findUser(ctx, c, params.ID)
+	if user == nil {
+		return err
+	}
+	// assigning roles must not let the admin take over users above them, or raise anyone above them
+	if !admin.CanAll(user.EffectivePermissions()) {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Message: "You cannot change the role of a user with permissions you do not have",
+		})
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	role := models.Role(payload.Role)
+	permissions := make([]models.Permission, 0, len(payload.Permissions))
+	for _, permission := range payload.Permissions {
+		permissions = append(permissions, models.Permission(permission))
+	}
+	if !admin.CanAll(role.Permissions()) || !admin.CanAll(permissions) {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Message: "You cannot grant permissions you do not have",
+		})
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"role":        role,
+			"permissions": permissions,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = ctr.MongoUserColl.FindOneAndUpdate(ctx, bson.M{"_id": userObjectID}, update, opts).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "User not found",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	userSessionData := models.NewUserSessionData(user)
+	if err = libs.UpdateUserSessionsData(ctx, userSessionData); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(userSessionData)
+}
+
+// @summary		Suspend user
+// @description	Suspend a user, logging them out of every session and refusing their logins until they are unsuspended.
+// @description	Users with permissions the admin lacks cannot be suspended
+// @id				SuspendUser
+// @tags			admin
+// @accept			json
+// @produce		json
+// @param			id		path		string	true	"user's ID"
+// @param			reason	body		string	false	"reason, shown to admins"	maxlength(300)
+// @success		200		{object}	models.SuccessResponse
+// @failure		401		{object}	models.ErrorResponse			"unauthorized"
+// @failure		403		{object}	models.ErrorResponse			"access denied"
+// @failure		404		{object}	models.ErrorResponse			"user not found"
+// @failure		409		{object}	models.ErrorResponse			"cannot suspend self"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/admin/users/:id/suspend [post]
+func (ctr *AdminController) SuspendUser(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.AdminUserParams)
+	payload := c.Locals("payload").(*validators.SuspendUserPayload)
+	admin := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	if params.ID == admin.ID {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "You cannot suspend yourself",
+		})
+	}
+
+	user, err := ctr.findManageableUser(ctx, c, admin, params.ID)
+	if user == nil {
+		return err
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	// the user is suspended in MongoDB first, so that no new login slips in between revoking the sessions
+	update := bson.M{
+		"$set": bson.M{
+			"suspendedAt":      time.Now(),
+			"suspensionReason": payload.Reason,
+		},
+	}
+	result, err := ctr.MongoUserColl.UpdateByID(ctx, userObjectID, update)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if result.MatchedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "User not found",
+		})
+	}
+
+	if err = libs.SuspendUser(ctx, params.ID); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "User suspended",
+	})
+}
+
+// @summary		Unsuspend user
+// @description	Let a suspended user log in again. Users with permissions the admin lacks cannot be unsuspended
+// @id				UnsuspendUser
+// @tags			admin
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"user's ID"
+// @success		200	{object}	models.SuccessResponse
+// @failure		401	{object}	models.ErrorResponse			"unauthorized"
+// @failure		403	{object}	models.ErrorResponse			"access denied"
+// @failure		404	{object}	models.ErrorResponse			"user not found"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/admin/users/:id/unsuspend [post]
+func (ctr *AdminController) UnsuspendUser(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.AdminUserParams)
+	admin := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	user, err := ctr.findManageableUser(ctx, c, admin, params.ID)
+	if user == nil {
+		return err
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	update := bson.M{
+		"$unset": bson.M{
+			"suspendedAt":      "",
+			"suspensionReason": "",
+		},
+	}
+	result, err := ctr.MongoUserColl.UpdateByID(ctx, userObjectID, update)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if result.MatchedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "User not found",
+		})
+	}
+
+	if err = libs.UnsuspendUser(ctx, params.ID); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "User unsuspended",
+	})
+}
+
+// @summary		Force password reset
+// @description	Log a user out of every session and email them a password reset link. They cannot log in until
+// @description	they have set a new password with it, or with one from ForgotPassword. Users with permissions the
+// @description	admin lacks cannot be reset
+// @id				ForcePasswordReset
+// @tags			admin
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"user's ID"
+// @success		202	{object}	models.SuccessResponse
+// @failure		401	{object}	models.ErrorResponse			"unauthorized"
+// @failure		403	{object}	models.ErrorResponse			"access denied"
+// @failure		404	{object}	models.ErrorResponse			"user not found"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/admin/users/:id/password-reset [post]
+func (ctr *AdminController) ForcePasswordReset(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.AdminUserParams)
+	admin := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	user, err := ctr.findManageableUser(ctx, c, admin, params.ID)
+	if user == nil {
+		return err
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{"passwordResetRequired": true},
+	}
+	err = ctr.MongoUserColl.FindOneAndUpdate(ctx, bson.M{"_id": userObjectID}, update).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "User not found",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	if err = libs.RevokeUserSessions(ctx, user.ID, ""); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	token, err := newPasswordResetToken(ctx, user.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	err = mailer.Default.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Please choose a new password",
+		Text: fmt.Sprintf(
+			"Hi %s,\n\nFor your security, you have been logged out and need a new password to log in again.\nOpen this link within %s to choose one:\n%s/reset-password?token=%s\n",
+			user.Name, configs.Env.PasswordResetTTL, configs.Env.AppURL, url.QueryEscape(token),
+		),
+	})
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{
+		Message: "User has been logged out and sent a password reset link",
+	})
+}
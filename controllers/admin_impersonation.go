@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"go_blogs/configs"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// recordAuditLog records that actorID took action on targetID with the request
+func (ctr *AdminController) recordAuditLog(ctx context.Context, c *fiber.Ctx, action models.AuditAction, actorID string, targetID string) error {
+	document := bson.D{
+		{Key: "_id", Value: primitive.NewObjectID()},
+		{Key: "action", Value: action},
+		{Key: "actorId", Value: actorID},
+		{Key: "targetId", Value: targetID},
+		{Key: "ip", Value: c.IP()},
+		{Key: "userAgent", Value: string(c.Request().Header.UserAgent())},
+		{Key: "createdAt", Value: time.Now()},
+	}
+	_, err := ctr.MongoAuditLogColl.InsertOne(ctx, document)
+	return err
+}
+
+// respondSessionData responds with data, the new data of the session of the request. With AUTH_MODE jwt, it comes
+// with an access token carrying it, as the one of the request does not
+func respondSessionData(c *fiber.Ctx, data models.UserSessionData) error {
+	if configs.Env.AuthMode != libs.AuthModeJWT {
+		return c.Status(fiber.StatusOK).JSON(data)
+	}
+	tokens, err := libs.IssueSessionAccessToken(c, data)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+// @summary		Impersonate user
+// @description	Turn the current session into a session of a user, to see what they see. Changing their password,
+// @description	email, two-factor authentication, passkeys or sessions is not allowed while impersonating. Users with
+// @description	a permission the admin lacks cannot be impersonated. Start and stop are recorded in the audit log.
+// @description	When AUTH_MODE is jwt, a models.TokenPair with an access token of the user is returned
+// @id				ImpersonateUser
+// @tags			admin
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"user's ID"
+// @success		200	{object}	models.UserSessionData
+// @failure		401	{object}	models.ErrorResponse			"unauthorized"
+// @failure		403	{object}	models.ErrorResponse			"access denied"
+// @failure		404	{object}	models.ErrorResponse			"user not found"
+// @failure		409	{object}	models.ErrorResponse			"user cannot be impersonated"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/admin/users/:id/impersonate [post]
+func (ctr *AdminController) ImpersonateUser(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.AdminUserParams)
+	admin := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	if params.ID == admin.ID {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "You cannot impersonate yourself",
+		})
+	}
+
+	user, err := ctr.findUser(ctx, c, params.ID)
+	if user == nil {
+		return err
+	}
+	if user.SuspendedAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Suspended users cannot be impersonated",
+		})
+	}
+
+	userSessionData := models.NewUserSessionData(user)
+	// impersonating must not give the admin permissions they do not have
+	if !admin.CanAll(userSessionData.Permissions) {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Message: "You cannot impersonate a user with permissions you do not have",
+		})
+	}
+
+	if err = ctr.recordAuditLog(ctx, c, models.AuditActionImpersonationStart, admin.ID, user.ID); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	impersonator := models.Impersonator{
+		ID:        admin.ID,
+		Email:     admin.Email,
+		Name:      admin.Name,
+		StartedAt: time.Now(),
+	}
+	userSessionData, err = libs.StartImpersonation(c, impersonator, userSessionData)
+	if errors.Is(err, libs.ErrSessionExpired) {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+			Message: fiber.ErrUnauthorized.Message,
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return respondSessionData(c, userSessionData)
+}
+
+// @summary		Stop impersonation
+// @description	Turn a session impersonating a user back into a session of the admin. Responds like ImpersonateUser
+// @description	When AUTH_MODE is jwt, the access token of the impersonation is revoked
+// @id				StopImpersonation
+// @tags			auth
+// @accept			json
+// @produce		json
+// @success		200	{object}	models.UserSessionData
+// @failure		401	{object}	models.ErrorResponse	"unauthorized"
+// @failure		409	{object}	models.ErrorResponse	"not impersonating"
+// @failure		500	{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/auth/impersonation/stop [post]
+func (ctr *AdminController) StopImpersonation(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	if user.Impersonator == nil {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "You are not impersonating a user",
+		})
+	}
+
+	admin, err := ctr.findUser(ctx, c, user.Impersonator.ID)
+	if admin == nil {
+		return err
+	}
+
+	if err = ctr.recordAuditLog(ctx, c, models.AuditActionImpersonationStop, admin.ID, user.ID); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	adminSessionData := models.NewUserSessionData(admin)
+	err = libs.StopImpersonation(c, adminSessionData)
+	if errors.Is(err, libs.ErrSessionExpired) {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+			Message: fiber.ErrUnauthorized.Message,
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return respondSessionData(c, adminSessionData)
+}
+
+// @summary		Get audit logs
+// @description	Get audit logs, newest first
+// @id				GetAuditLogs
+// @tags			admin
+// @accept			json
+// @produce		json
+// @param			actor	query		string	false	"ID of the admin who acted"
+// @param			target	query		string	false	"ID of the user acted on"
+// @param			action	query		string	false	"action"			Enums(impersonation.start, impersonation.stop)
+// @param			from	query		int		false	"audit log offset"	default(0)	minimum(0)
+// @param			limit	query		int		false	"number of logs"	default(10)	minimum(1)	maximum(50)
+// @success		200		{object}	models.PaginatedResponse[models.AuditLog]
+// @failure		401		{object}	models.ErrorResponse			"unauthorized"
+// @failure		403		{object}	models.ErrorResponse			"access denied"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/admin/audit-logs [get]
+func (ctr *AdminController) GetAuditLogs(c *fiber.Ctx) error {
+	query := c.Locals("query").(*validators.GetAuditLogsQuery)
+
+	ctx := context.TODO()
+
+	filter := bson.M{}
+	if query.Actor != "" {
+		filter["actorId"] = query.Actor
+	}
+	if query.Target != "" {
+		filter["targetId"] = query.Target
+	}
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+
+	total, totalIsEstimate, err := countPageTotal(ctx, ctr.MongoAuditLogColl, filter)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	limit := pageLimit(query.Limit)
+	opts := options.Find().SetSkip(int64(query.From)).SetLimit(int64(limit)).SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := ctr.MongoAuditLogColl.Find(ctx, filter, opts)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	auditLogs := []models.AuditLog{}
+	if err = cursor.All(ctx, &auditLogs); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return respondPage(c, newOffsetPage(c, auditLogs, query.From, limit, total, totalIsEstimate))
+}
@@ -3,16 +3,21 @@ package controllers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"go_blogs/configs"
 	"go_blogs/connections"
 	"go_blogs/libs"
 	"go_blogs/models"
 	"go_blogs/utils"
 	"go_blogs/validators"
+	"time"
 
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/gofiber/fiber/v2"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -21,23 +26,75 @@ type authController interface {
 	Register(c *fiber.Ctx) error
 	Logout(c *fiber.Ctx) error
 	GetUserData(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+	ResendEmailVerification(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	UnlockLogin(c *fiber.Ctx) error
+	LoginTwoFactor(c *fiber.Ctx) error
+	BeginPasskeyRegistration(c *fiber.Ctx) error
+	FinishPasskeyRegistration(c *fiber.Ctx) error
+	GetMyPasskeys(c *fiber.Ctx) error
+	DeletePasskey(c *fiber.Ctx) error
+	BeginPasskeyLogin(c *fiber.Ctx) error
+	FinishPasskeyLogin(c *fiber.Ctx) error
+	RefreshToken(c *fiber.Ctx) error
+	GetJWKS(c *fiber.Ctx) error
 }
 
 type AuthController struct {
-	MongoUserColl *mongo.Collection
+	MongoUserColl    *mongo.Collection
+	MongoPasskeyColl *mongo.Collection
+	LoginThrottle    *libs.LoginThrottle
+	WebAuthn         *webauthn.WebAuthn
 }
 
 func NewAuthControllers() authController {
+	database := connections.MongoClient.Database(configs.Env.MongoDatabase)
+
+	userColl := connections.NewMongoCollection(database, "users")
+
+	migrateUserEmailIndex(userColl)
+	migrateUserEmailVerification(userColl)
+
+	passkeyColl := database.Collection("passkeys")
+	connections.CreateMongoIndexes(
+		passkeyColl,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "credentialId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: 1}},
+		},
+	)
+
 	return &AuthController{
-		MongoUserColl: connections.NewMongoCollection(
-			connections.MongoClient.Database(configs.Env.MongoDatabase),
-			"users",
-		),
+		MongoUserColl:    userColl,
+		MongoPasskeyColl: passkeyColl,
+		WebAuthn:         newWebAuthn(),
+		LoginThrottle: &libs.LoginThrottle{
+			Redis:              connections.RedisClient,
+			FailureWindow:      configs.Env.LoginFailureWindow,
+			BackoffAfter:       configs.Env.LoginBackoffAfter,
+			BackoffBase:        configs.Env.LoginBackoffBase,
+			BackoffMax:         configs.Env.LoginBackoffMax,
+			LockoutThreshold:   configs.Env.LoginLockoutThreshold,
+			IPLockoutThreshold: configs.Env.LoginIPLockoutThreshold,
+			LockoutDuration:    configs.Env.LoginLockoutDuration,
+		},
 	}
 }
 
+// unknownUserPasswordHash is compared with the password of logins to emails no user has, at bcrypt.DefaultCost
+const unknownUserPasswordHash = "$2a$10$MwooOioEUazlkK5hiZVl2.hXh5Dd.xpAMh1WmLdIqd8wXSH816IdO"
+
 // @summary		Login
-// @description	User Login
+// @description	User Login. Repeated failures for an account or from an IP block further attempts for a growing
+// @description	time, and enough failures lock the account until the lockout ends or the link emailed to its owner is opened.
+// @description	Users with two-factor authentication get a challenge to complete with LoginTwoFactor instead of a session.
+// @description	Suspended users and users who must reset their password cannot log in. When AUTH_MODE is jwt,
+// @description	a models.TokenPair is returned instead of setting a session cookie
 // @tags			auth
 // @id				Login
 // @accept			json
@@ -45,46 +102,91 @@ func NewAuthControllers() authController {
 // @param			email		body		string	true	"email"
 // @param			password	body		string	true	"password"	minlength(6)	maxlength(32)
 // @success		200			{object}	models.UserSessionData
+// @success		202			{object}	models.LoginChallenge			"second factor required"
 // @failure		400			{object}	models.ErrorResponse			"some condition failed"
+// @failure		403			{object}	models.ErrorResponse			"email is not verified, account is suspended or password must be reset"
 // @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		429			{object}	models.ErrorResponse			"too many failed logins, see Retry-After"
 // @failure		500			{object}	models.ErrorResponse			"something went wrong"
 // @router			/api/auth/login [post]
 func (ctr *AuthController) Login(c *fiber.Ctx) error {
 	payload := c.Locals("payload").(*validators.LoginPayload)
 
+	ctx := context.TODO()
+
+	retryAfter, err := ctr.LoginThrottle.RetryAfter(ctx, payload.Email, c.IP())
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if retryAfter > 0 {
+		return respondTooManyLogins(c, retryAfter)
+	}
+
 	var result models.User
-	err := ctr.MongoUserColl.FindOne(context.TODO(), bson.M{
+	err = ctr.MongoUserColl.FindOne(ctx, bson.M{
 		"email": payload.Email,
 	}).Decode(&result)
 	if errors.Is(err, mongo.ErrNoDocuments) {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Message: "Email or password is invalid",
-		})
+		// comparing anyway makes logins to unknown emails take as long as those with a wrong password
+		bcrypt.CompareHashAndPassword([]byte(unknownUserPasswordHash), []byte(payload.Password))
+		return ctr.loginFailed(c, ctx, payload.Email, nil)
 	} else if err != nil {
 		return utils.NewAppError(err)
 	}
 
 	if err = bcrypt.CompareHashAndPassword([]byte(result.Password), []byte(payload.Password)); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
-			Message: "Email or password is invalid",
+		return ctr.loginFailed(c, ctx, payload.Email, &result)
+	}
+
+	if configs.Env.RequireVerifiedEmailToLogin && !result.EmailVerified {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Message: "Email is not verified. Please open the verification link sent to your email",
 		})
 	}
 
-	userSessionData := models.UserSessionData{
-		ID:    result.ID,
-		Email: result.Email,
-		Name:  result.Name,
+	if result.TwoFactorEnabled() {
+		return ctr.challengeLogin(c, ctx, &result)
 	}
-	err = libs.SetUserSessionData(c, userSessionData)
-	if err != nil {
+
+	return ctr.startSession(c, ctx, &result)
+}
+
+// startSession logs user in once every factor has been checked
+func (ctr *AuthController) startSession(c *fiber.Ctx, ctx context.Context, user *models.User) error {
+	if reason := user.LoginBlockedReason(); reason != "" {
+		return respondLoginBlocked(c, reason)
+	}
+
+	// failures are only forgotten here, so that a known password alone cannot clear them
+	if err := ctr.LoginThrottle.Reset(ctx, user.Email); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	userSessionData := models.NewUserSessionData(user)
+
+	if configs.Env.AuthMode == libs.AuthModeJWT {
+		tokens, err := libs.StartTokenSession(c, userSessionData)
+		if err != nil {
+			return utils.NewAppError(err)
+		}
+		return c.JSON(tokens)
+	}
+
+	if err := libs.SetUserSessionData(c, userSessionData); err != nil {
 		return utils.NewAppError(err)
 	}
 
 	return c.JSON(userSessionData)
 }
 
+func respondLoginBlocked(c *fiber.Ctx, reason string) error {
+	return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+		Message: reason,
+	})
+}
+
 // @summary		Register
-// @description	Registration
+// @description	Registration. A verification link is sent to the email
 // @tags			auth
 // @id				Register
 // @accept			json
@@ -92,6 +194,7 @@ func (ctr *AuthController) Login(c *fiber.Ctx) error {
 // @param			email		body		string	true	"email"
 // @param			password	body		string	true	"password"	minlength(5)	maxlength(32)
 // @param			name		body		string	true	"Name"
+// @param			handle		body		string	false	"public handle, generated from the name when omitted"	maxlength(30)
 // @success		200			{object}	models.UserSessionData
 // @failure		400			{object}	models.ErrorResponse			"some condition failed"
 // @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
@@ -108,7 +211,7 @@ func (ctr *AuthController) Register(c *fiber.Ctx) error {
 		"email": payload.Email,
 	}
 	err := ctr.MongoUserColl.FindOne(ctx, filter).Decode(&user)
-	if err != nil && errors.Is(err, mongo.ErrNoDocuments) {
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
 		return utils.NewAppError(err)
 	}
 	if user != nil {
@@ -117,23 +220,70 @@ func (ctr *AuthController) Register(c *fiber.Ctx) error {
 		})
 	}
 
+	userObjectID := primitive.NewObjectID()
+
+	handle := payload.Handle
+	if handle != "" {
+		var taken bool
+		if taken, err = isHandleTaken(ctx, ctr.MongoUserColl, handle, userObjectID); err != nil {
+			return utils.NewAppError(err)
+		}
+		if taken {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Message: "Handle has been used. Please use another handle",
+			})
+		}
+	} else if handle, err = generateUniqueHandle(ctx, ctr.MongoUserColl, payload.Name, userObjectID); err != nil {
+		return utils.NewAppError(err)
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return utils.NewAppError(err)
 	}
 
+	role := models.RoleUser
+	if configs.Env.BootstrapAdminEmail != "" && payload.Email == configs.Env.BootstrapAdminEmail {
+		role = models.RoleAdmin
+	}
+
 	document := bson.D{
+		{Key: "_id", Value: userObjectID},
 		{Key: "email", Value: payload.Email},
+		{Key: "emailVerified", Value: false},
 		{Key: "password", Value: string(hashedPassword)},
 		{Key: "name", Value: payload.Name},
+		{Key: "handle", Value: handle},
+		{Key: "role", Value: role},
+		{Key: "createdAt", Value: time.Now()},
 	}
 	_, err = ctr.MongoUserColl.InsertOne(ctx, document)
 	if err != nil {
+		if isDuplicateEmailError(err) {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Message: "Email has been used. Please use another email",
+			})
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Message: "Handle has been used. Please use another handle",
+			})
+		}
 		return utils.NewAppError(err)
 	}
 
+	user = &models.User{
+		ID:    userObjectID.Hex(),
+		Email: payload.Email,
+		Name:  payload.Name,
+	}
+	// the user can ask for another link, so a failed delivery does not fail the registration
+	if err = sendEmailVerification(ctx, user); err != nil {
+		fmt.Println("Register:", err.Error())
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(models.SuccessResponse{
-		Message: "Registered",
+		Message: "Registered. Please open the verification link sent to your email",
 	})
 }
 
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/libs"
+	"go_blogs/mailer"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const loginUnlockTokenPurpose = "login_unlock"
+
+type loginUnlock struct {
+	Email string `json:"email"`
+}
+
+func respondTooManyLogins(c *fiber.Ctx, retryAfter time.Duration) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	return c.Status(fiber.StatusTooManyRequests).JSON(models.ErrorResponse{
+		Message: "Too many failed logins. Please try again later",
+	})
+}
+
+// loginFailed records a failed login to email and responds to it. user is nil when no user has the email;
+// the response is the same either way, so it cannot be used to find registered emails
+func (ctr *AuthController) loginFailed(c *fiber.Ctx, ctx context.Context, email string, user *models.User) error {
+	failure, err := ctr.LoginThrottle.RecordFailure(ctx, email, c.IP())
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	// the link is sent in the background, so that the time taken does not tell whether the email belongs to a user
+	if failure.AccountLocked && user != nil {
+		runInBackground(func() { sendLoginUnlock(user) })
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+		Message: "Email or password is invalid",
+	})
+}
+
+// sendLoginUnlock tells user their account has been locked and mails them a link that unlocks it. The login has
+// already been answered, so failures are logged
+func sendLoginUnlock(user *models.User) {
+	if err := mailLoginUnlock(context.Background(), user); err != nil {
+		fmt.Println("Login:", err.Error())
+	}
+}
+
+func mailLoginUnlock(ctx context.Context, user *models.User) error {
+	token, err := libs.NewToken()
+	if err != nil {
+		return err
+	}
+	unlock := loginUnlock{Email: user.Email}
+	if err = libs.StoreToken(ctx, loginUnlockTokenPurpose, token, unlock, configs.Env.LoginLockoutDuration); err != nil {
+		return err
+	}
+
+	return mailer.Default.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your account has been locked",
+		Text: fmt.Sprintf(
+			"Hi %s,\n\nThere were too many failed logins to your account, so logins are blocked for %s.\n"+
+				"If it was you, open this link to unlock your account now:\n%s/unlock-login?token=%s\n\n"+
+				"If it was not you, consider resetting your password.\n",
+			user.Name, configs.Env.LoginLockoutDuration, configs.Env.AppURL, url.QueryEscape(token),
+		),
+	})
+}
+
+// @summary		Unlock login
+// @description	Unlock an account locked after too many failed logins, with the token of the link sent to its owner
+// @id				UnlockLogin
+// @tags			auth
+// @accept			json
+// @produce		json
+// @param			token	body		string	true	"unlock token"
+// @success		200		{object}	models.SuccessResponse
+// @failure		400		{object}	models.ErrorResponse			"link is invalid or has expired"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/auth/unlock [post]
+func (ctr *AuthController) UnlockLogin(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.UnlockLoginPayload)
+
+	ctx := context.TODO()
+
+	var unlock loginUnlock
+	found, err := libs.ConsumeToken(ctx, loginUnlockTokenPurpose, payload.Token, &unlock)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !found {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Link is invalid or has expired",
+		})
+	}
+
+	if err = ctr.LoginThrottle.Unlock(ctx, unlock.Email); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Account unlocked",
+	})
+}
@@ -0,0 +1,435 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go_blogs/configs"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	passkeyRegistrationTokenPurpose = "passkey_registration"
+	passkeyLoginTokenPurpose        = "passkey_login"
+	passkeyCeremonyTTL              = 5 * time.Minute
+	defaultPasskeyName              = "Passkey"
+)
+
+func newWebAuthn() *webauthn.WebAuthn {
+	residentKey := true
+	config := &webauthn.Config{
+		RPID:          configs.Env.WebAuthnRPID,
+		RPDisplayName: configs.Env.WebAuthnRPName,
+		RPOrigins:     configs.Env.WebAuthnRPOrigins,
+		// passkeys are discoverable, so logging in does not start with an email
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			RequireResidentKey: &residentKey,
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			UserVerification:   protocol.VerificationPreferred,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTTL, TimeoutUVD: passkeyCeremonyTTL},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTTL, TimeoutUVD: passkeyCeremonyTTL},
+		},
+	}
+	w, err := webauthn.New(config)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// passkeyUser adapts a user and their passkeys to webauthn.User. The user handle is the 12 bytes of the user's object ID
+type passkeyUser struct {
+	user     *models.User
+	passkeys []models.Passkey
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	userObjectID, _ := primitive.ObjectIDFromHex(u.user.ID)
+	return userObjectID[:]
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.user.Name
+}
+
+func (u *passkeyUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.passkeys))
+	for i, passkey := range u.passkeys {
+		transports := make([]protocol.AuthenticatorTransport, len(passkey.Transports))
+		for j, transport := range passkey.Transports {
+			transports[j] = protocol.AuthenticatorTransport(transport)
+		}
+		credentials[i] = webauthn.Credential{
+			ID:              passkey.CredentialID,
+			PublicKey:       passkey.PublicKey,
+			AttestationType: passkey.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: passkey.BackupEligible,
+				BackupState:    passkey.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    passkey.AAGUID,
+				SignCount: passkey.SignCount,
+			},
+		}
+	}
+	return credentials
+}
+
+func (ctr *AuthController) loadPasskeyUser(ctx context.Context, userObjectID primitive.ObjectID) (*passkeyUser, error) {
+	var user *models.User
+	if err := ctr.MongoUserColl.FindOne(ctx, bson.M{"_id": userObjectID}).Decode(&user); err != nil {
+		return nil, err
+	}
+
+	cursor, err := ctr.MongoPasskeyColl.Find(ctx, bson.M{"userId": user.ID})
+	if err != nil {
+		return nil, err
+	}
+	passkeys := []models.Passkey{}
+	if err = cursor.All(ctx, &passkeys); err != nil {
+		return nil, err
+	}
+
+	return &passkeyUser{user: user, passkeys: passkeys}, nil
+}
+
+func respondInvalidPasskey(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+		Message: "Passkey could not be verified",
+	})
+}
+
+// @summary		Begin passkey registration
+// @description	Start adding a passkey. Pass the options to navigator.credentials.create() and the result to FinishPasskeyRegistration
+// @id				BeginPasskeyRegistration
+// @tags			me
+// @accept			json
+// @produce		json
+// @success		200	{object}	object					"PublicKeyCredentialCreationOptions under publicKey"
+// @failure		401	{object}	models.ErrorResponse	"unauthorized"
+// @failure		500	{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/me/passkeys/register/begin [post]
+func (ctr *AuthController) BeginPasskeyRegistration(c *fiber.Ctx) error {
+	sessionUser := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	userObjectID, err := primitive.ObjectIDFromHex(sessionUser.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	user, err := ctr.loadPasskeyUser(ctx, userObjectID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.passkeys))
+	for _, credential := range user.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+	creation, session, err := ctr.WebAuthn.BeginRegistration(user, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if err = libs.StoreToken(ctx, passkeyRegistrationTokenPurpose, session.Challenge, session, passkeyCeremonyTTL); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(creation)
+}
+
+// @summary		Finish passkey registration
+// @description	Add the passkey created by navigator.credentials.create() with the options of BeginPasskeyRegistration
+// @id				FinishPasskeyRegistration
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			name		query		string	false	"name to tell the passkey apart"	maxlength(50)
+// @param			credential	body		object	true	"PublicKeyCredential returned by the browser"
+// @success		201			{object}	models.Passkey
+// @failure		400			{object}	models.ErrorResponse			"passkey could not be verified"
+// @failure		401			{object}	models.ErrorResponse			"unauthorized"
+// @failure		409			{object}	models.ErrorResponse			"passkey has been added already"
+// @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500			{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/passkeys/register/finish [post]
+func (ctr *AuthController) FinishPasskeyRegistration(c *fiber.Ctx) error {
+	query := c.Locals("query").(*validators.FinishPasskeyRegistrationQuery)
+	sessionUser := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(c.Body()))
+	if err != nil {
+		return respondInvalidPasskey(c)
+	}
+
+	var session webauthn.SessionData
+	found, err := libs.ConsumeToken(ctx, passkeyRegistrationTokenPurpose, parsed.Response.CollectedClientData.Challenge, &session)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	userObjectID, err := primitive.ObjectIDFromHex(sessionUser.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	// the ceremony must have been started by the same user
+	if !found || !bytes.Equal(session.UserID, userObjectID[:]) {
+		return respondInvalidPasskey(c)
+	}
+
+	user, err := ctr.loadPasskeyUser(ctx, userObjectID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	credential, err := ctr.WebAuthn.CreateCredential(user, session, parsed)
+	if err != nil {
+		return respondInvalidPasskey(c)
+	}
+
+	name := query.Name
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	transports := make([]string, len(credential.Transport))
+	for i, transport := range credential.Transport {
+		transports[i] = string(transport)
+	}
+	passkey := models.Passkey{
+		ID:             primitive.NewObjectID().Hex(),
+		UserID:         sessionUser.ID,
+		Name:           name,
+		Transports:     transports,
+		BackupEligible: credential.Flags.BackupEligible,
+		BackupState:    credential.Flags.BackupState,
+		CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+	}
+	passkeyObjectID, _ := primitive.ObjectIDFromHex(passkey.ID)
+	document := bson.D{
+		{Key: "_id", Value: passkeyObjectID},
+		{Key: "userId", Value: passkey.UserID},
+		{Key: "name", Value: passkey.Name},
+		{Key: "credentialId", Value: credential.ID},
+		{Key: "publicKey", Value: credential.PublicKey},
+		{Key: "attestationType", Value: credential.AttestationType},
+		{Key: "transports", Value: passkey.Transports},
+		{Key: "aaguid", Value: credential.Authenticator.AAGUID},
+		{Key: "signCount", Value: int64(credential.Authenticator.SignCount)},
+		{Key: "backupEligible", Value: passkey.BackupEligible},
+		{Key: "backupState", Value: passkey.BackupState},
+		{Key: "cloneWarning", Value: false},
+		{Key: "createdAt", Value: passkey.CreatedAt},
+	}
+	if _, err = ctr.MongoPasskeyColl.InsertOne(ctx, document); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Message: "Passkey has been added already",
+			})
+		}
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(passkey)
+}
+
+// @summary		Get my passkeys
+// @description	Get the passkeys of the current user
+// @id				GetMyPasskeys
+// @tags			me
+// @accept			json
+// @produce		json
+// @success		200	{array}		models.Passkey
+// @failure		401	{object}	models.ErrorResponse	"unauthorized"
+// @failure		500	{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/me/passkeys [get]
+func (ctr *AuthController) GetMyPasskeys(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := ctr.MongoPasskeyColl.Find(ctx, bson.M{"userId": user.ID}, opts)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	passkeys := []models.Passkey{}
+	if err = cursor.All(ctx, &passkeys); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(passkeys)
+}
+
+// @summary		Delete passkey
+// @description	Remove a passkey of the current user
+// @id				DeletePasskey
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"passkey's id"
+// @success		200	{object}	models.SuccessResponse
+// @failure		401	{object}	models.ErrorResponse			"unauthorized"
+// @failure		404	{object}	models.ErrorResponse			"passkey not found"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/passkeys/:id [delete]
+func (ctr *AuthController) DeletePasskey(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.DeletePasskeyParams)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	passkeyObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	result, err := ctr.MongoPasskeyColl.DeleteOne(ctx, bson.M{"_id": passkeyObjectID, "userId": user.ID})
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if result.DeletedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "Passkey not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Deleted",
+	})
+}
+
+// @summary		Begin passkey login
+// @description	Start logging in with a passkey. Pass the options to navigator.credentials.get() and the result to FinishPasskeyLogin
+// @id				BeginPasskeyLogin
+// @tags			auth
+// @accept			json
+// @produce		json
+// @success		200	{object}	object					"PublicKeyCredentialRequestOptions under publicKey"
+// @failure		500	{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/auth/passkey/begin [post]
+func (ctr *AuthController) BeginPasskeyLogin(c *fiber.Ctx) error {
+	ctx := context.TODO()
+
+	assertion, session, err := ctr.WebAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if err = libs.StoreToken(ctx, passkeyLoginTokenPurpose, session.Challenge, session, passkeyCeremonyTTL); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(assertion)
+}
+
+// @summary		Finish passkey login
+// @description	Log in with the passkey assertion returned by navigator.credentials.get() with the options of BeginPasskeyLogin.
+// @description	A passkey stands in for both the password and the second factor. A passkey whose signature counter
+// @description	goes backwards may have been cloned and is refused. Responds like Login
+// @id				FinishPasskeyLogin
+// @tags			auth
+// @accept			json
+// @produce		json
+// @param			credential	body		object	true	"PublicKeyCredential returned by the browser"
+// @success		200			{object}	models.UserSessionData
+// @failure		400			{object}	models.ErrorResponse	"passkey could not be verified"
+// @failure		403			{object}	models.ErrorResponse	"email is not verified, account is suspended or password must be reset"
+// @failure		500			{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/auth/passkey/finish [post]
+func (ctr *AuthController) FinishPasskeyLogin(c *fiber.Ctx) error {
+	ctx := context.TODO()
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(c.Body()))
+	if err != nil {
+		return respondInvalidPasskey(c)
+	}
+
+	var session webauthn.SessionData
+	found, err := libs.ConsumeToken(ctx, passkeyLoginTokenPurpose, parsed.Response.CollectedClientData.Challenge, &session)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !found {
+		return respondInvalidPasskey(c)
+	}
+
+	var user *passkeyUser
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		if len(userHandle) != len(primitive.ObjectID{}) {
+			return nil, errors.New("unknown user handle")
+		}
+		var userObjectID primitive.ObjectID
+		copy(userObjectID[:], userHandle)
+		user, err = ctr.loadPasskeyUser(ctx, userObjectID)
+		return user, err
+	}
+	credential, err := ctr.WebAuthn.ValidateDiscoverableLogin(handler, session, parsed)
+	if err != nil {
+		return respondInvalidPasskey(c)
+	}
+
+	filter := bson.M{
+		"userId":       user.user.ID,
+		"credentialId": credential.ID,
+	}
+	if credential.Authenticator.CloneWarning {
+		update := bson.M{
+			"$set": bson.M{"cloneWarning": true},
+		}
+		if _, err = ctr.MongoPasskeyColl.UpdateOne(ctx, filter, update); err != nil {
+			return utils.NewAppError(err)
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Passkey may have been copied. Please log in another way and remove it",
+		})
+	}
+	// the counter only moves forwards, so of two concurrent logins with one assertion only the first updates it
+	if credential.Authenticator.SignCount > 0 {
+		filter["signCount"] = bson.M{"$lt": int64(credential.Authenticator.SignCount)}
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"signCount":   int64(credential.Authenticator.SignCount),
+			"backupState": credential.Flags.BackupState,
+			"lastUsedAt":  time.Now(),
+		},
+	}
+	result, err := ctr.MongoPasskeyColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if result.MatchedCount == 0 {
+		return respondInvalidPasskey(c)
+	}
+
+	if configs.Env.RequireVerifiedEmailToLogin && !user.user.EmailVerified {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Message: "Email is not verified. Please open the verification link sent to your email",
+		})
+	}
+
+	return ctr.startSession(c, ctx, user.user)
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/libs"
+	"go_blogs/mailer"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	passwordResetTokenPurpose = "password_reset"
+	// passwordResetInterval is how long a user waits between password reset emails
+	passwordResetInterval = time.Minute
+)
+
+type passwordReset struct {
+	UserID string `json:"userId"`
+}
+
+// newPasswordResetToken returns the token of a link that sets a new password for the user
+func newPasswordResetToken(ctx context.Context, userID string) (string, error) {
+	token, err := libs.NewToken()
+	if err != nil {
+		return "", err
+	}
+	reset := passwordReset{UserID: userID}
+	if err = libs.StoreUserToken(ctx, passwordResetTokenPurpose, userID, token, reset, configs.Env.PasswordResetTTL); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// @summary		Forgot password
+// @description	Send a password reset link to an email. The response is the same whether or not the email belongs
+// @description	to a user, so it cannot be used to find registered emails
+// @id				ForgotPassword
+// @tags			auth
+// @accept			json
+// @produce		json
+// @param			email	body		string	true	"email"
+// @success		202		{object}	models.SuccessResponse
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/auth/forgot-password [post]
+func (ctr *AuthController) ForgotPassword(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.ForgotPasswordPayload)
+
+	ctx := context.TODO()
+
+	var user *models.User
+	err := ctr.MongoUserColl.FindOne(ctx, bson.M{"email": payload.Email}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return respondPasswordResetAccepted(c)
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	// the link is sent in the background, so that neither the status nor the time taken tells whether the email
+	// belongs to a user
+	runInBackground(func() { sendPasswordReset(user) })
+
+	return respondPasswordResetAccepted(c)
+}
+
+func respondPasswordResetAccepted(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{
+		Message: "If the email belongs to a user, a password reset link has been sent to it",
+	})
+}
+
+// sendPasswordReset mails user a password reset link, at most once per passwordResetInterval. The request has
+// already been answered, so failures are logged
+func sendPasswordReset(user *models.User) {
+	ctx := context.Background()
+
+	key := fmt.Sprintf("password_reset_sent:%s", user.ID)
+	sent, err := connections.RedisClient.SetNX(ctx, key, 1, passwordResetInterval).Result()
+	if err != nil {
+		fmt.Println("ForgotPassword:", err.Error())
+		return
+	}
+	if !sent {
+		return
+	}
+
+	if err = mailPasswordReset(ctx, user); err != nil {
+		fmt.Println("ForgotPassword:", err.Error())
+		// no link went out, so the user may ask again right away
+		if err = connections.RedisClient.Del(ctx, key).Err(); err != nil {
+			fmt.Println("ForgotPassword:", err.Error())
+		}
+	}
+}
+
+func mailPasswordReset(ctx context.Context, user *models.User) error {
+	token, err := newPasswordResetToken(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	return mailer.Default.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Text: fmt.Sprintf(
+			"Hi %s,\n\nOpen this link within %s to choose a new password:\n%s/reset-password?token=%s\n\nIf you did not ask for this, ignore this email. Your password stays the same.\n",
+			user.Name, configs.Env.PasswordResetTTL, configs.Env.AppURL, url.QueryEscape(token),
+		),
+	})
+}
+
+// @summary		Reset password
+// @description	Set a new password with the token of a link sent by ForgotPassword. Every session of the user is logged out,
+// @description	and the other links sent to them stop working
+// @id				ResetPassword
+// @tags			auth
+// @accept			json
+// @produce		json
+// @param			token		body		string	true	"reset token"
+// @param			password	body		string	true	"new password"	minlength(6)	maxlength(32)
+// @success		200			{object}	models.SuccessResponse
+// @failure		400			{object}	models.ErrorResponse			"link is invalid or has expired"
+// @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500			{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/auth/reset-password [post]
+func (ctr *AuthController) ResetPassword(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.ResetPasswordPayload)
+
+	ctx := context.TODO()
+
+	var reset passwordReset
+	found, err := libs.ConsumeToken(ctx, passwordResetTokenPurpose, payload.Token, &reset)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !found {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Link is invalid or has expired",
+		})
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(reset.UserID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	// opening the link proves the user reads their email, so it counts as verifying it
+	update := bson.M{
+		"$set": bson.M{
+			"password":              string(hashedPassword),
+			"emailVerified":         true,
+			"passwordResetRequired": false,
+		},
+	}
+	var user *models.User
+	err = ctr.MongoUserColl.FindOneAndUpdate(ctx, bson.M{"_id": userObjectID}, update).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Link is invalid or has expired",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	// other links sent before must not set the password again, as one of them may have leaked
+	if err = libs.DeleteUserTokens(ctx, passwordResetTokenPurpose, reset.UserID); err != nil {
+		return utils.NewAppError(err)
+	}
+	if err = libs.RevokeUserSessions(ctx, reset.UserID, ""); err != nil {
+		return utils.NewAppError(err)
+	}
+	// failed guesses of the old password should not keep the owner out with the new one
+	if err = ctr.LoginThrottle.Unlock(ctx, user.Email); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Password has been reset. Please log in with the new password",
+	})
+}
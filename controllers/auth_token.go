@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @summary		Refresh token
+// @description	Exchange a refresh token for a new access token and refresh token, when AUTH_MODE is jwt. Each refresh
+// @description	token can be exchanged once. Exchanging one again means it has leaked, so its session is logged out
+// @tags			auth
+// @id				RefreshToken
+// @accept			json
+// @produce		json
+// @param			refreshToken	body		string	true	"refresh token"
+// @success		200				{object}	models.TokenPair
+// @failure		401				{object}	models.ErrorResponse			"refresh token is invalid, has expired or has been reused"
+// @failure		422				{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500				{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/auth/token/refresh [post]
+func (ctr *AuthController) RefreshToken(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.RefreshTokenPayload)
+
+	tokens, err := libs.RefreshTokenSession(context.TODO(), payload.RefreshToken)
+	if errors.Is(err, libs.ErrRefreshTokenReused) {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+			Message: "Refresh token has already been used. Please log in again",
+		})
+	} else if errors.Is(err, libs.ErrInvalidRefreshToken) {
+		return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+			Message: "Refresh token is invalid or has expired",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tokens)
+}
+
+// @summary		Get JWKS
+// @description	Get the public keys access tokens are verified with, when AUTH_MODE is jwt with EdDSA or RS256.
+// @description	Keys being rotated in or out are listed too
+// @tags			auth
+// @id				GetJWKS
+// @produce		json
+// @success		200	{object}	models.JWKS
+// @router			/.well-known/jwks.json [get]
+func (ctr *AuthController) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(libs.GetJWKS())
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	loginChallengeTokenPurpose = "login_challenge"
+	loginChallengeTTL          = 5 * time.Minute
+	// maxLoginChallengeAttempts is how many wrong codes a challenge takes before the password is asked again
+	maxLoginChallengeAttempts = 5
+)
+
+type loginChallenge struct {
+	UserID string `json:"userId"`
+}
+
+// challengeLogin answers a correct password of a user with two-factor authentication with a challenge
+// to complete with LoginTwoFactor, instead of a session
+func (ctr *AuthController) challengeLogin(c *fiber.Ctx, ctx context.Context, user *models.User) error {
+	if reason := user.LoginBlockedReason(); reason != "" {
+		return respondLoginBlocked(c, reason)
+	}
+
+	token, err := libs.NewToken()
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	challenge := loginChallenge{UserID: user.ID}
+	if err = libs.StoreToken(ctx, loginChallengeTokenPurpose, token, challenge, loginChallengeTTL); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.LoginChallenge{
+		Challenge: token,
+		ExpiresIn: int(loginChallengeTTL.Seconds()),
+	})
+}
+
+// @summary		Login with second factor
+// @description	Complete a login challenged for two-factor authentication with a code from the authenticator app
+// @description	or a recovery code. Wrong codes count as failed logins. Responds like Login
+// @tags			auth
+// @id				LoginTwoFactor
+// @accept			json
+// @produce		json
+// @param			challenge		body		string	true	"challenge returned by Login"
+// @param			code			body		string	false	"6-digit code from the authenticator app"
+// @param			recoveryCode	body		string	false	"recovery code, when the app is lost"
+// @success		200				{object}	models.UserSessionData
+// @failure		400				{object}	models.ErrorResponse			"challenge or code is invalid"
+// @failure		403				{object}	models.ErrorResponse			"account is suspended or password must be reset"
+// @failure		422				{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		429				{object}	models.ErrorResponse			"too many failed logins, see Retry-After"
+// @failure		500				{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/auth/login/2fa [post]
+func (ctr *AuthController) LoginTwoFactor(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.LoginTwoFactorPayload)
+
+	ctx := context.TODO()
+
+	var challenge loginChallenge
+	found, err := libs.LoadToken(ctx, loginChallengeTokenPurpose, payload.Challenge, &challenge)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !found {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Login has expired. Please log in again",
+		})
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(challenge.UserID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	var user *models.User
+	err = ctr.MongoUserColl.FindOne(ctx, bson.M{"_id": userObjectID}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Login has expired. Please log in again",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	retryAfter, err := ctr.LoginThrottle.RetryAfter(ctx, user.Email, c.IP())
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if retryAfter > 0 {
+		return respondTooManyLogins(c, retryAfter)
+	}
+
+	verified, err := verifySecondFactor(ctx, ctr.MongoUserColl, user, payload.Code, payload.RecoveryCode)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !verified {
+		return ctr.secondFactorFailed(c, ctx, payload.Challenge, user)
+	}
+
+	if err = libs.DeleteToken(ctx, loginChallengeTokenPurpose, payload.Challenge); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return ctr.startSession(c, ctx, user)
+}
+
+func (ctr *AuthController) secondFactorFailed(c *fiber.Ctx, ctx context.Context, challenge string, user *models.User) error {
+	failure, err := ctr.LoginThrottle.RecordFailure(ctx, user.Email, c.IP())
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if failure.AccountLocked {
+		runInBackground(func() { sendLoginUnlock(user) })
+	}
+
+	attempts, err := libs.CountTokenAttempt(ctx, loginChallengeTokenPurpose, challenge, loginChallengeTTL)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if attempts >= maxLoginChallengeAttempts {
+		if err = libs.DeleteToken(ctx, loginChallengeTokenPurpose, challenge); err != nil {
+			return utils.NewAppError(err)
+		}
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+		Message: "Code is invalid",
+	})
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/libs"
+	"go_blogs/mailer"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// emailVerificationResendInterval is how long a user waits between verification emails
+const emailVerificationResendInterval = time.Minute
+
+// sendEmailVerification mails user a link that verifies their current email
+func sendEmailVerification(ctx context.Context, user *models.User) error {
+	token := libs.EncodeEmailVerification(libs.EmailVerification{
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(configs.Env.EmailVerificationTTL).Unix(),
+	})
+	return mailer.Default.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Text: fmt.Sprintf(
+			"Hi %s,\n\nOpen this link within %s to verify your email:\n%s/verify-email?token=%s\n\nIf you did not create an account, ignore this email.\n",
+			user.Name, configs.Env.EmailVerificationTTL, configs.Env.AppURL, url.QueryEscape(token),
+		),
+	})
+}
+
+// migrateUserEmailVerification marks users registered before email verification existed as verified
+func migrateUserEmailVerification(userColl *mongo.Collection) {
+	filter := bson.M{
+		"emailVerified": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"emailVerified": true},
+	}
+	if _, err := userColl.UpdateMany(context.TODO(), filter, update); err != nil {
+		panic(err)
+	}
+}
+
+// @summary		Verify email
+// @description	Verify the email of a user with the token of a link sent on registration
+// @id				VerifyEmail
+// @tags			auth
+// @accept			json
+// @produce		json
+// @param			token	body		string	true	"verification token"
+// @success		200		{object}	models.SuccessResponse
+// @failure		400		{object}	models.ErrorResponse			"link is invalid or has expired"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/auth/verify-email [post]
+func (ctr *AuthController) VerifyEmail(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.VerifyEmailPayload)
+
+	ctx := context.TODO()
+
+	verification, err := libs.DecodeEmailVerification(payload.Token, time.Now())
+	if errors.Is(err, libs.ErrExpiredEmailVerification) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Link has expired. Please ask for a new one",
+		})
+	} else if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Link is invalid",
+		})
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(verification.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Link is invalid",
+		})
+	}
+
+	// the email is matched so that a link stops working once the user changes their email
+	var user *models.User
+	filter := bson.M{
+		"_id":   userObjectID,
+		"email": verification.Email,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"emailVerified":   true,
+			"emailVerifiedAt": time.Now(),
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = ctr.MongoUserColl.FindOneAndUpdate(ctx, filter, update, opts).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Link is invalid",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	if err = libs.UpdateUserSessionsData(ctx, models.NewUserSessionData(user)); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Email verified",
+	})
+}
+
+// @summary		Resend email verification
+// @description	Send another verification link to an unverified email. The response is the same whether or not
+// @description	the email belongs to a user, so it cannot be used to find registered emails
+// @id				ResendEmailVerification
+// @tags			auth
+// @accept			json
+// @produce		json
+// @param			email	body		string	true	"email"
+// @success		202		{object}	models.SuccessResponse
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/auth/verify-email/resend [post]
+func (ctr *AuthController) ResendEmailVerification(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.ResendEmailVerificationPayload)
+
+	ctx := context.TODO()
+
+	accepted := c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{
+		Message: "If the email needs verifying, a new link has been sent to it",
+	})
+
+	var user *models.User
+	filter := bson.M{
+		"email":         payload.Email,
+		"emailVerified": false,
+	}
+	err := ctr.MongoUserColl.FindOne(ctx, filter).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return accepted
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	key := fmt.Sprintf("email_verification_resend:%s", user.ID)
+	sent, err := connections.RedisClient.SetNX(ctx, key, 1, emailVerificationResendInterval).Result()
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !sent {
+		return accepted
+	}
+
+	if err = sendEmailVerification(ctx, user); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return accepted
+}
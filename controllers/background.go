@@ -0,0 +1,20 @@
+package controllers
+
+import "sync"
+
+// background tracks the work of requests that have already been answered, such as mailing links
+var background sync.WaitGroup
+
+// runInBackground runs f without holding up the response, so that the time it takes does not show in it
+func runInBackground(f func()) {
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		f()
+	}()
+}
+
+// WaitForBackgroundWork waits for the work requests have started in the background to finish
+func WaitForBackgroundWork() {
+	background.Wait()
+}
@@ -3,9 +3,13 @@ package controllers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"go_blogs/configs"
 	"go_blogs/connections"
+	"go_blogs/libs"
 	"go_blogs/models"
+	"go_blogs/policies"
+	"go_blogs/search"
 	"go_blogs/utils"
 	"go_blogs/validators"
 	"time"
@@ -19,78 +23,269 @@ import (
 
 type blogController interface {
 	GetBlogs(c *fiber.Ctx) error
+	SearchBlogs(c *fiber.Ctx) error
 	GetBlogByID(c *fiber.Ctx) error
+	GetBlogBySlug(c *fiber.Ctx) error
+	GetTags(c *fiber.Ctx) error
 	CreateBlog(c *fiber.Ctx) error
 	UpdateBlog(c *fiber.Ctx) error
 	DeleteBlog(c *fiber.Ctx) error
+	SubmitBlog(c *fiber.Ctx) error
+	PublishBlog(c *fiber.Ctx) error
+	UnpublishBlog(c *fiber.Ctx) error
+	ArchiveBlog(c *fiber.Ctx) error
+	RestoreBlog(c *fiber.Ctx) error
+	GetMyBlogs(c *fiber.Ctx) error
+	GetMyTrash(c *fiber.Ctx) error
+	GetUserBlogs(c *fiber.Ctx) error
+	GetBlogRevisions(c *fiber.Ctx) error
+	GetBlogRevision(c *fiber.Ctx) error
+	DiffBlogRevisions(c *fiber.Ctx) error
+	RestoreBlogRevision(c *fiber.Ctx) error
 }
 
 type BlogController struct {
-	MongoBlogColl *mongo.Collection
+	MongoBlogColl         *mongo.Collection
+	MongoBlogRevisionColl *mongo.Collection
+	MongoCategoryColl     *mongo.Collection
+	MongoUserColl         *mongo.Collection
+	SearchIndex           *search.BlogIndex
 }
 
 func NewBlogControllers() blogController {
+	database := connections.MongoClient.Database(configs.Env.MongoDatabase)
+
+	blogColl := connections.NewMongoCollection(database, "blogs")
+	connections.CreateMongoIndexes(
+		blogColl,
+		mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+		mongo.IndexModel{Keys: bson.D{{Key: "createdBy", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "publishAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "unpublishAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "deletedBy", Value: 1}, {Key: "deletedAt", Value: -1}},
+			Options: options.Index().SetSparse(true),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "deletedAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"slug": bson.M{"$type": "string"}}),
+		},
+		mongo.IndexModel{Keys: bson.D{{Key: "slugHistory", Value: 1}}},
+		mongo.IndexModel{Keys: bson.D{{Key: "tags", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+		mongo.IndexModel{Keys: bson.D{{Key: "categoryId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+	)
+	migrateBlogStatus(blogColl)
+	migrateBlogVersion(blogColl)
+	migrateBlogSlugs(blogColl)
+
+	blogRevisionColl := connections.NewMongoCollection(database, "blog_revisions")
+	connections.CreateMongoIndexes(
+		blogRevisionColl,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "blogId", Value: 1}, {Key: "revision", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)
+	migrateBlogRevisions(blogColl, blogRevisionColl)
+
 	return &BlogController{
-		MongoBlogColl: connections.NewMongoCollection(
-			connections.MongoClient.Database(configs.Env.MongoDatabase),
-			"blogs",
-		),
+		MongoBlogColl:         blogColl,
+		MongoBlogRevisionColl: blogRevisionColl,
+		MongoCategoryColl:     database.Collection("categories"),
+		MongoUserColl:         database.Collection("users"),
+		SearchIndex:           search.Blogs,
+	}
+}
+
+// migrateBlogStatus marks blogs created before the status lifecycle existed as published
+func migrateBlogStatus(blogColl *mongo.Collection) {
+	filter := bson.M{
+		"status": bson.M{"$exists": false},
+	}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"status":      models.BlogStatusPublished,
+			"publishedAt": "$createdAt",
+		}}},
+	}
+	if _, err := blogColl.UpdateMany(context.TODO(), filter, update); err != nil {
+		panic(err)
+	}
+}
+
+// migrateBlogVersion starts the version of blogs created before optimistic concurrency control existed at 1
+func migrateBlogVersion(blogColl *mongo.Collection) {
+	filter := bson.M{
+		"version": bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{"version": 1},
+	}
+	if _, err := blogColl.UpdateMany(context.TODO(), filter, update); err != nil {
+		panic(err)
+	}
+}
+
+func respondBlogVersionConflict(c *fiber.Ctx, currentVersion int) error {
+	c.Set(fiber.HeaderETag, libs.FormatETag(currentVersion))
+	return c.Status(fiber.StatusPreconditionFailed).JSON(models.PreconditionFailedResponse{
+		Message:        "Blog has been changed by someone else",
+		CurrentVersion: currentVersion,
+	})
+}
+
+// respondBlogChanged answers a conditional write that lost a race against another writer
+func (ctr *BlogController) respondBlogChanged(ctx context.Context, c *fiber.Ctx, blogObjectID primitive.ObjectID) error {
+	var blog *models.Blog
+	opts := options.FindOne().SetProjection(bson.M{"version": 1})
+	if err := ctr.MongoBlogColl.FindOne(ctx, bson.M{"_id": blogObjectID, "deletedAt": nil}, opts).Decode(&blog); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Message: "Blog not found",
+			})
+		}
+		return utils.NewAppError(err)
 	}
+	return respondBlogVersionConflict(c, blog.Version)
 }
 
 // @summary		Get blogs
-// @description	Get all blogs (number of blogs per query is 10)
+// @description	Get published blogs, newest first. Follow the next and prev cursors of a page to get the pages around it
 // @id				GetBlogs
 // @tags			blogs
 // @accept			json
 // @produce		json
-// @param			from	query		int	true	"blog offset"	default(0)	maximum(0)
-// @success		200		{array}		models.Blog
-// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
-// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @param			cursor		query		string	false	"cursor of the page to get"
+// @param			limit		query		int		false	"number of blogs"	default(10)	minimum(1)	maximum(50)
+// @param			from		query		int		false	"blog offset, deprecated in favour of cursor"	default(0)	minimum(0)
+// @param			tag			query		string	false	"tag to filter by"
+// @param			category	query		string	false	"category slug to filter by, including its subcategories"
+// @param			expand		query		string	false	"related objects to include"	Enums(author)
+// @success		200			{object}	models.PaginatedResponse[models.Blog]
+// @failure		400			{object}	models.ErrorResponse			"invalid cursor"
+// @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500			{object}	models.ErrorResponse			"something went wrong"
 // @router			/api/blogs [get]
 func (ctr *BlogController) GetBlogs(c *fiber.Ctx) error {
 	query := c.Locals("query").(*validators.GetBlogsQuery)
 
 	ctx := context.TODO()
 
-	queryLimit := 10
-	opts := options.Find().SetSkip(int64(query.From)).SetLimit(int64(queryLimit)).SetSort(bson.D{{Key: "createdAt", Value: -1}})
-	cursor, err := ctr.MongoBlogColl.Find(ctx, bson.M{}, opts)
+	filter := bson.M{
+		"status":    models.BlogStatusPublished,
+		"deletedAt": nil,
+	}
+	found, err := ctr.applyTaxonomyFilter(ctx, filter, query.Tag, query.Category)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !found {
+		return respondPage(c, newCursorPage(c, []models.Blog{}, "", "", pageLimit(query.Limit), 0, false))
+	}
+
+	return ctr.respondBlogPage(ctx, c, filter, blogPageOptions{
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+		From:   query.From,
+		Expand: query.Expand,
+	})
+}
+
+type blogPageOptions struct {
+	Cursor string
+	Limit  int
+	// From is the deprecated offset, used when there is no cursor
+	From   int
+	Expand string
+}
+
+// respondBlogPage responds with the page of blogs matching filter selected by opts, newest first
+func (ctr *BlogController) respondBlogPage(ctx context.Context, c *fiber.Ctx, filter bson.M, pageOpts blogPageOptions) error {
+	var pageCursor *libs.Cursor
+	if pageOpts.Cursor != "" {
+		decoded, err := libs.DecodeCursor(pageOpts.Cursor)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Message: "Invalid cursor",
+			})
+		}
+		pageCursor = &decoded
+	}
+
+	limit := pageLimit(pageOpts.Limit)
+
+	total, totalIsEstimate, err := countPageTotal(ctx, ctr.MongoBlogColl, filter)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	opts, err := applyCursor(filter, pageCursor, limit)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if pageCursor == nil && pageOpts.From > 0 {
+		c.Set("Deprecation", "true")
+		opts.SetSkip(int64(pageOpts.From))
+	}
+	cursor, err := ctr.MongoBlogColl.Find(ctx, filter, opts)
 	if err != nil {
 		return utils.NewAppError(err)
 	}
 
-	var blogs []models.Blog
+	blogs := []models.Blog{}
 	if err = cursor.All(ctx, &blogs); err != nil {
 		return utils.NewAppError(err)
 	}
+	blogs, next, prev := cursorPage(blogs, blogCursorKey, pageCursor, limit, pageOpts.From > 0)
+	if pageOpts.Expand == blogExpandAuthor {
+		if err = ctr.expandBlogAuthors(ctx, blogs); err != nil {
+			return utils.NewAppError(err)
+		}
+	}
 
-	return c.Status(fiber.StatusOK).JSON(blogs)
+	return respondPage(c, newCursorPage(c, blogs, next, prev, limit, total, totalIsEstimate))
 }
 
 // @summary		Get blog by ID
-// @description	Get blog by ID
+// @description	Get blog by ID. Unpublished blogs are only visible to their author and to editors and moderators,
+// @description	who may also use a personal access token with the blogs:read scope
 // @id				GetByID
 // @tags			blogs
 // @accept			json
 // @produce		json
-// @param			id	path		string	true	"blog's ID"
-// @success		200	{object}	models.Blog
-// @failure		404	{object}	models.ErrorResponse			"blog not found"
-// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
-// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @param			id		path		string	true	"blog's ID"
+// @param			expand	query		string	false	"related objects to include"	Enums(author)
+// @success		200		{object}	models.Blog
+// @failure		404		{object}	models.ErrorResponse			"blog not found"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
 // @router			/api/blogs/:id [get]
 func (ctr *BlogController) GetBlogByID(c *fiber.Ctx) error {
 	params := c.Locals("params").(*validators.GetBlogByIDParams)
+	query := c.Locals("query").(*validators.GetBlogByIDQuery)
 	blogObjectID, _ := primitive.ObjectIDFromHex(params.ID)
 
+	ctx := context.TODO()
+
 	var blog *models.Blog
 
 	filter := bson.M{
-		"_id": blogObjectID,
+		"_id":       blogObjectID,
+		"deletedAt": nil,
 	}
-	if err := ctr.MongoBlogColl.FindOne(context.TODO(), filter).Decode(&blog); err != nil {
+	if err := ctr.MongoBlogColl.FindOne(ctx, filter).Decode(&blog); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 				Message: "Blog not found",
@@ -99,38 +294,143 @@ func (ctr *BlogController) GetBlogByID(c *fiber.Ctx) error {
 		return utils.NewAppError(err)
 	}
 
+	if !canViewBlog(c, blog) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "Blog not found",
+		})
+	}
+
+	if query.Expand == blogExpandAuthor {
+		blogs := []models.Blog{*blog}
+		if err := ctr.expandBlogAuthors(ctx, blogs); err != nil {
+			return utils.NewAppError(err)
+		}
+		blog = &blogs[0]
+	}
+
+	c.Set(fiber.HeaderETag, libs.FormatETag(blog.Version))
 	return c.Status(fiber.StatusOK).JSON(blog)
 }
 
+// canViewBlog reports whether the requester, who may not be logged in, may read blog. A personal access token
+// counts when it grants blogs:read
+func canViewBlog(c *fiber.Ctx, blog *models.Blog) bool {
+	viewer, err := libs.GetRequestUserData(c, models.AccessTokenScopeBlogsRead)
+	if err != nil {
+		viewer = nil
+	}
+	return policies.CanViewBlog(viewer, blog)
+}
+
 // @summary		Create blog
-// @description	Create new blog
+// @description	Create new blog as a draft, optionally scheduled to be published and unpublished
 // @id				CreateBlog
 // @tags			blogs
 // @accept			json
 // @produce		json
-// @param			title	body		string	true	"blog's title"
-// @param			content	body		string	true	"blog's content"
-// @success		201		{object}	string
-// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
-// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @param			title		body		string		true	"blog's title"
+// @param			content		body		string		true	"blog's content"	maxlength(100000)
+// @param			publishAt	body		string		false	"time to publish the blog (RFC 3339), needs blogs:publish_any"
+// @param			unpublishAt	body		string		false	"time to archive the blog (RFC 3339)"
+// @param			slug		body		string		false	"custom slug, generated from the title when omitted"
+// @param			tags		body		[]string	false	"tags"	maxitems(10)
+// @param			categoryId	body		string		false	"category's ID"
+// @success		201			{object}	models.CreatedResponse
+// @failure		400			{object}	models.ErrorResponse			"category not found"
+// @failure		409			{object}	models.ErrorResponse			"slug has been used or scheduling is not allowed"
+// @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500			{object}	models.ErrorResponse			"something went wrong"
 // @router			/api/blogs [post]
 func (ctr *BlogController) CreateBlog(c *fiber.Ctx) error {
 	payload := c.Locals("payload").(*validators.CreateBlogPayload)
 	user := c.Locals("user").(*models.UserSessionData)
 
+	ctx := context.TODO()
+
+	blogObjectID := primitive.NewObjectID()
+
+	if payload.PublishAt != nil && !policies.CanPublishBlog(user, &models.Blog{CreatedBy: user.ID}) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Access Denied",
+		})
+	}
+
+	if payload.CategoryID != "" {
+		exists, err := categoryExists(ctx, ctr.MongoCategoryColl, payload.CategoryID)
+		if err != nil {
+			return utils.NewAppError(err)
+		}
+		if !exists {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Message: "Category not found",
+			})
+		}
+	}
+
+	slug := payload.Slug
+	if slug != "" {
+		taken, err := isSlugTaken(ctx, ctr.MongoBlogColl, slug, blogObjectID)
+		if err != nil {
+			return utils.NewAppError(err)
+		}
+		if taken {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Message: "Slug has been used. Please use another slug",
+			})
+		}
+	} else {
+		var err error
+		if slug, err = generateUniqueSlug(ctx, ctr.MongoBlogColl, payload.Title, blogObjectID); err != nil {
+			return utils.NewAppError(err)
+		}
+	}
+
 	document := bson.D{
+		{Key: "_id", Value: blogObjectID},
 		{Key: "title", Value: payload.Title},
 		{Key: "content", Value: payload.Content},
+		{Key: "status", Value: models.BlogStatusDraft},
+		{Key: "revision", Value: 1},
+		{Key: "version", Value: 1},
+		{Key: "slug", Value: slug},
+		{Key: "customSlug", Value: payload.Slug != ""},
+		{Key: "tags", Value: libs.NormalizeTags(payload.Tags)},
 		{Key: "createdBy", Value: user.ID},
 		{Key: "createdAt", Value: time.Now()},
 	}
-	_, err := ctr.MongoBlogColl.InsertOne(context.TODO(), document)
+	if payload.PublishAt != nil {
+		document = append(document, bson.E{Key: "publishAt", Value: *payload.PublishAt})
+	}
+	if payload.UnpublishAt != nil {
+		document = append(document, bson.E{Key: "unpublishAt", Value: *payload.UnpublishAt})
+	}
+	if payload.CategoryID != "" {
+		document = append(document, bson.E{Key: "categoryId", Value: payload.CategoryID})
+	}
+	_, err := ctr.MongoBlogColl.InsertOne(ctx, document)
+	if mongo.IsDuplicateKeyError(err) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Slug has been used. Please use another slug",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+	blogID := blogObjectID.Hex()
+
+	err = insertBlogRevision(ctx, ctr.MongoBlogRevisionColl, models.BlogRevision{
+		BlogID:    blogID,
+		Revision:  1,
+		Title:     payload.Title,
+		Content:   payload.Content,
+		CreatedBy: user.ID,
+	})
 	if err != nil {
 		return utils.NewAppError(err)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(models.SuccessResponse{
+	return c.Status(fiber.StatusCreated).JSON(models.CreatedResponse{
 		Message: "Created",
+		ID:      blogID,
 	})
 }
 
@@ -140,18 +440,29 @@ func (ctr *BlogController) CreateBlog(c *fiber.Ctx) error {
 // @tags			blogs
 // @accept			json
 // @produce		json
-// @param			id		path		string	true	"blog's ID"
-// @param			title	body		string	true	"blog's title"
-// @param			content	body		string	true	"blog's content"
-// @success		200		{object}	string
-// @failure		404		{object}	models.ErrorResponse			"blog not found"
-// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
-// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @param			id			path		string	true	"blog's ID"
+// @param			title		body		string	true	"blog's title"
+// @param			content		body		string	true	"blog's content"	maxlength(100000)
+// @param			publishAt	body		string	false	"time to publish the blog (RFC 3339), omit to clear. Changing it needs blogs:publish_any"
+// @param			unpublishAt	body		string	false	"time to archive the blog (RFC 3339), omit to clear"
+// @param			slug		body		string		false	"custom slug, omit to follow the title"
+// @param			tags		body		[]string	false	"tags, omit to clear"	maxitems(10)
+// @param			categoryId	body		string		false	"category's ID, omit to clear"
+// @param			If-Match	header		string		true	"ETag of the blog version being updated"
+// @success		200			{object}	string
+// @failure		400			{object}	models.ErrorResponse				"category not found"
+// @failure		404			{object}	models.ErrorResponse				"blog not found"
+// @failure		409			{object}	models.ErrorResponse				"slug has been used or scheduling is not allowed"
+// @failure		412			{object}	models.PreconditionFailedResponse	"blog has been changed"
+// @failure		422			{array}		models.ValidationErrorResponse		"validation failed"
+// @failure		428			{object}	models.ErrorResponse				"If-Match header is missing"
+// @failure		500			{object}	models.ErrorResponse				"something went wrong"
 // @router			/api/blogs/:id [put]
 func (ctr *BlogController) UpdateBlog(c *fiber.Ctx) error {
 	params := c.Locals("params").(*validators.UpdateBlogParams)
 	body := c.Locals("payload").(*validators.UpdateBlogPayload)
 	user := c.Locals("user").(*models.UserSessionData)
+	ifMatch := c.Locals("ifMatch").(int)
 
 	ctx := context.TODO()
 
@@ -162,9 +473,18 @@ func (ctr *BlogController) UpdateBlog(c *fiber.Ctx) error {
 
 	var blog *models.Blog
 	filter := bson.M{
-		"_id": blogObjectID,
+		"_id":       blogObjectID,
+		"deletedAt": nil,
 	}
-	opts := options.FindOne().SetProjection(bson.M{"createdBy": 1})
+	opts := options.FindOne().SetProjection(bson.M{
+		"createdBy":   1,
+		"version":     1,
+		"title":       1,
+		"slug":        1,
+		"slugHistory": 1,
+		"customSlug":  1,
+		"publishAt":   1,
+	})
 	if err = ctr.MongoBlogColl.FindOne(ctx, filter, opts).Decode(&blog); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
@@ -173,43 +493,141 @@ func (ctr *BlogController) UpdateBlog(c *fiber.Ctx) error {
 		}
 		return utils.NewAppError(err)
 	}
-	if blog.CreatedBy != user.ID {
+	if !policies.CanEditBlog(user, blog) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Access Denied",
+		})
+	}
+	// keeping the schedule an editor set is fine, but setting one would publish the blog
+	reschedules := body.PublishAt != nil && (blog.PublishAt == nil || !blog.PublishAt.Time().Equal(*body.PublishAt))
+	if reschedules && !policies.CanPublishBlog(user, blog) {
 		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
 			Message: "Access Denied",
 		})
 	}
+	if blog.Version != ifMatch {
+		return respondBlogVersionConflict(c, blog.Version)
+	}
+
+	if body.Slug != "" && body.Slug != blog.Slug {
+		var taken bool
+		if taken, err = isSlugTaken(ctx, ctr.MongoBlogColl, body.Slug, blogObjectID); err != nil {
+			return utils.NewAppError(err)
+		}
+		if taken {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Message: "Slug has been used. Please use another slug",
+			})
+		}
+	}
+	if body.CategoryID != "" {
+		var exists bool
+		if exists, err = categoryExists(ctx, ctr.MongoCategoryColl, body.CategoryID); err != nil {
+			return utils.NewAppError(err)
+		}
+		if !exists {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Message: "Category not found",
+			})
+		}
+	}
+
+	slug, customSlug, err := nextBlogSlug(ctx, ctr.MongoBlogColl, blog, blogObjectID, body.Title, body.Slug)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	set := bson.D{
+		{Key: "title", Value: body.Title},
+		{Key: "content", Value: body.Content},
+		{Key: "customSlug", Value: customSlug},
+		{Key: "tags", Value: libs.NormalizeTags(body.Tags)},
+	}
+	if slug != blog.Slug {
+		set = append(set,
+			bson.E{Key: "slug", Value: slug},
+			bson.E{Key: "slugHistory", Value: slugHistoryAfterChange(blog.SlugHistory, blog.Slug, slug)},
+		)
+	}
+	unset := bson.D{}
+	if body.PublishAt != nil {
+		set = append(set, bson.E{Key: "publishAt", Value: *body.PublishAt})
+	} else {
+		unset = append(unset, bson.E{Key: "publishAt", Value: ""})
+	}
+	if body.UnpublishAt != nil {
+		set = append(set, bson.E{Key: "unpublishAt", Value: *body.UnpublishAt})
+	} else {
+		unset = append(unset, bson.E{Key: "unpublishAt", Value: ""})
+	}
+	if body.CategoryID != "" {
+		set = append(set, bson.E{Key: "categoryId", Value: body.CategoryID})
+	} else {
+		unset = append(unset, bson.E{Key: "categoryId", Value: ""})
+	}
 
 	document := bson.M{
-		"$set": bson.D{
-			{Key: "title", Value: body.Title},
-			{Key: "content", Value: body.Content},
-		},
+		"$set": set,
+		"$inc": bson.M{"revision": 1, "version": 1},
+	}
+	if len(unset) > 0 {
+		document["$unset"] = unset
 	}
-	_, err = ctr.MongoBlogColl.UpdateByID(ctx, blogObjectID, document)
+	var updated *models.Blog
+	filter["version"] = ifMatch
+	updateOpts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"revision": 1, "version": 1})
+	if err = ctr.MongoBlogColl.FindOneAndUpdate(ctx, filter, document, updateOpts).Decode(&updated); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ctr.respondBlogChanged(ctx, c, blogObjectID)
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Message: "Slug has been used. Please use another slug",
+			})
+		}
+		return utils.NewAppError(err)
+	}
+
+	err = insertBlogRevision(ctx, ctr.MongoBlogRevisionColl, models.BlogRevision{
+		BlogID:    params.ID,
+		Revision:  updated.Revision,
+		Title:     body.Title,
+		Content:   body.Content,
+		CreatedBy: user.ID,
+	})
 	if err != nil {
 		return utils.NewAppError(err)
 	}
 
+	ctr.SearchIndex.Notify(ctx, params.ID)
+
+	c.Set(fiber.HeaderETag, libs.FormatETag(updated.Version))
 	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
 		Message: "Updated",
 	})
 }
 
 // @summary		Delete blog
-// @description	Delete new blog
+// @description	Move a blog to the trash. Trashed blogs are purged after the retention period
 // @id				DeleteBlog
 // @tags			blogs
 // @accept			json
 // @produce		json
-// @param			id	path		string	true	"blog's ID"
-// @success		200	{object}	string
-// @failure		404	{object}	models.ErrorResponse			"blog not found"
-// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
-// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @param			id			path		string	true	"blog's ID"
+// @param			If-Match	header		string	true	"ETag of the blog version being deleted"
+// @success		200			{object}	string
+// @failure		404			{object}	models.ErrorResponse				"blog not found"
+// @failure		412			{object}	models.PreconditionFailedResponse	"blog has been changed"
+// @failure		422			{array}		models.ValidationErrorResponse		"validation failed"
+// @failure		428			{object}	models.ErrorResponse				"If-Match header is missing"
+// @failure		500			{object}	models.ErrorResponse				"something went wrong"
 // @router			/api/blogs/:id [delete]
 func (ctr *BlogController) DeleteBlog(c *fiber.Ctx) error {
 	params := c.Locals("params").(*validators.DeleteBlogParams)
 	user := c.Locals("user").(*models.UserSessionData)
+	ifMatch := c.Locals("ifMatch").(int)
 
 	blogObjectID, err := primitive.ObjectIDFromHex(params.ID)
 	if err != nil {
@@ -221,29 +639,379 @@ func (ctr *BlogController) DeleteBlog(c *fiber.Ctx) error {
 	var blog *models.Blog
 
 	filter := bson.M{
-		"_id": blogObjectID,
+		"_id":       blogObjectID,
+		"deletedAt": nil,
 	}
-	opts := options.FindOne().SetProjection(bson.D{{Key: "createdBy", Value: 1}})
+	opts := options.FindOne().SetProjection(bson.D{{Key: "createdBy", Value: 1}, {Key: "version", Value: 1}})
 	if err = ctr.MongoBlogColl.FindOne(ctx, filter, opts).Decode(&blog); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
 				Message: "Blog not found",
 			})
 		}
+		return utils.NewAppError(err)
 	}
 
-	if user.ID != blog.CreatedBy {
+	if !policies.CanDeleteBlog(user, blog) {
 		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
 			Message: "Access Denied",
 		})
 	}
+	if blog.Version != ifMatch {
+		return respondBlogVersionConflict(c, blog.Version)
+	}
 
-	_, err = ctr.MongoBlogColl.DeleteOne(ctx, filter)
+	document := bson.M{
+		"$set": bson.M{
+			"deletedAt": time.Now(),
+			"deletedBy": user.ID,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	filter["version"] = ifMatch
+	result, err := ctr.MongoBlogColl.UpdateOne(ctx, filter, document)
 	if err != nil {
 		return utils.NewAppError(err)
 	}
+	if result.MatchedCount == 0 {
+		return ctr.respondBlogChanged(ctx, c, blogObjectID)
+	}
+
+	ctr.SearchIndex.Notify(ctx, params.ID)
 
 	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
 		Message: "Deleted",
 	})
 }
+
+// @summary		Get my blogs
+// @description	Get blogs of the current user in any status, newest first
+// @id				GetMyBlogs
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			from	query		int		false	"blog offset"		default(0)	minimum(0)
+// @param			limit	query		int		false	"number of blogs"	default(10)	minimum(1)	maximum(50)
+// @param			status	query		string	false	"blog status"		Enums(draft, in_review, published, archived)
+// @success		200		{object}	models.PaginatedResponse[models.Blog]
+// @failure		401		{object}	models.ErrorResponse			"unauthorized"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/blogs [get]
+func (ctr *BlogController) GetMyBlogs(c *fiber.Ctx) error {
+	query := c.Locals("query").(*validators.GetMyBlogsQuery)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	filter := bson.M{
+		"createdBy": user.ID,
+		"deletedAt": nil,
+	}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+
+	total, totalIsEstimate, err := countPageTotal(ctx, ctr.MongoBlogColl, filter)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	limit := pageLimit(query.Limit)
+	opts := options.Find().SetSkip(int64(query.From)).SetLimit(int64(limit)).SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := ctr.MongoBlogColl.Find(ctx, filter, opts)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	blogs := []models.Blog{}
+	if err = cursor.All(ctx, &blogs); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return respondPage(c, newOffsetPage(c, blogs, query.From, limit, total, totalIsEstimate))
+}
+
+// @summary		Get my trash
+// @description	Get trashed blogs of the current user, most recently deleted first
+// @id				GetMyTrash
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			from	query		int	false	"blog offset"		default(0)	minimum(0)
+// @param			limit	query		int	false	"number of blogs"	default(10)	minimum(1)	maximum(50)
+// @success		200		{object}	models.PaginatedResponse[models.Blog]
+// @failure		401		{object}	models.ErrorResponse			"unauthorized"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/trash [get]
+func (ctr *BlogController) GetMyTrash(c *fiber.Ctx) error {
+	query := c.Locals("query").(*validators.GetMyTrashQuery)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	filter := bson.M{
+		"createdBy": user.ID,
+		"deletedAt": bson.M{"$ne": nil},
+	}
+
+	total, totalIsEstimate, err := countPageTotal(ctx, ctr.MongoBlogColl, filter)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	limit := pageLimit(query.Limit)
+	opts := options.Find().SetSkip(int64(query.From)).SetLimit(int64(limit)).SetSort(bson.D{{Key: "deletedAt", Value: -1}})
+	cursor, err := ctr.MongoBlogColl.Find(ctx, filter, opts)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	blogs := []models.Blog{}
+	if err = cursor.All(ctx, &blogs); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return respondPage(c, newOffsetPage(c, blogs, query.From, limit, total, totalIsEstimate))
+}
+
+// @summary		Restore blog
+// @description	Restore a blog from the trash
+// @id				RestoreBlog
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"blog's ID"
+// @success		200	{object}	models.SuccessResponse
+// @failure		404	{object}	models.ErrorResponse			"blog not found in trash"
+// @failure		409	{object}	models.ErrorResponse			"access denied"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/:id/restore [post]
+func (ctr *BlogController) RestoreBlog(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.RestoreBlogParams)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	blogObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	var blog *models.Blog
+	filter := bson.M{
+		"_id":       blogObjectID,
+		"deletedAt": bson.M{"$ne": nil},
+	}
+	opts := options.FindOne().SetProjection(bson.M{"createdBy": 1})
+	if err = ctr.MongoBlogColl.FindOne(ctx, filter, opts).Decode(&blog); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Message: "Blog not found in trash",
+			})
+		}
+		return utils.NewAppError(err)
+	}
+	if !policies.CanDeleteBlog(user, blog) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Access Denied",
+		})
+	}
+
+	document := bson.M{
+		"$unset": bson.M{
+			"deletedAt": "",
+			"deletedBy": "",
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	if _, err = ctr.MongoBlogColl.UpdateOne(ctx, filter, document); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	ctr.SearchIndex.Notify(ctx, params.ID)
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Restored",
+	})
+}
+
+// @summary		Submit blog
+// @description	Submit a draft blog for review
+// @id				SubmitBlog
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"blog's ID"
+// @success		200	{object}	models.SuccessResponse
+// @failure		404	{object}	models.ErrorResponse			"blog not found"
+// @failure		409	{object}	models.ErrorResponse			"status transition is not allowed"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/:id/submit [post]
+func (ctr *BlogController) SubmitBlog(c *fiber.Ctx) error {
+	return ctr.changeBlogStatus(c, models.BlogStatusInReview, "Submitted")
+}
+
+// @summary		Publish blog
+// @description	Publish a blog so it becomes publicly visible. Needs blogs:publish_any, even for the author, so that
+// @description	authors submit their blogs for review instead
+// @id				PublishBlog
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"blog's ID"
+// @success		200	{object}	models.SuccessResponse
+// @failure		404	{object}	models.ErrorResponse			"blog not found"
+// @failure		409	{object}	models.ErrorResponse			"status transition is not allowed"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/:id/publish [post]
+func (ctr *BlogController) PublishBlog(c *fiber.Ctx) error {
+	return ctr.changeBlogStatus(c, models.BlogStatusPublished, "Published")
+}
+
+// @summary		Unpublish blog
+// @description	Move a blog back to draft
+// @id				UnpublishBlog
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"blog's ID"
+// @success		200	{object}	models.SuccessResponse
+// @failure		404	{object}	models.ErrorResponse			"blog not found"
+// @failure		409	{object}	models.ErrorResponse			"status transition is not allowed"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/:id/unpublish [post]
+func (ctr *BlogController) UnpublishBlog(c *fiber.Ctx) error {
+	return ctr.changeBlogStatus(c, models.BlogStatusDraft, "Unpublished")
+}
+
+// @summary		Archive blog
+// @description	Archive a blog so it is hidden from public listing
+// @id				ArchiveBlog
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"blog's ID"
+// @success		200	{object}	models.SuccessResponse
+// @failure		404	{object}	models.ErrorResponse			"blog not found"
+// @failure		409	{object}	models.ErrorResponse			"status transition is not allowed"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/:id/archive [post]
+func (ctr *BlogController) ArchiveBlog(c *fiber.Ctx) error {
+	return ctr.changeBlogStatus(c, models.BlogStatusArchived, "Archived")
+}
+
+func (ctr *BlogController) changeBlogStatus(c *fiber.Ctx, next models.BlogStatus, message string) error {
+	params := c.Locals("params").(*validators.ChangeBlogStatusParams)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	blogObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	var blog *models.Blog
+	filter := bson.M{
+		"_id":       blogObjectID,
+		"deletedAt": nil,
+	}
+	opts := options.FindOne().SetProjection(bson.M{"createdBy": 1, "status": 1})
+	if err = ctr.MongoBlogColl.FindOne(ctx, filter, opts).Decode(&blog); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Message: "Blog not found",
+			})
+		}
+		return utils.NewAppError(err)
+	}
+	if !policies.CanChangeBlogStatus(user, blog, next) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Access Denied",
+		})
+	}
+	if !blog.Status.CanTransitionTo(next) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: fmt.Sprintf("Blog cannot be moved from %s to %s", blog.Status, next),
+		})
+	}
+
+	now := time.Now()
+	set := bson.M{"status": next}
+	unset := bson.M{}
+	switch next {
+	case models.BlogStatusPublished:
+		set["publishedAt"] = now
+		unset["archivedAt"] = ""
+		unset["publishAt"] = ""
+	case models.BlogStatusArchived:
+		set["archivedAt"] = now
+		unset["publishAt"] = ""
+		unset["unpublishAt"] = ""
+	case models.BlogStatusDraft, models.BlogStatusInReview:
+		unset["archivedAt"] = ""
+	}
+	document := bson.M{
+		"$set": set,
+		"$inc": bson.M{"version": 1},
+	}
+	if len(unset) > 0 {
+		document["$unset"] = unset
+	}
+
+	// the status condition guards against a concurrent transition between the read and the write
+	filter["status"] = blog.Status
+	result, err := ctr.MongoBlogColl.UpdateOne(ctx, filter, document)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if result.MatchedCount == 0 {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Blog status has been changed. Please try again",
+		})
+	}
+
+	ctr.SearchIndex.Notify(ctx, params.ID)
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: message,
+	})
+}
+
+// @summary		Get user blogs
+// @description	Get published blogs of a user, newest first, paginated like GetBlogs
+// @id				GetUserBlogs
+// @tags			users
+// @accept			json
+// @produce		json
+// @param			id		path		string	true	"user's ID"
+// @param			cursor	query		string	false	"cursor of the page to get"
+// @param			limit	query		int		false	"number of blogs"				default(10)	minimum(1)	maximum(50)
+// @param			expand	query		string	false	"related objects to include"	Enums(author)
+// @success		200		{object}	models.PaginatedResponse[models.Blog]
+// @failure		400		{object}	models.ErrorResponse			"invalid cursor"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/users/:id/blogs [get]
+func (ctr *BlogController) GetUserBlogs(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.GetUserBlogsParams)
+	query := c.Locals("query").(*validators.GetUserBlogsQuery)
+
+	filter := bson.M{
+		"createdBy": params.ID,
+		"status":    models.BlogStatusPublished,
+		"deletedAt": nil,
+	}
+	return ctr.respondBlogPage(context.TODO(), c, filter, blogPageOptions{
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+		Expand: query.Expand,
+	})
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"go_blogs/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const blogExpandAuthor = "author"
+
+// expandBlogAuthors sets the author of every blog, loading all authors with a single query.
+// Blogs whose author no longer exists are left without one
+func (ctr *BlogController) expandBlogAuthors(ctx context.Context, blogs []models.Blog) error {
+	seen := map[string]bool{}
+	var userObjectIDs []primitive.ObjectID
+	for _, blog := range blogs {
+		if seen[blog.CreatedBy] {
+			continue
+		}
+		seen[blog.CreatedBy] = true
+		if userObjectID, err := primitive.ObjectIDFromHex(blog.CreatedBy); err == nil {
+			userObjectIDs = append(userObjectIDs, userObjectID)
+		}
+	}
+	if len(userObjectIDs) == 0 {
+		return nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"name": 1, "handle": 1, "avatarUrl": 1})
+	cursor, err := ctr.MongoUserColl.Find(ctx, bson.M{"_id": bson.M{"$in": userObjectIDs}}, opts)
+	if err != nil {
+		return err
+	}
+
+	var authors []models.PublicUser
+	if err = cursor.All(ctx, &authors); err != nil {
+		return err
+	}
+
+	authorsByID := make(map[string]*models.PublicUser, len(authors))
+	for i := range authors {
+		authorsByID[authors[i].ID] = &authors[i]
+	}
+	for i := range blogs {
+		blogs[i].Author = authorsByID[blogs[i].CreatedBy]
+	}
+	return nil
+}
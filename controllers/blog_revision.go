@@ -0,0 +1,382 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/policies"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func insertBlogRevision(ctx context.Context, blogRevisionColl *mongo.Collection, revision models.BlogRevision) error {
+	document := bson.D{
+		{Key: "blogId", Value: revision.BlogID},
+		{Key: "revision", Value: revision.Revision},
+		{Key: "title", Value: revision.Title},
+		{Key: "content", Value: revision.Content},
+		{Key: "createdBy", Value: revision.CreatedBy},
+		{Key: "createdAt", Value: time.Now()},
+	}
+	if revision.RestoredFrom > 0 {
+		document = append(document, bson.E{Key: "restoredFrom", Value: revision.RestoredFrom})
+	}
+	_, err := blogRevisionColl.InsertOne(ctx, document)
+	return err
+}
+
+// migrateBlogRevisions records the current content of blogs created before revisions existed as their first revision
+func migrateBlogRevisions(blogColl *mongo.Collection, blogRevisionColl *mongo.Collection) {
+	ctx := context.TODO()
+
+	filter := bson.M{
+		"revision": bson.M{"$exists": false},
+	}
+	cursor, err := blogColl.Find(ctx, filter)
+	if err != nil {
+		panic(err)
+	}
+
+	var blogs []models.Blog
+	if err = cursor.All(ctx, &blogs); err != nil {
+		panic(err)
+	}
+
+	for _, blog := range blogs {
+		revisionFilter := bson.M{
+			"blogId":   blog.ID,
+			"revision": 1,
+		}
+		revision := bson.M{
+			"$setOnInsert": bson.M{
+				"title":     blog.Title,
+				"content":   blog.Content,
+				"createdBy": blog.CreatedBy,
+				"createdAt": blog.CreatedAt,
+			},
+		}
+		_, err = blogRevisionColl.UpdateOne(ctx, revisionFilter, revision, options.Update().SetUpsert(true))
+		if err != nil {
+			panic(err)
+		}
+
+		blogObjectID, _ := primitive.ObjectIDFromHex(blog.ID)
+		if _, err = blogColl.UpdateByID(ctx, blogObjectID, bson.M{"$set": bson.M{"revision": 1}}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// findEditableBlog loads a blog and reports whether user may edit it, which revisions are part of
+func (ctr *BlogController) findEditableBlog(ctx context.Context, blogID string, user *models.UserSessionData, projection bson.M) (*models.Blog, bool, error) {
+	blogObjectID, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	projection["createdBy"] = 1
+
+	var blog *models.Blog
+	opts := options.FindOne().SetProjection(projection)
+	if err = ctr.MongoBlogColl.FindOne(ctx, bson.M{"_id": blogObjectID, "deletedAt": nil}, opts).Decode(&blog); err != nil {
+		return nil, false, err
+	}
+
+	return blog, policies.CanEditBlog(user, blog), nil
+}
+
+func (ctr *BlogController) respondBlogLookupError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "Blog not found",
+		})
+	}
+	return utils.NewAppError(err)
+}
+
+func (ctr *BlogController) respondRevisionLookupError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "Revision not found",
+		})
+	}
+	return utils.NewAppError(err)
+}
+
+// @summary		Get blog revisions
+// @description	Get revisions of a blog without their content, newest first
+// @id				GetBlogRevisions
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			id		path		string	true	"blog's ID"
+// @param			from	query		int		false	"revision offset"		default(0)	minimum(0)
+// @param			limit	query		int		false	"number of revisions"	default(10)	minimum(1)	maximum(50)
+// @success		200		{object}	models.PaginatedResponse[models.BlogRevision]
+// @failure		404		{object}	models.ErrorResponse			"blog not found"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/:id/revisions [get]
+func (ctr *BlogController) GetBlogRevisions(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.GetBlogRevisionsParams)
+	query := c.Locals("query").(*validators.GetBlogRevisionsQuery)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	_, canEdit, err := ctr.findEditableBlog(ctx, params.ID, user, bson.M{})
+	if err != nil {
+		return ctr.respondBlogLookupError(c, err)
+	}
+	if !canEdit {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Access Denied",
+		})
+	}
+
+	filter := bson.M{"blogId": params.ID}
+	total, totalIsEstimate, err := countPageTotal(ctx, ctr.MongoBlogRevisionColl, filter)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	limit := pageLimit(query.Limit)
+	opts := options.Find().
+		SetSkip(int64(query.From)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "revision", Value: -1}}).
+		SetProjection(bson.M{"content": 0})
+	cursor, err := ctr.MongoBlogRevisionColl.Find(ctx, filter, opts)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	revisions := []models.BlogRevision{}
+	if err = cursor.All(ctx, &revisions); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return respondPage(c, newOffsetPage(c, revisions, query.From, limit, total, totalIsEstimate))
+}
+
+// @summary		Get blog revision
+// @description	Get a single revision of a blog
+// @id				GetBlogRevision
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"blog's ID"
+// @param			rev	path		int		true	"revision number"	minimum(1)
+// @success		200	{object}	models.BlogRevision
+// @failure		404	{object}	models.ErrorResponse			"blog or revision not found"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/:id/revisions/:rev [get]
+func (ctr *BlogController) GetBlogRevision(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.GetBlogRevisionParams)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	_, canEdit, err := ctr.findEditableBlog(ctx, params.ID, user, bson.M{})
+	if err != nil {
+		return ctr.respondBlogLookupError(c, err)
+	}
+	if !canEdit {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Access Denied",
+		})
+	}
+
+	revision, err := ctr.findBlogRevision(ctx, params.ID, params.Rev)
+	if err != nil {
+		return ctr.respondRevisionLookupError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(revision)
+}
+
+// @summary		Diff blog revisions
+// @description	Get a line-level diff of title and content between two revisions of a blog
+// @id				DiffBlogRevisions
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			id		path		string	true	"blog's ID"
+// @param			from	query		int		true	"base revision number"		minimum(1)
+// @param			to		query		int		true	"compared revision number"	minimum(1)
+// @success		200		{object}	models.BlogRevisionDiff
+// @failure		404		{object}	models.ErrorResponse			"blog or revision not found"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/:id/revisions/diff [get]
+func (ctr *BlogController) DiffBlogRevisions(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.DiffBlogRevisionsParams)
+	query := c.Locals("query").(*validators.DiffBlogRevisionsQuery)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	_, canEdit, err := ctr.findEditableBlog(ctx, params.ID, user, bson.M{})
+	if err != nil {
+		return ctr.respondBlogLookupError(c, err)
+	}
+	if !canEdit {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Access Denied",
+		})
+	}
+
+	from, err := ctr.findBlogRevision(ctx, params.ID, query.From)
+	if err != nil {
+		return ctr.respondRevisionLookupError(c, err)
+	}
+	to, err := ctr.findBlogRevision(ctx, params.ID, query.To)
+	if err != nil {
+		return ctr.respondRevisionLookupError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.BlogRevisionDiff{
+		From:    from.Revision,
+		To:      to.Revision,
+		Title:   libs.DiffLines(from.Title, to.Title),
+		Content: libs.DiffLines(from.Content, to.Content),
+	})
+}
+
+// @summary		Restore blog revision
+// @description	Restore the title and content of an old revision as a new revision. A slug that follows the title
+// @description	follows the restored title, and the former slug keeps redirecting to the blog
+// @id				RestoreBlogRevision
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			id			path		string	true	"blog's ID"
+// @param			rev			path		int		true	"revision number to restore"	minimum(1)
+// @param			If-Match	header		string	true	"ETag of the blog version being replaced"
+// @success		200			{object}	models.SuccessResponse
+// @failure		404			{object}	models.ErrorResponse				"blog or revision not found"
+// @failure		409			{object}	models.ErrorResponse				"access denied or slug has been used"
+// @failure		412			{object}	models.PreconditionFailedResponse	"blog has been changed"
+// @failure		422			{array}		models.ValidationErrorResponse		"validation failed"
+// @failure		428			{object}	models.ErrorResponse				"If-Match header is missing"
+// @failure		500			{object}	models.ErrorResponse				"something went wrong"
+// @router			/api/blogs/:id/revisions/:rev/restore [post]
+func (ctr *BlogController) RestoreBlogRevision(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.RestoreBlogRevisionParams)
+	user := c.Locals("user").(*models.UserSessionData)
+	ifMatch := c.Locals("ifMatch").(int)
+
+	ctx := context.TODO()
+
+	blog, canEdit, err := ctr.findEditableBlog(ctx, params.ID, user, bson.M{
+		"version":     1,
+		"title":       1,
+		"slug":        1,
+		"slugHistory": 1,
+		"customSlug":  1,
+	})
+	if err != nil {
+		return ctr.respondBlogLookupError(c, err)
+	}
+	if !canEdit {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Access Denied",
+		})
+	}
+	if blog.Version != ifMatch {
+		return respondBlogVersionConflict(c, blog.Version)
+	}
+
+	revision, err := ctr.findBlogRevision(ctx, params.ID, params.Rev)
+	if err != nil {
+		return ctr.respondRevisionLookupError(c, err)
+	}
+
+	blogObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	// a custom slug is kept, since only the title and content are restored
+	slug := blog.Slug
+	if !blog.CustomSlug {
+		if slug, _, err = nextBlogSlug(ctx, ctr.MongoBlogColl, blog, blogObjectID, revision.Title, ""); err != nil {
+			return utils.NewAppError(err)
+		}
+	}
+
+	set := bson.D{
+		{Key: "title", Value: revision.Title},
+		{Key: "content", Value: revision.Content},
+	}
+	if slug != blog.Slug {
+		set = append(set,
+			bson.E{Key: "slug", Value: slug},
+			bson.E{Key: "slugHistory", Value: slugHistoryAfterChange(blog.SlugHistory, blog.Slug, slug)},
+		)
+	}
+	document := bson.M{
+		"$set": set,
+		"$inc": bson.M{"revision": 1, "version": 1},
+	}
+	var updated *models.Blog
+	filter := bson.M{
+		"_id":       blogObjectID,
+		"deletedAt": nil,
+		"version":   ifMatch,
+	}
+	updateOpts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"revision": 1, "version": 1})
+	if err = ctr.MongoBlogColl.FindOneAndUpdate(ctx, filter, document, updateOpts).Decode(&updated); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ctr.respondBlogChanged(ctx, c, blogObjectID)
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Message: "Slug has been used. Please try again",
+			})
+		}
+		return utils.NewAppError(err)
+	}
+
+	err = insertBlogRevision(ctx, ctr.MongoBlogRevisionColl, models.BlogRevision{
+		BlogID:       params.ID,
+		Revision:     updated.Revision,
+		Title:        revision.Title,
+		Content:      revision.Content,
+		CreatedBy:    user.ID,
+		RestoredFrom: revision.Revision,
+	})
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	ctr.SearchIndex.Notify(ctx, params.ID)
+
+	c.Set(fiber.HeaderETag, libs.FormatETag(updated.Version))
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Restored",
+	})
+}
+
+func (ctr *BlogController) findBlogRevision(ctx context.Context, blogID string, rev int) (*models.BlogRevision, error) {
+	var revision *models.BlogRevision
+	filter := bson.M{
+		"blogId":   blogID,
+		"revision": rev,
+	}
+	if err := ctr.MongoBlogRevisionColl.FindOne(ctx, filter).Decode(&revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/search"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const searchDateLayout = "2006-01-02"
+
+// @summary		Search blogs
+// @description	Search published blogs by title and content, most relevant first.
+// @description	Words must all match; use "double quotes" for a phrase and a trailing * for a prefix, as in gorout*
+// @id				SearchBlogs
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			q		query		string	true	"search text"	maxlength(200)
+// @param			from	query		int		false	"result offset"		default(0)	minimum(0)
+// @param			limit	query		int		false	"number of results"	default(10)	minimum(1)	maximum(50)
+// @param			author	query		string	false	"author's ID"
+// @param			tag		query		string	false	"tag to filter by"
+// @param			since	query		string	false	"published on or after, as YYYY-MM-DD"
+// @param			until	query		string	false	"published on or before, as YYYY-MM-DD"
+// @success		200		{object}	models.PaginatedResponse[models.BlogSearchHit]
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/search [get]
+func (ctr *BlogController) SearchBlogs(c *fiber.Ctx) error {
+	query := c.Locals("query").(*validators.SearchBlogsQuery)
+
+	ctx := context.TODO()
+
+	limit := pageLimit(query.Limit)
+	searchQuery := search.Query{
+		Text:   query.Q,
+		Author: query.Author,
+		Tag:    libs.NormalizeTag(query.Tag),
+		Offset: query.From,
+		Limit:  limit,
+	}
+	if query.Since != "" {
+		since, err := time.Parse(searchDateLayout, query.Since)
+		if err != nil {
+			return utils.NewAppError(err)
+		}
+		searchQuery.Since = &since
+	}
+	if query.Until != "" {
+		until, err := time.Parse(searchDateLayout, query.Until)
+		if err != nil {
+			return utils.NewAppError(err)
+		}
+		// until is inclusive, so the search stops at the start of the following day
+		until = until.AddDate(0, 0, 1)
+		searchQuery.Until = &until
+	}
+
+	result, err := ctr.SearchIndex.Backend.Search(ctx, searchQuery)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	blogs, err := ctr.findSearchHitBlogs(ctx, result.Hits)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	hits := []models.BlogSearchHit{}
+	total := int64(result.Total)
+	for _, hit := range result.Hits {
+		blog, ok := blogs[hit.ID]
+		if !ok {
+			// the blog stopped being searchable after the index was queried
+			total--
+			continue
+		}
+		hits = append(hits, models.BlogSearchHit{
+			Blog:    blog,
+			Score:   hit.Score,
+			Title:   hit.Title,
+			Snippet: hit.Snippet,
+		})
+	}
+
+	return respondPage(c, newOffsetPage(c, hits, query.From, limit, total, false))
+}
+
+// findSearchHitBlogs loads the blogs of hits, without their content, keyed by ID
+func (ctr *BlogController) findSearchHitBlogs(ctx context.Context, hits []search.Hit) (map[string]models.Blog, error) {
+	blogs := map[string]models.Blog{}
+	if len(hits) == 0 {
+		return blogs, nil
+	}
+
+	blogObjectIDs := make([]primitive.ObjectID, 0, len(hits))
+	for _, hit := range hits {
+		blogObjectID, err := primitive.ObjectIDFromHex(hit.ID)
+		if err != nil {
+			return nil, err
+		}
+		blogObjectIDs = append(blogObjectIDs, blogObjectID)
+	}
+
+	filter := bson.M{
+		"_id":       bson.M{"$in": blogObjectIDs},
+		"status":    models.BlogStatusPublished,
+		"deletedAt": nil,
+	}
+	opts := options.Find().SetProjection(bson.M{"content": 0})
+	cursor, err := ctr.MongoBlogColl.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []models.Blog
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for _, blog := range found {
+		blogs[blog.ID] = blog
+	}
+	return blogs, nil
+}
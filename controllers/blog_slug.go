@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	fallbackSlug         = "post"
+	maxNumberedSlugTries = 20
+)
+
+// isSlugTaken reports whether slug is the current or a former slug of any blog other than blogObjectID
+func isSlugTaken(ctx context.Context, blogColl *mongo.Collection, slug string, blogObjectID primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id": bson.M{"$ne": blogObjectID},
+		"$or": bson.A{
+			bson.M{"slug": slug},
+			bson.M{"slugHistory": slug},
+		},
+	}
+	count, err := blogColl.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// generateUniqueSlug derives a slug from title and numbers it until no other blog uses it
+func generateUniqueSlug(ctx context.Context, blogColl *mongo.Collection, title string, blogObjectID primitive.ObjectID) (string, error) {
+	base := libs.Slugify(title)
+	if base == "" {
+		base = fallbackSlug
+	}
+
+	for i := 1; i <= maxNumberedSlugTries; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		taken, err := isSlugTaken(ctx, blogColl, candidate, blogObjectID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+
+	// the object ID suffix is unique by itself
+	return fmt.Sprintf("%s-%s", base, blogObjectID.Hex()[len(blogObjectID.Hex())-8:]), nil
+}
+
+// migrateBlogSlugs generates slugs for blogs created before slugs existed
+func migrateBlogSlugs(blogColl *mongo.Collection) {
+	ctx := context.TODO()
+
+	filter := bson.M{
+		"slug": bson.M{"$exists": false},
+	}
+	opts := options.Find().SetProjection(bson.M{"title": 1}).SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := blogColl.Find(ctx, filter, opts)
+	if err != nil {
+		panic(err)
+	}
+
+	var blogs []models.Blog
+	if err = cursor.All(ctx, &blogs); err != nil {
+		panic(err)
+	}
+
+	for _, blog := range blogs {
+		blogObjectID, _ := primitive.ObjectIDFromHex(blog.ID)
+		slug, err := generateUniqueSlug(ctx, blogColl, blog.Title, blogObjectID)
+		if err != nil {
+			panic(err)
+		}
+		if _, err = blogColl.UpdateByID(ctx, blogObjectID, bson.M{"$set": bson.M{"slug": slug}}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// nextBlogSlug decides the slug of a blog after an update. A custom slug is kept until the author clears it;
+// otherwise the slug follows the title
+func nextBlogSlug(ctx context.Context, blogColl *mongo.Collection, blog *models.Blog, blogObjectID primitive.ObjectID, title string, customSlug string) (string, bool, error) {
+	if customSlug != "" {
+		return customSlug, true, nil
+	}
+	if !blog.CustomSlug && title == blog.Title && blog.Slug != "" {
+		return blog.Slug, false, nil
+	}
+	slug, err := generateUniqueSlug(ctx, blogColl, title, blogObjectID)
+	return slug, false, err
+}
+
+// slugHistoryAfterChange returns the former slugs of a blog once its slug moves from oldSlug to newSlug
+func slugHistoryAfterChange(history []string, oldSlug string, newSlug string) []string {
+	result := []string{}
+	for _, slug := range history {
+		if slug != newSlug && slug != oldSlug {
+			result = append(result, slug)
+		}
+	}
+	if oldSlug != "" && oldSlug != newSlug {
+		result = append(result, oldSlug)
+	}
+	return result
+}
+
+// @summary		Get blog by slug
+// @description	Get blog by its slug. A former slug redirects permanently to the current one
+// @id				GetBlogBySlug
+// @tags			blogs
+// @accept			json
+// @produce		json
+// @param			slug	path		string	true	"blog's slug"
+// @success		200		{object}	models.Blog
+// @success		301		{string}	string							"blog has moved to a new slug"
+// @failure		404		{object}	models.ErrorResponse			"blog not found"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/blogs/by-slug/:slug [get]
+func (ctr *BlogController) GetBlogBySlug(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.GetBlogBySlugParams)
+
+	ctx := context.TODO()
+
+	var blog *models.Blog
+	filter := bson.M{
+		"slug":      params.Slug,
+		"deletedAt": nil,
+	}
+	err := ctr.MongoBlogColl.FindOne(ctx, filter).Decode(&blog)
+	if err == nil && canViewBlog(c, blog) {
+		c.Set(fiber.HeaderETag, libs.FormatETag(blog.Version))
+		return c.Status(fiber.StatusOK).JSON(blog)
+	}
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return utils.NewAppError(err)
+	}
+
+	filter = bson.M{
+		"slugHistory": params.Slug,
+		"deletedAt":   nil,
+	}
+	opts := options.FindOne().SetProjection(bson.M{"slug": 1, "status": 1, "createdBy": 1})
+	err = ctr.MongoBlogColl.FindOne(ctx, filter, opts).Decode(&blog)
+	if err == nil && canViewBlog(c, blog) {
+		return c.Redirect(fmt.Sprintf("/api/blogs/by-slug/%s", blog.Slug), fiber.StatusMovedPermanently)
+	}
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+		Message: "Blog not found",
+	})
+}
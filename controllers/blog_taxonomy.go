@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultTagCloudLimit = 50
+
+// applyTaxonomyFilter narrows filter to blogs tagged with tag and filed under the category with categorySlug
+// or any of its subcategories. It returns false when the category does not exist, so nothing can match
+func (ctr *BlogController) applyTaxonomyFilter(ctx context.Context, filter bson.M, tag string, categorySlug string) (bool, error) {
+	if tag != "" {
+		filter["tags"] = libs.NormalizeTag(tag)
+	}
+	if categorySlug == "" {
+		return true, nil
+	}
+
+	var category *models.Category
+	err := ctr.MongoCategoryColl.FindOne(ctx, bson.M{"slug": categorySlug}).Decode(&category)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	categories, err := loadCategories(ctx, ctr.MongoCategoryColl)
+	if err != nil {
+		return false, err
+	}
+	filter["categoryId"] = bson.M{"$in": categoryDescendantIDs(categories, category.ID)}
+	return true, nil
+}
+
+// @summary		Get tags
+// @description	Get the most used tags of published blogs with their number of blogs
+// @id				GetTags
+// @tags			tags
+// @accept			json
+// @produce		json
+// @param			limit	query		int	false	"number of tags"	default(50)	minimum(1)	maximum(100)
+// @success		200		{array}		models.TagCount
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/tags [get]
+func (ctr *BlogController) GetTags(c *fiber.Ctx) error {
+	query := c.Locals("query").(*validators.GetTagsQuery)
+
+	ctx := context.TODO()
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultTagCloudLimit
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"status":    models.BlogStatusPublished,
+			"deletedAt": nil,
+		}}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$tags",
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"_id": 0, "tag": "$_id", "count": 1}}},
+	}
+	cursor, err := ctr.MongoBlogColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	tags := []models.TagCount{}
+	if err = cursor.All(ctx, &tags); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(tags)
+}
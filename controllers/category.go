@@ -0,0 +1,412 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const fallbackCategorySlug = "category"
+
+type categoryController interface {
+	GetCategories(c *fiber.Ctx) error
+	CreateCategory(c *fiber.Ctx) error
+	UpdateCategory(c *fiber.Ctx) error
+	DeleteCategory(c *fiber.Ctx) error
+}
+
+type CategoryController struct {
+	MongoCategoryColl *mongo.Collection
+	MongoBlogColl     *mongo.Collection
+}
+
+func NewCategoryControllers() categoryController {
+	database := connections.MongoClient.Database(configs.Env.MongoDatabase)
+
+	categoryColl := connections.NewMongoCollection(database, "categories")
+	connections.CreateMongoIndexes(
+		categoryColl,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		mongo.IndexModel{Keys: bson.D{{Key: "parentId", Value: 1}}},
+	)
+
+	return &CategoryController{
+		MongoCategoryColl: categoryColl,
+		MongoBlogColl:     database.Collection("blogs"),
+	}
+}
+
+func loadCategories(ctx context.Context, categoryColl *mongo.Collection) ([]models.Category, error) {
+	cursor, err := categoryColl.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []models.Category{}
+	if err = cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// categoryDescendantIDs returns rootID and the IDs of every category below it
+func categoryDescendantIDs(categories []models.Category, rootID string) []string {
+	children := map[string][]string{}
+	for _, category := range categories {
+		children[category.ParentID] = append(children[category.ParentID], category.ID)
+	}
+
+	ids := []string{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// buildCategoryTree nests categories under their parents and adds up blog counts from the leaves
+func buildCategoryTree(categories []models.Category, blogCounts map[string]int64) []models.CategoryNode {
+	children := map[string][]models.Category{}
+	for _, category := range categories {
+		children[category.ParentID] = append(children[category.ParentID], category)
+	}
+
+	var build func(parentID string) []models.CategoryNode
+	build = func(parentID string) []models.CategoryNode {
+		nodes := []models.CategoryNode{}
+		for _, category := range children[parentID] {
+			node := models.CategoryNode{
+				ID:        category.ID,
+				Name:      category.Name,
+				Slug:      category.Slug,
+				ParentID:  category.ParentID,
+				BlogCount: blogCounts[category.ID],
+				Children:  build(category.ID),
+			}
+			node.TotalBlogCount = node.BlogCount
+			for _, child := range node.Children {
+				node.TotalBlogCount += child.TotalBlogCount
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return build("")
+}
+
+// @summary		Get categories
+// @description	Get the category tree with the number of published blogs in each category
+// @id				GetCategories
+// @tags			categories
+// @accept			json
+// @produce		json
+// @success		200	{array}		models.CategoryNode
+// @failure		500	{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/categories [get]
+func (ctr *CategoryController) GetCategories(c *fiber.Ctx) error {
+	ctx := context.TODO()
+
+	categories, err := loadCategories(ctx, ctr.MongoCategoryColl)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"status":     models.BlogStatusPublished,
+			"deletedAt":  nil,
+			"categoryId": bson.M{"$exists": true},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$categoryId",
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+	cursor, err := ctr.MongoBlogColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	var counts []struct {
+		ID    string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err = cursor.All(ctx, &counts); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	blogCounts := map[string]int64{}
+	for _, count := range counts {
+		blogCounts[count.ID] = count.Count
+	}
+
+	return c.Status(fiber.StatusOK).JSON(buildCategoryTree(categories, blogCounts))
+}
+
+// @summary		Create category
+// @description	Create new category, optionally below a parent category
+// @id				CreateCategory
+// @tags			categories
+// @accept			json
+// @produce		json
+// @param			name		body		string	true	"category's name"	maxlength(50)
+// @param			slug		body		string	false	"category's slug, generated from the name when omitted"
+// @param			parentId	body		string	false	"parent category's ID"
+// @success		201			{object}	models.CreatedResponse
+// @failure		400			{object}	models.ErrorResponse			"parent category not found"
+// @failure		401			{object}	models.ErrorResponse			"unauthorized"
+// @failure		409			{object}	models.ErrorResponse			"slug has been used"
+// @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500			{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/categories [post]
+func (ctr *CategoryController) CreateCategory(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.CreateCategoryPayload)
+
+	ctx := context.TODO()
+
+	if payload.ParentID != "" {
+		exists, err := ctr.categoryExists(ctx, payload.ParentID)
+		if err != nil {
+			return utils.NewAppError(err)
+		}
+		if !exists {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Message: "Parent category not found",
+			})
+		}
+	}
+
+	categoryObjectID := primitive.NewObjectID()
+	slug, err := categorySlug(ctx, ctr.MongoCategoryColl, payload.Name, payload.Slug, categoryObjectID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	document := bson.D{
+		{Key: "_id", Value: categoryObjectID},
+		{Key: "name", Value: payload.Name},
+		{Key: "slug", Value: slug},
+		{Key: "createdAt", Value: time.Now()},
+	}
+	if payload.ParentID != "" {
+		document = append(document, bson.E{Key: "parentId", Value: payload.ParentID})
+	}
+	_, err = ctr.MongoCategoryColl.InsertOne(ctx, document)
+	if mongo.IsDuplicateKeyError(err) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Slug has been used. Please use another slug",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.CreatedResponse{
+		Message: "Created",
+		ID:      categoryObjectID.Hex(),
+	})
+}
+
+// @summary		Update category
+// @description	Rename or move a category
+// @id				UpdateCategory
+// @tags			categories
+// @accept			json
+// @produce		json
+// @param			id			path		string	true	"category's ID"
+// @param			name		body		string	true	"category's name"	maxlength(50)
+// @param			slug		body		string	false	"category's slug, generated from the name when omitted"
+// @param			parentId	body		string	false	"parent category's ID, omit to move to the top level"
+// @success		200			{object}	models.SuccessResponse
+// @failure		400			{object}	models.ErrorResponse			"parent category not found or is a descendant"
+// @failure		401			{object}	models.ErrorResponse			"unauthorized"
+// @failure		404			{object}	models.ErrorResponse			"category not found"
+// @failure		409			{object}	models.ErrorResponse			"slug has been used"
+// @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500			{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/categories/:id [put]
+func (ctr *CategoryController) UpdateCategory(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.UpdateCategoryParams)
+	payload := c.Locals("payload").(*validators.UpdateCategoryPayload)
+
+	ctx := context.TODO()
+
+	categories, err := loadCategories(ctx, ctr.MongoCategoryColl)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	found := false
+	parentFound := payload.ParentID == ""
+	for _, category := range categories {
+		found = found || category.ID == params.ID
+		parentFound = parentFound || category.ID == payload.ParentID
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "Category not found",
+		})
+	}
+	if !parentFound {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Parent category not found",
+		})
+	}
+	for _, id := range categoryDescendantIDs(categories, params.ID) {
+		if id == payload.ParentID {
+			return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+				Message: "Category cannot be moved below itself",
+			})
+		}
+	}
+
+	categoryObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	slug, err := categorySlug(ctx, ctr.MongoCategoryColl, payload.Name, payload.Slug, categoryObjectID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	document := bson.M{
+		"$set": bson.M{
+			"name": payload.Name,
+			"slug": slug,
+		},
+	}
+	if payload.ParentID != "" {
+		document["$set"].(bson.M)["parentId"] = payload.ParentID
+	} else {
+		document["$unset"] = bson.M{"parentId": ""}
+	}
+	_, err = ctr.MongoCategoryColl.UpdateByID(ctx, categoryObjectID, document)
+	if mongo.IsDuplicateKeyError(err) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Slug has been used. Please use another slug",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Updated",
+	})
+}
+
+// @summary		Delete category
+// @description	Delete a category that has no subcategories and no blogs
+// @id				DeleteCategory
+// @tags			categories
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"category's ID"
+// @success		200	{object}	models.SuccessResponse
+// @failure		401	{object}	models.ErrorResponse			"unauthorized"
+// @failure		404	{object}	models.ErrorResponse			"category not found"
+// @failure		409	{object}	models.ErrorResponse			"category is in use"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/categories/:id [delete]
+func (ctr *CategoryController) DeleteCategory(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.DeleteCategoryParams)
+
+	ctx := context.TODO()
+
+	exists, err := ctr.categoryExists(ctx, params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !exists {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "Category not found",
+		})
+	}
+
+	childCount, err := ctr.MongoCategoryColl.CountDocuments(ctx, bson.M{"parentId": params.ID}, options.Count().SetLimit(1))
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	blogCount, err := ctr.MongoBlogColl.CountDocuments(ctx, bson.M{"categoryId": params.ID}, options.Count().SetLimit(1))
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if childCount > 0 || blogCount > 0 {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Category still has subcategories or blogs",
+		})
+	}
+
+	categoryObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if _, err = ctr.MongoCategoryColl.DeleteOne(ctx, bson.M{"_id": categoryObjectID}); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Deleted",
+	})
+}
+
+func (ctr *CategoryController) categoryExists(ctx context.Context, categoryID string) (bool, error) {
+	return categoryExists(ctx, ctr.MongoCategoryColl, categoryID)
+}
+
+func categoryExists(ctx context.Context, categoryColl *mongo.Collection, categoryID string) (bool, error) {
+	categoryObjectID, err := primitive.ObjectIDFromHex(categoryID)
+	if err != nil {
+		return false, err
+	}
+	err = categoryColl.FindOne(ctx, bson.M{"_id": categoryObjectID}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// categorySlug is customSlug, or else the slug of name. Names without a letter or digit get the fallback slug,
+// numbered until no category other than categoryObjectID uses it
+func categorySlug(ctx context.Context, categoryColl *mongo.Collection, name string, customSlug string, categoryObjectID primitive.ObjectID) (string, error) {
+	if customSlug != "" {
+		return customSlug, nil
+	}
+	if slug := libs.Slugify(name); slug != "" {
+		return slug, nil
+	}
+
+	for i := 1; i <= maxNumberedSlugTries; i++ {
+		candidate := fallbackCategorySlug
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", fallbackCategorySlug, i)
+		}
+		filter := bson.M{
+			"_id":  bson.M{"$ne": categoryObjectID},
+			"slug": candidate,
+		}
+		count, err := categoryColl.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+
+	// the object ID suffix is unique by itself
+	return fmt.Sprintf("%s-%s", fallbackCategorySlug, categoryObjectID.Hex()[len(categoryObjectID.Hex())-8:]), nil
+}
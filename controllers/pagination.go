@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 50
+
+	// maxExactTotal bounds the documents counted for a page total, beyond which the total is an estimate
+	maxExactTotal = 10000
+)
+
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
+// countPageTotal counts the documents matching filter, and reports whether it gave up at maxExactTotal
+func countPageTotal(ctx context.Context, coll *mongo.Collection, filter bson.M) (int64, bool, error) {
+	total, err := coll.CountDocuments(ctx, filter, options.Count().SetLimit(maxExactTotal+1))
+	if err != nil {
+		return 0, false, err
+	}
+	if total > maxExactTotal {
+		return maxExactTotal, true, nil
+	}
+	return total, false, nil
+}
+
+// applyCursor narrows filter to the items after cursor in (createdAt, _id) descending order, or before it for a
+// backward cursor, and returns options fetching one item more than limit to tell whether another page follows
+func applyCursor(filter bson.M, cursor *libs.Cursor, limit int) (*options.FindOptions, error) {
+	order := -1
+	if cursor != nil && cursor.Backward {
+		order = 1
+	}
+	opts := options.Find().
+		SetLimit(int64(limit + 1)).
+		SetSort(bson.D{{Key: "createdAt", Value: order}, {Key: "_id", Value: order}})
+	if cursor == nil {
+		return opts, nil
+	}
+
+	cursorObjectID, err := primitive.ObjectIDFromHex(cursor.ID)
+	if err != nil {
+		return nil, err
+	}
+	operator := "$lt"
+	if cursor.Backward {
+		operator = "$gt"
+	}
+	filter["$or"] = bson.A{
+		bson.M{"createdAt": bson.M{operator: cursor.CreatedAt}},
+		bson.M{"createdAt": cursor.CreatedAt, "_id": bson.M{operator: cursorObjectID}},
+	}
+	return opts, nil
+}
+
+// cursorPage turns the items found with the options of applyCursor into a page, working out the cursors of the
+// neighbouring pages. afterStart tells whether a first page fetched without a cursor has items before it
+func cursorPage[T any](items []T, keyOf func(T) (primitive.DateTime, string), cursor *libs.Cursor, limit int, afterStart bool) ([]T, string, string) {
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+	if len(items) == 0 {
+		return items, "", ""
+	}
+
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	hasNext, hasPrev := hasMore, cursor != nil || afterStart
+	if backward {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	var next, prev string
+	if hasNext {
+		createdAt, id := keyOf(items[len(items)-1])
+		next = libs.EncodeCursor(libs.Cursor{CreatedAt: createdAt, ID: id})
+	}
+	if hasPrev {
+		createdAt, id := keyOf(items[0])
+		prev = libs.EncodeCursor(libs.Cursor{CreatedAt: createdAt, ID: id, Backward: true})
+	}
+	return items, next, prev
+}
+
+func blogCursorKey(blog models.Blog) (primitive.DateTime, string) {
+	return blog.CreatedAt, blog.ID
+}
+
+// pageURL returns the URL of the current request with params replaced, dropping those replaced by ""
+func pageURL(c *fiber.Ctx, params map[string]string) string {
+	values, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		values = url.Values{}
+	}
+	for key, value := range params {
+		if value == "" {
+			values.Del(key)
+		} else {
+			values.Set(key, value)
+		}
+	}
+	if encoded := values.Encode(); encoded != "" {
+		return c.Path() + "?" + encoded
+	}
+	return c.Path()
+}
+
+// newOffsetPage builds a page of a list paginated by the from offset
+func newOffsetPage[T any](c *fiber.Ctx, items []T, from int, limit int, total int64, totalIsEstimate bool) models.PaginatedResponse[T] {
+	fromParam := func(from int) map[string]string {
+		if from <= 0 {
+			return map[string]string{"from": ""}
+		}
+		return map[string]string{"from": strconv.Itoa(from)}
+	}
+
+	page := models.PaginatedResponse[T]{
+		Items:           items,
+		Total:           total,
+		TotalIsEstimate: totalIsEstimate,
+		Limit:           limit,
+		Links: models.PaginationLinks{
+			Self:  pageURL(c, nil),
+			First: pageURL(c, fromParam(0)),
+		},
+	}
+	if from > 0 {
+		page.Links.Prev = pageURL(c, fromParam(from-limit))
+	}
+	if int64(from+limit) < total {
+		page.Links.Next = pageURL(c, fromParam(from+limit))
+	}
+	if !totalIsEstimate && total > 0 {
+		page.Links.Last = pageURL(c, fromParam(int((total-1)/int64(limit))*limit))
+	}
+	return page
+}
+
+// newCursorPage builds a page of a list paginated by cursor, with the next and prev cursors given by cursorPage
+func newCursorPage[T any](c *fiber.Ctx, items []T, next string, prev string, limit int, total int64, totalIsEstimate bool) models.PaginatedResponse[T] {
+	page := models.PaginatedResponse[T]{
+		Items:           items,
+		Total:           total,
+		TotalIsEstimate: totalIsEstimate,
+		Limit:           limit,
+		Next:            next,
+		Prev:            prev,
+		Links: models.PaginationLinks{
+			Self:  pageURL(c, nil),
+			First: pageURL(c, map[string]string{"cursor": "", "from": ""}),
+		},
+	}
+	if next != "" {
+		page.Links.Next = pageURL(c, map[string]string{"cursor": next, "from": ""})
+	}
+	if prev != "" {
+		page.Links.Prev = pageURL(c, map[string]string{"cursor": prev, "from": ""})
+	}
+	return page
+}
+
+// respondPage sends page with its links repeated in an RFC 8288 Link header
+func respondPage[T any](c *fiber.Ctx, page models.PaginatedResponse[T]) error {
+	var links []string
+	for _, link := range []struct{ rel, url string }{
+		{"first", page.Links.First},
+		{"prev", page.Links.Prev},
+		{"next", page.Links.Next},
+		{"last", page.Links.Last},
+	} {
+		if link.url != "" {
+			links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, link.url, link.rel))
+		}
+	}
+	c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	return c.Status(fiber.StatusOK).JSON(page)
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type userController interface {
+	GetUser(c *fiber.Ctx) error
+	GetUserByHandle(c *fiber.Ctx) error
+	UpdateMe(c *fiber.Ctx) error
+	ChangePassword(c *fiber.Ctx) error
+	ChangeEmail(c *fiber.Ctx) error
+	ConfirmEmailChange(c *fiber.Ctx) error
+	EnrolTOTP(c *fiber.Ctx) error
+	ConfirmTOTP(c *fiber.Ctx) error
+	DisableTOTP(c *fiber.Ctx) error
+	GetMySessions(c *fiber.Ctx) error
+	DeleteMySession(c *fiber.Ctx) error
+	DeleteMySessions(c *fiber.Ctx) error
+	GetMyAccessTokens(c *fiber.Ctx) error
+	CreateAccessToken(c *fiber.Ctx) error
+	DeleteAccessToken(c *fiber.Ctx) error
+}
+
+type UserController struct {
+	MongoUserColl        *mongo.Collection
+	MongoBlogColl        *mongo.Collection
+	MongoAccessTokenColl *mongo.Collection
+}
+
+func NewUserControllers() userController {
+	database := connections.MongoClient.Database(configs.Env.MongoDatabase)
+
+	userColl := database.Collection("users")
+	connections.CreateMongoIndexes(
+		userColl,
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "handle", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"handle": bson.M{"$type": "string"}}),
+		},
+	)
+	migrateUserProfiles(userColl)
+
+	accessTokenColl := database.Collection("access_tokens")
+	connections.CreateMongoIndexes(
+		accessTokenColl,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		// tokens without expiresAt never expire
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	)
+
+	return &UserController{
+		MongoUserColl:        userColl,
+		MongoBlogColl:        database.Collection("blogs"),
+		MongoAccessTokenColl: accessTokenColl,
+	}
+}
+
+// @summary		Get user
+// @description	Get the public profile of a user
+// @id				GetUser
+// @tags			users
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"user's ID"
+// @success		200	{object}	models.UserProfile
+// @failure		404	{object}	models.ErrorResponse			"user not found"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/users/:id [get]
+func (ctr *UserController) GetUser(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.GetUserParams)
+	userObjectID, _ := primitive.ObjectIDFromHex(params.ID)
+
+	return ctr.respondUserProfile(context.TODO(), c, bson.M{"_id": userObjectID})
+}
+
+// @summary		Get user by handle
+// @description	Get the public profile of a user by their handle
+// @id				GetUserByHandle
+// @tags			users
+// @accept			json
+// @produce		json
+// @param			handle	path		string	true	"user's handle"
+// @success		200		{object}	models.UserProfile
+// @failure		404		{object}	models.ErrorResponse			"user not found"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/users/by-handle/:handle [get]
+func (ctr *UserController) GetUserByHandle(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.GetUserByHandleParams)
+
+	return ctr.respondUserProfile(context.TODO(), c, bson.M{"handle": params.Handle})
+}
+
+func (ctr *UserController) respondUserProfile(ctx context.Context, c *fiber.Ctx, filter bson.M) error {
+	var user *models.User
+	opts := options.FindOne().SetProjection(bson.M{"email": 0, "password": 0})
+	if err := ctr.MongoUserColl.FindOne(ctx, filter, opts).Decode(&user); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+				Message: "User not found",
+			})
+		}
+		return utils.NewAppError(err)
+	}
+
+	postFilter := bson.M{
+		"createdBy": user.ID,
+		"status":    models.BlogStatusPublished,
+		"deletedAt": nil,
+	}
+	postCount, err := ctr.MongoBlogColl.CountDocuments(ctx, postFilter)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.UserProfile{
+		ID:        user.ID,
+		Name:      user.Name,
+		Handle:    user.Handle,
+		Bio:       user.Bio,
+		AvatarURL: user.AvatarURL,
+		JoinedAt:  user.CreatedAt,
+		PostCount: postCount,
+	})
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"context"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxAccessTokens is how many personal access tokens a user may have at once
+const maxAccessTokens = 20
+
+// @summary		Get my access tokens
+// @description	Get the personal access tokens of the current user, newest first. The tokens themselves are not shown
+// @id				GetMyAccessTokens
+// @tags			me
+// @accept			json
+// @produce		json
+// @success		200	{array}		models.AccessToken
+// @failure		401	{object}	models.ErrorResponse	"unauthorized"
+// @failure		500	{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/me/tokens [get]
+func (ctr *UserController) GetMyAccessTokens(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := ctr.MongoAccessTokenColl.Find(ctx, bson.M{"userId": user.ID}, opts)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	accessTokens := []models.AccessToken{}
+	if err = cursor.All(ctx, &accessTokens); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(accessTokens)
+}
+
+// @summary		Create access token
+// @description	Create a personal access token for API clients to send as Authorization: Bearer. The token is only
+// @description	returned now. It can only be used on routes accepting one of its scopes
+// @id				CreateAccessToken
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			name			body		string		true	"name"	maxlength(50)
+// @param			scopes			body		[]string	true	"scopes, of blogs:read, blogs:write and categories:write"
+// @param			expiresInDays	body		int			false	"days until the token expires, never when omitted"	minimum(1)	maximum(365)
+// @success		201				{object}	models.CreatedAccessToken
+// @failure		401				{object}	models.ErrorResponse			"unauthorized"
+// @failure		403				{object}	models.ErrorResponse			"not allowed while impersonating"
+// @failure		409				{object}	models.ErrorResponse			"too many tokens"
+// @failure		422				{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500				{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/tokens [post]
+func (ctr *UserController) CreateAccessToken(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.CreateAccessTokenPayload)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	count, err := ctr.MongoAccessTokenColl.CountDocuments(ctx, bson.M{"userId": user.ID})
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if count >= maxAccessTokens {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "You have too many access tokens. Please delete one first",
+		})
+	}
+
+	token, err := libs.NewAccessToken()
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	scopes := make([]models.AccessTokenScope, 0, len(payload.Scopes))
+	for _, scope := range payload.Scopes {
+		scopes = append(scopes, models.AccessTokenScope(scope))
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	accessToken := models.AccessToken{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    user.ID,
+		Name:      payload.Name,
+		Hint:      libs.AccessTokenHint(token),
+		Scopes:    scopes,
+		CreatedAt: now,
+	}
+	if payload.ExpiresInDays > 0 {
+		expiresAt := primitive.NewDateTimeFromTime(time.Now().AddDate(0, 0, payload.ExpiresInDays))
+		accessToken.ExpiresAt = &expiresAt
+	}
+
+	accessTokenObjectID, _ := primitive.ObjectIDFromHex(accessToken.ID)
+	document := bson.D{
+		{Key: "_id", Value: accessTokenObjectID},
+		{Key: "userId", Value: accessToken.UserID},
+		{Key: "name", Value: accessToken.Name},
+		{Key: "tokenHash", Value: libs.HashToken(token)},
+		{Key: "hint", Value: accessToken.Hint},
+		{Key: "scopes", Value: accessToken.Scopes},
+		{Key: "expiresAt", Value: accessToken.ExpiresAt},
+		{Key: "createdAt", Value: accessToken.CreatedAt},
+	}
+	if _, err = ctr.MongoAccessTokenColl.InsertOne(ctx, document); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.CreatedAccessToken{
+		AccessToken: accessToken,
+		Token:       token,
+	})
+}
+
+// @summary		Delete access token
+// @description	Revoke a personal access token of the current user
+// @id				DeleteAccessToken
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"token's ID"
+// @success		200	{object}	models.SuccessResponse
+// @failure		401	{object}	models.ErrorResponse			"unauthorized"
+// @failure		404	{object}	models.ErrorResponse			"token not found"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/tokens/:id [delete]
+func (ctr *UserController) DeleteAccessToken(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.DeleteAccessTokenParams)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	accessTokenObjectID, err := primitive.ObjectIDFromHex(params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	result, err := ctr.MongoAccessTokenColl.DeleteOne(context.TODO(), bson.M{
+		"_id":    accessTokenObjectID,
+		"userId": user.ID,
+	})
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if result.DeletedCount == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "Access token not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Access token deleted",
+	})
+}
@@ -0,0 +1,326 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/libs"
+	"go_blogs/mailer"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailChangeTokenPurpose = "email_change"
+	emailChangeTokenTTL     = 24 * time.Hour
+)
+
+type emailChange struct {
+	UserID string `json:"userId"`
+	Email  string `json:"email"`
+}
+
+func (ctr *UserController) findUser(ctx context.Context, userID string) (*models.User, error) {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	var user *models.User
+	if err = ctr.MongoUserColl.FindOne(ctx, bson.M{"_id": userObjectID}).Decode(&user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (ctr *UserController) isEmailTaken(ctx context.Context, email string) (bool, error) {
+	count, err := ctr.MongoUserColl.CountDocuments(ctx, bson.M{"email": email}, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// @summary		Update me
+// @description	Update the profile of the current user. Omitted fields are left unchanged
+// @id				UpdateMe
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			name		body		string	false	"display name"	maxlength(50)
+// @param			handle		body		string	false	"public handle"	maxlength(30)
+// @param			bio			body		string	false	"bio"			maxlength(300)
+// @param			avatarUrl	body		string	false	"avatar URL"	maxlength(500)
+// @success		200			{object}	models.SuccessResponse
+// @failure		401			{object}	models.ErrorResponse			"unauthorized"
+// @failure		409			{object}	models.ErrorResponse			"handle has been used"
+// @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500			{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me [patch]
+func (ctr *UserController) UpdateMe(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.UpdateMePayload)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	userObjectID, err := primitive.ObjectIDFromHex(user.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	set := bson.M{}
+	if payload.Name != nil {
+		set["name"] = *payload.Name
+	}
+	if payload.Handle != nil {
+		var taken bool
+		if taken, err = isHandleTaken(ctx, ctr.MongoUserColl, *payload.Handle, userObjectID); err != nil {
+			return utils.NewAppError(err)
+		}
+		if taken {
+			return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+				Message: "Handle has been used. Please use another handle",
+			})
+		}
+		set["handle"] = *payload.Handle
+	}
+	if payload.Bio != nil {
+		set["bio"] = *payload.Bio
+	}
+	if payload.AvatarURL != nil {
+		set["avatarUrl"] = *payload.AvatarURL
+	}
+
+	if len(set) > 0 {
+		if _, err = ctr.MongoUserColl.UpdateByID(ctx, userObjectID, bson.M{"$set": set}); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+					Message: "Handle has been used. Please use another handle",
+				})
+			}
+			return utils.NewAppError(err)
+		}
+	}
+
+	if payload.Name != nil && *payload.Name != user.Name {
+		updated := *user
+		updated.Name = *payload.Name
+		if err = libs.UpdateUserSessionsData(ctx, updated); err != nil {
+			return utils.NewAppError(err)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Updated",
+	})
+}
+
+// @summary		Change password
+// @description	Change the password of the current user and log out every other session
+// @id				ChangePassword
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			currentPassword	body		string	true	"current password"
+// @param			newPassword		body		string	true	"new password"	minlength(6)	maxlength(32)
+// @success		200				{object}	models.SuccessResponse
+// @failure		400				{object}	models.ErrorResponse			"current password is invalid"
+// @failure		401				{object}	models.ErrorResponse			"unauthorized"
+// @failure		422				{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500				{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/password [post]
+func (ctr *UserController) ChangePassword(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.ChangePasswordPayload)
+	sessionUser := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	user, err := ctr.findUser(ctx, sessionUser.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.CurrentPassword)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Current password is invalid",
+		})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	userObjectID, _ := primitive.ObjectIDFromHex(user.ID)
+	update := bson.M{
+		"$set": bson.M{"password": string(hashedPassword)},
+	}
+	if _, err = ctr.MongoUserColl.UpdateByID(ctx, userObjectID, update); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	sessionID, err := libs.GetSessionID(c)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if err = libs.RevokeUserSessions(ctx, user.ID, sessionID); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Password changed",
+	})
+}
+
+// @summary		Change email
+// @description	Start changing the email of the current user by sending a confirmation link to the new address.
+// @description	The email changes once the link is confirmed
+// @id				ChangeEmail
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			email		body		string	true	"new email"
+// @param			password	body		string	true	"current password"
+// @success		202			{object}	models.SuccessResponse
+// @failure		400			{object}	models.ErrorResponse			"password is invalid"
+// @failure		401			{object}	models.ErrorResponse			"unauthorized"
+// @failure		409			{object}	models.ErrorResponse			"email has been used"
+// @failure		422			{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500			{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/email [post]
+func (ctr *UserController) ChangeEmail(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.ChangeEmailPayload)
+	sessionUser := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	user, err := ctr.findUser(ctx, sessionUser.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Password is invalid",
+		})
+	}
+
+	taken, err := ctr.isEmailTaken(ctx, payload.Email)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if taken {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Email has been used. Please use another email",
+		})
+	}
+
+	token, err := libs.NewToken()
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	change := emailChange{UserID: user.ID, Email: payload.Email}
+	if err = libs.StoreToken(ctx, emailChangeTokenPurpose, token, change, emailChangeTokenTTL); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	err = mailer.Default.Send(ctx, mailer.Message{
+		To:      payload.Email,
+		Subject: "Confirm your new email",
+		Text: fmt.Sprintf(
+			"Hi %s,\n\nOpen this link within 24 hours to use this address for your account:\n%s/confirm-email-change?token=%s\n\nIf you did not ask for this, ignore this email.\n",
+			user.Name, configs.Env.AppURL, token,
+		),
+	})
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(models.SuccessResponse{
+		Message: "Confirmation link has been sent to the new email",
+	})
+}
+
+// @summary		Confirm email change
+// @description	Change the email of a user with the token of a confirmation link sent by ChangeEmail
+// @id				ConfirmEmailChange
+// @tags			auth
+// @accept			json
+// @produce		json
+// @param			token	body		string	true	"confirmation token"
+// @success		200		{object}	models.SuccessResponse
+// @failure		400		{object}	models.ErrorResponse			"link is invalid or has expired"
+// @failure		409		{object}	models.ErrorResponse			"email has been used"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/auth/email-change/confirm [post]
+func (ctr *UserController) ConfirmEmailChange(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.ConfirmEmailChangePayload)
+
+	ctx := context.TODO()
+
+	var change emailChange
+	found, err := libs.ConsumeToken(ctx, emailChangeTokenPurpose, payload.Token, &change)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !found {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Link is invalid or has expired",
+		})
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(change.UserID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	var previous *models.User
+	// the new email is verified, as the link was sent to it. The unique email index refuses it when another user
+	// has taken it since the link was sent
+	update := bson.M{
+		"$set": bson.M{
+			"email":           change.Email,
+			"emailVerified":   true,
+			"emailVerifiedAt": time.Now(),
+		},
+	}
+	err = ctr.MongoUserColl.FindOneAndUpdate(ctx, bson.M{"_id": userObjectID}, update).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Link is invalid or has expired",
+		})
+	} else if mongo.IsDuplicateKeyError(err) {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Email has been used. Please use another email",
+		})
+	} else if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	updated := *previous
+	updated.Email = change.Email
+	updated.EmailVerified = true
+	if err = libs.UpdateUserSessionsData(ctx, models.NewUserSessionData(&updated)); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	// the previous address is told so that its owner notices a change they did not make
+	err = mailer.Default.Send(ctx, mailer.Message{
+		To:      previous.Email,
+		Subject: "Your email has been changed",
+		Text: fmt.Sprintf(
+			"Hi %s,\n\nThe email of your account has been changed to %s.\nIf you did not do this, please contact us right away.\n",
+			previous.Name, change.Email,
+		),
+	})
+	if err != nil {
+		fmt.Println("ConfirmEmailChange:", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Email changed",
+	})
+}
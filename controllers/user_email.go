@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"go_blogs/connections"
+	"go_blogs/models"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// userEmailIndexName is the name of the unique index on the email of users
+const userEmailIndexName = "email_1"
+
+// isDuplicateEmailError tells whether err is a write refused for an email another user has
+func isDuplicateEmailError(err error) bool {
+	return mongo.IsDuplicateKeyError(err) && strings.Contains(err.Error(), userEmailIndexName)
+}
+
+// migrateUserEmailIndex makes the email index of users unique. Emails several users have are reported,
+// and have to be changed before the index can be created
+func migrateUserEmailIndex(userColl *mongo.Collection) {
+	ctx := context.TODO()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$email",
+			"users": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"users": bson.M{"$gt": 1}}}},
+	}
+	cursor, err := userColl.Aggregate(ctx, pipeline)
+	if err != nil {
+		panic(err)
+	}
+	var duplicates []struct {
+		Email string `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &duplicates); err != nil {
+		panic(err)
+	}
+	if len(duplicates) > 0 {
+		for _, duplicate := range duplicates {
+			opts := options.Find().SetProjection(bson.M{"_id": 1})
+			cursor, err = userColl.Find(ctx, bson.M{"email": duplicate.Email}, opts)
+			if err != nil {
+				panic(err)
+			}
+			var users []models.User
+			if err = cursor.All(ctx, &users); err != nil {
+				panic(err)
+			}
+			userIDs := make([]string, len(users))
+			for i, user := range users {
+				userIDs[i] = user.ID
+			}
+			fmt.Printf("%s is the email of users %s\n", duplicate.Email, strings.Join(userIDs, ", "))
+		}
+		panic(fmt.Sprintf("%d emails are used by several users, change them so that emails can be unique", len(duplicates)))
+	}
+
+	// the email index used to be created without being unique, and cannot be changed in place
+	cursor, err = userColl.Indexes().List(ctx)
+	if err != nil {
+		panic(err)
+	}
+	var indexes []struct {
+		Name   string `bson:"name"`
+		Unique bool   `bson:"unique"`
+	}
+	if err = cursor.All(ctx, &indexes); err != nil {
+		panic(err)
+	}
+	for _, index := range indexes {
+		if index.Name == userEmailIndexName && !index.Unique {
+			if _, err = userColl.Indexes().DropOne(ctx, userEmailIndexName); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	connections.CreateMongoIndexes(
+		userColl,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetName(userEmailIndexName).SetUnique(true),
+		},
+	)
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	fallbackHandle         = "user"
+	maxHandleLength        = 30
+	maxNumberedHandleTries = 20
+)
+
+func isHandleTaken(ctx context.Context, userColl *mongo.Collection, handle string, userObjectID primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id":    bson.M{"$ne": userObjectID},
+		"handle": handle,
+	}
+	count, err := userColl.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	return count > 0, err
+}
+
+// generateUniqueHandle derives a handle from name and numbers it until no other user has it
+func generateUniqueHandle(ctx context.Context, userColl *mongo.Collection, name string, userObjectID primitive.ObjectID) (string, error) {
+	base := libs.Slugify(name)
+	// leave room for a number suffix
+	if len(base) > maxHandleLength-3 {
+		base = strings.TrimRight(base[:maxHandleLength-3], "-")
+	}
+	if base == "" {
+		base = fallbackHandle
+	}
+
+	for i := 1; i <= maxNumberedHandleTries; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		taken, err := isHandleTaken(ctx, userColl, candidate, userObjectID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+
+	// the object ID suffix is unique by itself
+	return fmt.Sprintf("%s-%s", fallbackHandle, userObjectID.Hex()[len(userObjectID.Hex())-8:]), nil
+}
+
+// migrateUserProfiles gives users registered before public profiles existed a handle and a joined date
+func migrateUserProfiles(userColl *mongo.Collection) {
+	ctx := context.TODO()
+
+	filter := bson.M{
+		"handle": bson.M{"$exists": false},
+	}
+	opts := options.Find().SetProjection(bson.M{"name": 1}).SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := userColl.Find(ctx, filter, opts)
+	if err != nil {
+		panic(err)
+	}
+
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		panic(err)
+	}
+
+	for _, user := range users {
+		userObjectID, _ := primitive.ObjectIDFromHex(user.ID)
+		handle, err := generateUniqueHandle(ctx, userColl, user.Name, userObjectID)
+		if err != nil {
+			panic(err)
+		}
+		update := bson.M{
+			"$set": bson.M{"handle": handle},
+			"$min": bson.M{"createdAt": userObjectID.Timestamp()},
+		}
+		if _, err = userColl.UpdateByID(ctx, userObjectID, update); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @summary		Get my sessions
+// @description	Get the devices the current user is logged in on, most recently seen first
+// @id				GetMySessions
+// @tags			me
+// @accept			json
+// @produce		json
+// @success		200	{array}		models.UserSession
+// @failure		401	{object}	models.ErrorResponse	"unauthorized"
+// @failure		500	{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/me/sessions [get]
+func (ctr *UserController) GetMySessions(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.UserSessionData)
+
+	sessionID, err := libs.GetSessionID(c)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	sessions, err := libs.GetUserSessions(context.TODO(), user.ID, sessionID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(sessions)
+}
+
+// @summary		Delete my session
+// @description	Log the current user out of one of their sessions
+// @id				DeleteMySession
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			id	path		string	true	"session's id"
+// @success		200	{object}	models.SuccessResponse
+// @failure		401	{object}	models.ErrorResponse			"unauthorized"
+// @failure		404	{object}	models.ErrorResponse			"session not found"
+// @failure		422	{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500	{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/sessions/:id [delete]
+func (ctr *UserController) DeleteMySession(c *fiber.Ctx) error {
+	params := c.Locals("params").(*validators.DeleteMySessionParams)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	revoked, err := libs.RevokeUserSession(context.TODO(), user.ID, params.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !revoked {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "Session not found",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Logged out",
+	})
+}
+
+// @summary		Delete my sessions
+// @description	Log the current user out everywhere, or everywhere else with exceptCurrent
+// @id				DeleteMySessions
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			exceptCurrent	query	bool	false	"keep the session of this request"
+// @success		204
+// @failure		401	{object}	models.ErrorResponse	"unauthorized"
+// @failure		500	{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/me/sessions [delete]
+func (ctr *UserController) DeleteMySessions(c *fiber.Ctx) error {
+	query := c.Locals("query").(*validators.DeleteMySessionsQuery)
+	user := c.Locals("user").(*models.UserSessionData)
+
+	sessionID, err := libs.GetSessionID(c)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if err = libs.RevokeUserSessions(context.TODO(), user.ID, sessionID); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	if !query.ExceptCurrent {
+		if err = libs.DestroyUserSessionData(c); err != nil {
+			return utils.NewAppError(err)
+		}
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
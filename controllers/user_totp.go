@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"context"
+	"go_blogs/configs"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"go_blogs/utils"
+	"go_blogs/validators"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// verifySecondFactor checks a TOTP code or a recovery code of user and uses it up, so that neither works twice
+func verifySecondFactor(ctx context.Context, userColl *mongo.Collection, user *models.User, code string, recoveryCode string) (bool, error) {
+	if user.TOTP == nil {
+		return false, nil
+	}
+	userObjectID, err := primitive.ObjectIDFromHex(user.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if recoveryCode != "" {
+		filter := bson.M{
+			"_id":                userObjectID,
+			"totp.recoveryCodes": libs.HashRecoveryCode(recoveryCode),
+		}
+		update := bson.M{
+			"$pull": bson.M{"totp.recoveryCodes": libs.HashRecoveryCode(recoveryCode)},
+		}
+		result, err := userColl.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return false, err
+		}
+		return result.ModifiedCount > 0, nil
+	}
+
+	secret, err := libs.OpenSecret(user.TOTP.Secret)
+	if err != nil {
+		return false, err
+	}
+	step, ok := libs.MatchTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	// a code seen by someone looking over the user's shoulder must not log them in as well
+	filter := bson.M{
+		"_id":               userObjectID,
+		"totp.lastUsedStep": bson.M{"$lt": step},
+	}
+	update := bson.M{
+		"$set": bson.M{"totp.lastUsedStep": step},
+	}
+	result, err := userColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// @summary		Enrol TOTP
+// @description	Start enabling two-factor authentication with an authenticator app. Scan the QR code or enter
+// @description	the secret, then confirm with a code from the app. Enrolling again replaces an unconfirmed secret
+// @id				EnrolTOTP
+// @tags			me
+// @accept			json
+// @produce		json
+// @success		200	{object}	models.TOTPEnrolment
+// @failure		401	{object}	models.ErrorResponse	"unauthorized"
+// @failure		409	{object}	models.ErrorResponse	"two-factor authentication is already enabled"
+// @failure		500	{object}	models.ErrorResponse	"something went wrong"
+// @router			/api/me/2fa/totp [post]
+func (ctr *UserController) EnrolTOTP(c *fiber.Ctx) error {
+	sessionUser := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	user, err := ctr.findUser(ctx, sessionUser.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if user.TwoFactorEnabled() {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Two-factor authentication is already enabled",
+		})
+	}
+
+	key, err := libs.NewTOTPKey(configs.Env.TOTPIssuer, user.Email)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	qrCode, err := libs.TOTPQRCode(key)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	sealedSecret, err := libs.SealSecret(key.Secret())
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+
+	userObjectID, _ := primitive.ObjectIDFromHex(user.ID)
+	// the enabled filter keeps a concurrent confirmation from being overwritten
+	filter := bson.M{
+		"_id":          userObjectID,
+		"totp.enabled": bson.M{"$ne": true},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"totp": models.UserTOTP{
+				Secret:        sealedSecret,
+				RecoveryCodes: []string{},
+			},
+		},
+	}
+	result, err := ctr.MongoUserColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if result.MatchedCount == 0 {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Two-factor authentication is already enabled",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.TOTPEnrolment{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: qrCode,
+	})
+}
+
+// @summary		Confirm TOTP
+// @description	Enable two-factor authentication with a code from the authenticator app enrolled by EnrolTOTP.
+// @description	The recovery codes are only shown here; each logs in once without the app
+// @id				ConfirmTOTP
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			code	body		string	true	"6-digit code from the authenticator app"
+// @success		200		{object}	models.RecoveryCodes
+// @failure		400		{object}	models.ErrorResponse			"code is invalid"
+// @failure		401		{object}	models.ErrorResponse			"unauthorized"
+// @failure		404		{object}	models.ErrorResponse			"TOTP is not enrolled"
+// @failure		409		{object}	models.ErrorResponse			"two-factor authentication is already enabled"
+// @failure		422		{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500		{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/2fa/totp/confirm [post]
+func (ctr *UserController) ConfirmTOTP(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.ConfirmTOTPPayload)
+	sessionUser := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	user, err := ctr.findUser(ctx, sessionUser.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if user.TOTP == nil {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "TOTP is not enrolled",
+		})
+	}
+	if user.TOTP.Enabled {
+		return c.Status(fiber.StatusConflict).JSON(models.ErrorResponse{
+			Message: "Two-factor authentication is already enabled",
+		})
+	}
+
+	verified, err := verifySecondFactor(ctx, ctr.MongoUserColl, user, payload.Code, "")
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !verified {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Code is invalid",
+		})
+	}
+
+	recoveryCodes, err := libs.NewRecoveryCodes()
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	hashedRecoveryCodes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashedRecoveryCodes[i] = libs.HashRecoveryCode(code)
+	}
+
+	userObjectID, _ := primitive.ObjectIDFromHex(user.ID)
+	filter := bson.M{
+		"_id":         userObjectID,
+		"totp.secret": user.TOTP.Secret,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"totp.enabled":       true,
+			"totp.enabledAt":     time.Now(),
+			"totp.recoveryCodes": hashedRecoveryCodes,
+		},
+	}
+	result, err := ctr.MongoUserColl.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	// the user enrolled again from another tab after loading the secret confirmed here
+	if result.MatchedCount == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Code is invalid",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.RecoveryCodes{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// @summary		Disable TOTP
+// @description	Disable two-factor authentication. Takes the password and a code from the authenticator app or a recovery code
+// @id				DisableTOTP
+// @tags			me
+// @accept			json
+// @produce		json
+// @param			password		body		string	true	"current password"
+// @param			code			body		string	false	"6-digit code from the authenticator app"
+// @param			recoveryCode	body		string	false	"recovery code, when the app is lost"
+// @success		200				{object}	models.SuccessResponse
+// @failure		400				{object}	models.ErrorResponse			"password or code is invalid"
+// @failure		401				{object}	models.ErrorResponse			"unauthorized"
+// @failure		404				{object}	models.ErrorResponse			"two-factor authentication is not enabled"
+// @failure		422				{array}		models.ValidationErrorResponse	"validation failed"
+// @failure		500				{object}	models.ErrorResponse			"something went wrong"
+// @router			/api/me/2fa/totp [delete]
+func (ctr *UserController) DisableTOTP(c *fiber.Ctx) error {
+	payload := c.Locals("payload").(*validators.DisableTOTPPayload)
+	sessionUser := c.Locals("user").(*models.UserSessionData)
+
+	ctx := context.TODO()
+
+	user, err := ctr.findUser(ctx, sessionUser.ID)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !user.TwoFactorEnabled() {
+		return c.Status(fiber.StatusNotFound).JSON(models.ErrorResponse{
+			Message: "Two-factor authentication is not enabled",
+		})
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password)); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Password or code is invalid",
+		})
+	}
+	verified, err := verifySecondFactor(ctx, ctr.MongoUserColl, user, payload.Code, payload.RecoveryCode)
+	if err != nil {
+		return utils.NewAppError(err)
+	}
+	if !verified {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "Password or code is invalid",
+		})
+	}
+
+	userObjectID, _ := primitive.ObjectIDFromHex(user.ID)
+	if _, err = ctr.MongoUserColl.UpdateByID(ctx, userObjectID, bson.M{"$unset": bson.M{"totp": ""}}); err != nil {
+		return utils.NewAppError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.SuccessResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
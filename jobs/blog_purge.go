@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const blogPurgeBatchSize = 100
+
+// BlogPurgeJob permanently removes blogs, and their revisions, that have been in the trash longer than Retention
+type BlogPurgeJob struct {
+	MongoBlogColl         *mongo.Collection
+	MongoBlogRevisionColl *mongo.Collection
+	Retention             time.Duration
+}
+
+func NewBlogPurgeJob(blogColl *mongo.Collection, blogRevisionColl *mongo.Collection, retention time.Duration) *BlogPurgeJob {
+	return &BlogPurgeJob{
+		MongoBlogColl:         blogColl,
+		MongoBlogRevisionColl: blogRevisionColl,
+		Retention:             retention,
+	}
+}
+
+func (j *BlogPurgeJob) Name() string {
+	return "blog_purge"
+}
+
+func (j *BlogPurgeJob) Run(ctx context.Context, now time.Time) error {
+	filter := bson.M{
+		"deletedAt": bson.M{"$lte": now.Add(-j.Retention)},
+	}
+	opts := options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(blogPurgeBatchSize)
+	cursor, err := j.MongoBlogColl.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+
+	var blogs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &blogs); err != nil {
+		return err
+	}
+	if len(blogs) == 0 {
+		return nil
+	}
+
+	blogObjectIDs := make([]primitive.ObjectID, len(blogs))
+	blogIDs := make([]string, len(blogs))
+	for i, blog := range blogs {
+		blogObjectIDs[i] = blog.ID
+		blogIDs[i] = blog.ID.Hex()
+	}
+
+	// blogs restored since they were selected are kept by repeating the retention condition
+	filter["_id"] = bson.M{"$in": blogObjectIDs}
+	result, err := j.MongoBlogColl.DeleteMany(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	revisionFilter := bson.M{
+		"blogId": bson.M{"$in": blogIDs},
+	}
+	if _, err = j.MongoBlogRevisionColl.DeleteMany(ctx, revisionFilter); err != nil {
+		return err
+	}
+
+	fmt.Printf("BlogPurgeJob: purged %d\n", result.DeletedCount)
+	return nil
+}
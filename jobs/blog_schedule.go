@@ -0,0 +1,114 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"go_blogs/models"
+	"go_blogs/search"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const blogScheduleBatchSize = 100
+
+// BlogScheduleJob publishes blogs whose publishAt has passed and archives blogs whose unpublishAt has passed
+type BlogScheduleJob struct {
+	MongoBlogColl *mongo.Collection
+	SearchIndex   *search.BlogIndex
+}
+
+func NewBlogScheduleJob(blogColl *mongo.Collection, searchIndex *search.BlogIndex) *BlogScheduleJob {
+	return &BlogScheduleJob{
+		MongoBlogColl: blogColl,
+		SearchIndex:   searchIndex,
+	}
+}
+
+func (j *BlogScheduleJob) Name() string {
+	return "blog_schedule"
+}
+
+func (j *BlogScheduleJob) Run(ctx context.Context, now time.Time) error {
+	published, err := j.flip(
+		ctx,
+		bson.M{
+			"status":    bson.M{"$in": []models.BlogStatus{models.BlogStatusDraft, models.BlogStatusInReview}},
+			"publishAt": bson.M{"$lte": now},
+			"deletedAt": nil,
+		},
+		bson.M{
+			"status":      models.BlogStatusPublished,
+			"publishedAt": "$publishAt",
+		},
+		"publishAt",
+	)
+	if err != nil {
+		return err
+	}
+
+	archived, err := j.flip(
+		ctx,
+		bson.M{
+			"status":      models.BlogStatusPublished,
+			"unpublishAt": bson.M{"$lte": now},
+			"deletedAt":   nil,
+		},
+		bson.M{
+			"status":     models.BlogStatusArchived,
+			"archivedAt": "$unpublishAt",
+		},
+		"unpublishAt",
+	)
+	if err != nil {
+		return err
+	}
+
+	if published > 0 || archived > 0 {
+		fmt.Printf("BlogScheduleJob: published %d, archived %d\n", published, archived)
+	}
+	return nil
+}
+
+// flip applies set to every blog matching filter and clears the schedule field that triggered it.
+// Each update repeats the filter so a blog changed concurrently by its author is left untouched
+func (j *BlogScheduleJob) flip(ctx context.Context, filter bson.M, set bson.M, scheduleField string) (int64, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(blogScheduleBatchSize)
+	cursor, err := j.MongoBlogColl.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var blogs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &blogs); err != nil {
+		return 0, err
+	}
+
+	set["version"] = bson.M{"$add": bson.A{"$version", 1}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: set}},
+		{{Key: "$unset", Value: scheduleField}},
+	}
+
+	var count int64
+	for _, blog := range blogs {
+		blogFilter := bson.M{"_id": blog.ID}
+		for key, value := range filter {
+			blogFilter[key] = value
+		}
+		result, updateErr := j.MongoBlogColl.UpdateOne(ctx, blogFilter, update)
+		if updateErr != nil {
+			return count, updateErr
+		}
+		if result.ModifiedCount > 0 {
+			j.SearchIndex.Notify(ctx, blog.ID.Hex())
+		}
+		count += result.ModifiedCount
+	}
+	return count, nil
+}
@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"context"
+	"go_blogs/models"
+	"go_blogs/search"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBlogScheduleJobFlipsOnlyDueBlogs(t *testing.T) {
+	ctx := context.TODO()
+	database := newTestDatabase(t)
+	rds, server := newTestRedis(t)
+	blogColl := database.Collection("blogs")
+	searchIndex := search.NewBlogIndex(search.NewMemoryBackend(), blogColl, rds)
+
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	interval := 30 * time.Second
+	scheduler := NewScheduler(rds, clock, interval, NewBlogScheduleJob(blogColl, searchIndex))
+
+	// tick moves both the clock and the scheduler lock on to the next interval
+	tick := func(d time.Duration) {
+		clock.Advance(d)
+		server.FastForward(interval)
+		scheduler.RunOnce(ctx)
+	}
+
+	insertBlog := func(status models.BlogStatus, fields bson.D) primitive.ObjectID {
+		id := primitive.NewObjectID()
+		document := append(bson.D{
+			{Key: "_id", Value: id},
+			{Key: "title", Value: "Blog " + id.Hex()},
+			{Key: "content", Value: "content"},
+			{Key: "status", Value: status},
+			{Key: "version", Value: 1},
+			{Key: "createdBy", Value: "author"},
+			{Key: "createdAt", Value: start.Add(-time.Hour)},
+		}, fields...)
+		if _, err := blogColl.InsertOne(ctx, document); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	findBlog := func(id primitive.ObjectID) models.Blog {
+		var blog models.Blog
+		if err := blogColl.FindOne(ctx, bson.M{"_id": id}).Decode(&blog); err != nil {
+			t.Fatal(err)
+		}
+		return blog
+	}
+
+	soon := start.Add(time.Minute)
+	later := start.Add(2 * time.Hour)
+	dueDraft := insertBlog(models.BlogStatusDraft, bson.D{{Key: "publishAt", Value: soon}})
+	dueInReview := insertBlog(models.BlogStatusInReview, bson.D{{Key: "publishAt", Value: soon}})
+	laterDraft := insertBlog(models.BlogStatusDraft, bson.D{{Key: "publishAt", Value: later}})
+	unscheduledDraft := insertBlog(models.BlogStatusDraft, nil)
+	deletedDraft := insertBlog(models.BlogStatusDraft, bson.D{
+		{Key: "publishAt", Value: soon},
+		{Key: "deletedAt", Value: start},
+	})
+	expiring := insertBlog(models.BlogStatusPublished, bson.D{
+		{Key: "publishedAt", Value: start.Add(-time.Hour)},
+		{Key: "unpublishAt", Value: later},
+	})
+
+	scheduler.RunOnce(ctx)
+	for _, id := range []primitive.ObjectID{dueDraft, dueInReview, laterDraft} {
+		if blog := findBlog(id); blog.Status == models.BlogStatusPublished {
+			t.Fatalf("blog %s was published before its publishAt", id.Hex())
+		}
+	}
+
+	tick(5 * time.Minute)
+	for _, id := range []primitive.ObjectID{dueDraft, dueInReview} {
+		blog := findBlog(id)
+		if blog.Status != models.BlogStatusPublished {
+			t.Errorf("due blog %s is %s, want published", id.Hex(), blog.Status)
+		}
+		if blog.PublishedAt == nil || !blog.PublishedAt.Time().Equal(soon) {
+			t.Errorf("due blog %s was published at %v, want its publishAt %s", id.Hex(), blog.PublishedAt, soon)
+		}
+		if blog.PublishAt != nil {
+			t.Errorf("due blog %s still has publishAt", id.Hex())
+		}
+		if blog.Version != 2 {
+			t.Errorf("due blog %s has version %d, want 2", id.Hex(), blog.Version)
+		}
+	}
+	for _, id := range []primitive.ObjectID{laterDraft, unscheduledDraft, deletedDraft} {
+		if blog := findBlog(id); blog.Status != models.BlogStatusDraft || blog.Version != 1 {
+			t.Errorf("blog %s is %s at version %d, want an untouched draft", id.Hex(), blog.Status, blog.Version)
+		}
+	}
+	if blog := findBlog(expiring); blog.Status != models.BlogStatusPublished {
+		t.Errorf("blog %s is %s before its unpublishAt, want published", expiring.Hex(), blog.Status)
+	}
+
+	result, err := searchIndex.Backend.Search(ctx, search.Query{Text: "blog", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 {
+		t.Errorf("search finds %d blogs, want the 2 published by the job", result.Total)
+	}
+
+	tick(2 * time.Hour)
+	if blog := findBlog(laterDraft); blog.Status != models.BlogStatusPublished {
+		t.Errorf("blog %s is %s once its publishAt passed, want published", laterDraft.Hex(), blog.Status)
+	}
+	if blog := findBlog(expiring); blog.Status != models.BlogStatusArchived || blog.UnpublishAt != nil {
+		t.Errorf("blog %s is %s once its unpublishAt passed, want archived", expiring.Hex(), blog.Status)
+	}
+	if blog := findBlog(unscheduledDraft); blog.Status != models.BlogStatusDraft {
+		t.Errorf("unscheduled blog %s is %s, want draft", unscheduledDraft.Hex(), blog.Status)
+	}
+}
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// fakeClock is a libs.Clock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestRedis returns a client of an in-memory Redis, whose clock only moves with FastForward
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	rds := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { rds.Close() })
+	return rds, server
+}
+
+// newTestDatabase returns an empty database on the Mongo of TEST_MONGO_URI, dropped once the test ends.
+// Tests that need Mongo are skipped when TEST_MONGO_URI is not set
+func newTestDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	uri := os.Getenv("TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGO_URI is not set, see Testing in README.md")
+	}
+
+	ctx := context.TODO()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetTimeout(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	database := client.Database("go_blogs_test_" + uuid.NewString()[:8])
+	t.Cleanup(func() {
+		database.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+	return database
+}
@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"go_blogs/libs"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Job is a unit of background work run periodically by the Scheduler
+type Job interface {
+	Name() string
+	Run(ctx context.Context, now time.Time) error
+}
+
+type Scheduler struct {
+	Redis      *redis.Client
+	Clock      libs.Clock
+	Interval   time.Duration
+	Jobs       []Job
+	instanceID string
+}
+
+func NewScheduler(rds *redis.Client, clock libs.Clock, interval time.Duration, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		Redis:      rds,
+		Clock:      clock,
+		Interval:   interval,
+		Jobs:       jobs,
+		instanceID: uuid.NewString(),
+	}
+}
+
+// Start runs every job once per interval until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	s.RunOnce(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce runs every job whose lock this instance can acquire. The lock is left to expire
+// so that each job runs on at most one replica per interval
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	for _, job := range s.Jobs {
+		acquired, err := s.acquireLock(ctx, job)
+		if err != nil {
+			fmt.Println("Scheduler:", job.Name(), err.Error())
+			continue
+		}
+		if !acquired {
+			continue
+		}
+
+		if err = job.Run(ctx, s.Clock.Now()); err != nil {
+			fmt.Println("Scheduler:", job.Name(), err.Error())
+		}
+	}
+}
+
+func (s *Scheduler) acquireLock(ctx context.Context, job Job) (bool, error) {
+	key := fmt.Sprintf("lock:job:%s", job.Name())
+	ttl := s.Interval * 9 / 10
+	return s.Redis.SetNX(ctx, key, s.instanceID, ttl).Result()
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type countingJob struct {
+	runs []time.Time
+}
+
+func (j *countingJob) Name() string {
+	return "counting"
+}
+
+func (j *countingJob) Run(_ context.Context, now time.Time) error {
+	j.runs = append(j.runs, now)
+	return nil
+}
+
+func TestSchedulerRunsJobOnOneInstancePerTick(t *testing.T) {
+	rds, server := newTestRedis(t)
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)}
+	interval := 30 * time.Second
+	job := &countingJob{}
+
+	first := NewScheduler(rds, clock, interval, job)
+	second := NewScheduler(rds, clock, interval, job)
+
+	first.RunOnce(context.TODO())
+	second.RunOnce(context.TODO())
+	if len(job.runs) != 1 {
+		t.Fatalf("job ran %d times in one tick, want 1", len(job.runs))
+	}
+	if !job.runs[0].Equal(clock.Now()) {
+		t.Errorf("job ran at %s, want the clock time %s", job.runs[0], clock.Now())
+	}
+
+	// the lock outlives most of the interval, so a replica ticking late in the same interval still skips the job
+	server.FastForward(interval / 2)
+	second.RunOnce(context.TODO())
+	if len(job.runs) != 1 {
+		t.Fatalf("job ran %d times within one interval, want 1", len(job.runs))
+	}
+
+	server.FastForward(interval / 2)
+	clock.Advance(interval)
+	second.RunOnce(context.TODO())
+	if len(job.runs) != 2 {
+		t.Fatalf("job ran %d times after the next tick, want 2", len(job.runs))
+	}
+	if !job.runs[1].Equal(clock.Now()) {
+		t.Errorf("job ran at %s, want the clock time %s", job.runs[1], clock.Now())
+	}
+}
@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"go_blogs/libs"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	sessionCleanupBatchSize = 100
+	sessionCleanupCursorKey = "session_cleanup:cursor"
+)
+
+// SessionCleanupJob drops expired sessions from the per-user session indexes, a batch of indexes per run,
+// so that indexes of users who never list their sessions do not keep growing until they expire themselves
+type SessionCleanupJob struct {
+	Redis *redis.Client
+}
+
+func NewSessionCleanupJob(rds *redis.Client) *SessionCleanupJob {
+	return &SessionCleanupJob{
+		Redis: rds,
+	}
+}
+
+func (j *SessionCleanupJob) Name() string {
+	return "session_cleanup"
+}
+
+func (j *SessionCleanupJob) Run(ctx context.Context, _ time.Time) error {
+	cursor, err := j.Redis.Get(ctx, sessionCleanupCursorKey).Uint64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	next, err := libs.PruneUserSessionIndexes(ctx, cursor, sessionCleanupBatchSize)
+	if err != nil {
+		return err
+	}
+
+	return j.Redis.Set(ctx, sessionCleanupCursorKey, next, 0).Err()
+}
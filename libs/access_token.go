@@ -0,0 +1,128 @@
+package libs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/models"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// accessTokenTouchInterval is how stale the last-used time of a personal access token may get
+const accessTokenTouchInterval = time.Minute
+
+// ErrAccessTokenScope is returned when the personal access token of a request does not grant the scope it needs
+var ErrAccessTokenScope = errors.New("access token does not have the scope")
+
+// AccessTokenPrefix starts every personal access token, so that leaked tokens are easy to spot
+const AccessTokenPrefix = "gb_pat_"
+
+// NewAccessToken returns a random personal access token
+func NewAccessToken() (string, error) {
+	token, err := NewToken()
+	if err != nil {
+		return "", err
+	}
+	return AccessTokenPrefix + token, nil
+}
+
+// AccessTokenHint returns the part of token shown to its user to tell their tokens apart
+func AccessTokenHint(token string) string {
+	return AccessTokenPrefix + "..." + token[len(token)-4:]
+}
+
+// GetBearerToken returns the token of the Authorization: Bearer header of the request, if it has one
+func GetBearerToken(c *fiber.Ctx) (string, bool) {
+	scheme, token, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// HasAccessToken reports whether the request comes with a personal access token. Other bearer tokens are access
+// tokens of AUTH_MODE jwt
+func HasAccessToken(c *fiber.Ctx) bool {
+	token, ok := GetBearerToken(c)
+	return ok && strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// GetRequestUserData returns the data of the user of the request, from its personal access token, which must grant
+// scope, or else like GetUserSessionData. Tokens that are not live return mongo.ErrNoDocuments
+func GetRequestUserData(c *fiber.Ctx, scope models.AccessTokenScope) (*models.UserSessionData, error) {
+	if !HasAccessToken(c) {
+		return GetUserSessionData(c)
+	}
+
+	token, _ := GetBearerToken(c)
+	userData, err := findAccessTokenUser(context.TODO(), token)
+	if err != nil {
+		return nil, err
+	}
+	if !userData.AccessToken.Allows(scope) {
+		return nil, ErrAccessTokenScope
+	}
+	return userData, nil
+}
+
+// findAccessTokenUser returns the data of the user of token, with what the token grants.
+// It returns mongo.ErrNoDocuments when token is not a live token of a user who may log in
+func findAccessTokenUser(ctx context.Context, token string) (*models.UserSessionData, error) {
+	database := connections.MongoClient.Database(configs.Env.MongoDatabase)
+	accessTokenColl := database.Collection("access_tokens")
+
+	// expired tokens are removed by a TTL index, which may lag behind by a minute
+	now := time.Now()
+	var accessToken *models.AccessToken
+	filter := bson.M{
+		"tokenHash": HashToken(token),
+		"$or": bson.A{
+			bson.M{"expiresAt": nil},
+			bson.M{"expiresAt": bson.M{"$gt": now}},
+		},
+	}
+	if err := accessTokenColl.FindOne(ctx, filter).Decode(&accessToken); err != nil {
+		return nil, err
+	}
+
+	userObjectID, err := primitive.ObjectIDFromHex(accessToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var user *models.User
+	if err = database.Collection("users").FindOne(ctx, bson.M{"_id": userObjectID}).Decode(&user); err != nil {
+		return nil, err
+	}
+	if user.LoginBlockedReason() != "" {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	// the last-used time is informational, so failing to record it does not fail the request
+	accessTokenObjectID, _ := primitive.ObjectIDFromHex(accessToken.ID)
+	touchFilter := bson.M{
+		"_id": accessTokenObjectID,
+		"$or": bson.A{
+			bson.M{"lastUsedAt": nil},
+			bson.M{"lastUsedAt": bson.M{"$lt": now.Add(-accessTokenTouchInterval)}},
+		},
+	}
+	if _, err = accessTokenColl.UpdateOne(ctx, touchFilter, bson.M{"$set": bson.M{"lastUsedAt": now}}); err != nil {
+		fmt.Println("findAccessTokenUser:", err.Error())
+	}
+
+	userData := models.NewUserSessionData(user)
+	userData.AccessToken = &models.AccessTokenGrant{
+		ID:     accessToken.ID,
+		Scopes: accessToken.Scopes,
+	}
+	return &userData, nil
+}
@@ -0,0 +1,14 @@
+package libs
+
+import "time"
+
+// Clock abstracts the current time so time-based logic can be driven by tests
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,59 @@
+package libs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go_blogs/configs"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a list sorted by createdAt then _id, both descending.
+// Backward cursors page towards newer items
+type Cursor struct {
+	CreatedAt primitive.DateTime `json:"t"`
+	ID        string             `json:"id"`
+	Backward  bool               `json:"b,omitempty"`
+}
+
+// EncodeCursor serializes cursor into an opaque token signed with the cursor secret, so clients cannot forge positions
+func EncodeCursor(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signCursor(encoded))
+}
+
+// DecodeCursor verifies and parses a token made by EncodeCursor
+func DecodeCursor(token string) (Cursor, error) {
+	var cursor Cursor
+
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return cursor, ErrInvalidCursor
+	}
+	decodedSignature, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decodedSignature, signCursor(encoded)) {
+		return cursor, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err = json.Unmarshal(payload, &cursor); err != nil || !primitive.IsValidObjectID(cursor.ID) {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func signCursor(encoded string) []byte {
+	mac := hmac.New(sha256.New, []byte(configs.Env.CursorSecret))
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
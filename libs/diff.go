@@ -0,0 +1,186 @@
+package libs
+
+import (
+	"go_blogs/models"
+	"strings"
+)
+
+// diffWorkLimit bounds the diagonals DiffLines searches. Past it, the lines left to compare are shown as
+// deleted and inserted rather than searched for the shortest diff, so that no pair of texts can tie up a request
+const diffWorkLimit = 20_000_000
+
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// lineDiffer holds the state of one DiffLines call. Lines are compared by ID, so that equal lines cost an int
+// comparison however long they are
+type lineDiffer struct {
+	a, b     []string
+	aIDs     []int
+	bIDs     []int
+	lines    []models.DiffLine
+	forward  []int
+	backward []int
+	work     int
+}
+
+// DiffLines computes a line-level diff between two texts using the linear space variant of Myers' O(ND)
+// algorithm. It finds the middle snake of the shortest edit path and recurses on either side of it, so memory
+// stays O(N+M) however far apart the texts are
+func DiffLines(oldText string, newText string) []models.DiffLine {
+	a, b := splitLines(oldText), splitLines(newText)
+
+	ids := make(map[string]int)
+	lineIDs := func(lines []string) []int {
+		result := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			result[i] = id
+		}
+		return result
+	}
+
+	size := len(a) + len(b) + 3
+	d := &lineDiffer{
+		a:        a,
+		b:        b,
+		aIDs:     lineIDs(a),
+		bIDs:     lineIDs(b),
+		lines:    make([]models.DiffLine, 0, max(len(a), len(b))),
+		forward:  make([]int, size),
+		backward: make([]int, size),
+	}
+	d.diff(0, len(a), 0, len(b))
+	return d.lines
+}
+
+// diff appends the diff of a[aLo:aHi] and b[bLo:bHi] to d.lines
+func (d *lineDiffer) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.aIDs[aLo] == d.bIDs[bLo] {
+		d.appendLine(models.DiffOpEqual, aLo, bLo)
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.aIDs[aHi-suffix-1] == d.bIDs[bHi-suffix-1] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aHi:
+		for y := bLo; y < bHi; y++ {
+			d.appendLine(models.DiffOpInsert, aLo, y)
+		}
+	case bLo == bHi:
+		for x := aLo; x < aHi; x++ {
+			d.appendLine(models.DiffOpDelete, x, bLo)
+		}
+	default:
+		x, y := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.diff(aLo, x, bLo, y)
+		d.diff(x, aHi, y, bHi)
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.appendLine(models.DiffOpEqual, aHi+i, bHi+i)
+	}
+}
+
+// middleSnake searches the shortest edit path of a[aLo:aHi] and b[bLo:bHi] from both ends at once and returns
+// the point where the two searches meet. Both ranges are non-empty and differ in their first and last lines
+func (d *lineDiffer) middleSnake(aLo, aHi, bLo, bHi int) (int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	forward, backward := d.forward[:2*maxD+2], d.backward[:2*maxD+2]
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	delta := n - m
+	// when delta is odd the forward search is the one to detect the overlap, otherwise the backward one
+	odd := delta%2 != 0
+	// diagonals whose search has run off the edge of the grid are skipped from then on
+	var forwardStart, forwardEnd, backwardStart, backwardEnd int
+	for step := 0; step < maxD && d.work < diffWorkLimit; step++ {
+		d.work += 2*step + 2
+		for k := -step + forwardStart; k <= step-forwardEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.aIDs[aLo+x] == d.bIDs[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if x > n {
+				forwardEnd += 2
+			} else if y > m {
+				forwardStart += 2
+			} else if odd {
+				if reverseK := offset + delta - k; reverseK >= 0 && reverseK < len(backward) && backward[reverseK] != -1 {
+					if x >= n-backward[reverseK] {
+						return aLo + x, bLo + y
+					}
+				}
+			}
+		}
+
+		for k := -step + backwardStart; k <= step-backwardEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.aIDs[aHi-x-1] == d.bIDs[bHi-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			if x > n {
+				backwardEnd += 2
+			} else if y > m {
+				backwardStart += 2
+			} else if !odd {
+				if forwardK := offset + delta - k; forwardK >= 0 && forwardK < len(forward) && forward[forwardK] != -1 {
+					forwardX := forward[forwardK]
+					if forwardX >= n-x {
+						return aLo + forwardX, bLo + forwardX - (forwardK - offset)
+					}
+				}
+			}
+		}
+	}
+
+	// the searches always meet within maxD steps, so only running out of work gets here
+	return aHi, bLo
+}
+
+func (d *lineDiffer) appendLine(op models.DiffOp, x int, y int) {
+	switch op {
+	case models.DiffOpEqual:
+		d.lines = append(d.lines, models.DiffLine{Op: op, Text: d.a[x], OldLine: x + 1, NewLine: y + 1})
+	case models.DiffOpInsert:
+		d.lines = append(d.lines, models.DiffLine{Op: op, Text: d.b[y], NewLine: y + 1})
+	case models.DiffOpDelete:
+		d.lines = append(d.lines, models.DiffLine{Op: op, Text: d.a[x], OldLine: x + 1})
+	}
+}
@@ -0,0 +1,156 @@
+package libs
+
+import (
+	"fmt"
+	"go_blogs/models"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rebuild returns the old and new texts a diff was computed from
+func rebuild(lines []models.DiffLine) (string, string) {
+	var oldLines, newLines []string
+	for _, line := range lines {
+		if line.Op != models.DiffOpInsert {
+			oldLines = append(oldLines, line.Text)
+		}
+		if line.Op != models.DiffOpDelete {
+			newLines = append(newLines, line.Text)
+		}
+	}
+	return strings.Join(oldLines, "\n"), strings.Join(newLines, "\n")
+}
+
+// lcsLength is the length of the longest common subsequence of a and b, which the shortest edit script keeps
+func lcsLength(a []string, b []string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				current[j+1] = previous[j] + 1
+			} else {
+				current[j+1] = max(previous[j+1], current[j])
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    []models.DiffLine
+	}{
+		{
+			name: "both empty",
+			want: []models.DiffLine{},
+		},
+		{
+			name:    "unchanged",
+			oldText: "a\nb",
+			newText: "a\nb",
+			want: []models.DiffLine{
+				{Op: models.DiffOpEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: models.DiffOpEqual, Text: "b", OldLine: 2, NewLine: 2},
+			},
+		},
+		{
+			name:    "insert into empty",
+			newText: "a\nb",
+			want: []models.DiffLine{
+				{Op: models.DiffOpInsert, Text: "a", NewLine: 1},
+				{Op: models.DiffOpInsert, Text: "b", NewLine: 2},
+			},
+		},
+		{
+			name:    "replace a middle line",
+			oldText: "a\nb\nc",
+			newText: "a\nx\nc",
+			want: []models.DiffLine{
+				{Op: models.DiffOpEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: models.DiffOpDelete, Text: "b", OldLine: 2},
+				{Op: models.DiffOpInsert, Text: "x", NewLine: 2},
+				{Op: models.DiffOpEqual, Text: "c", OldLine: 3, NewLine: 3},
+			},
+		},
+		{
+			name:    "windows line endings",
+			oldText: "a\r\nb",
+			newText: "a\nb\nc",
+			want: []models.DiffLine{
+				{Op: models.DiffOpEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: models.DiffOpEqual, Text: "b", OldLine: 2, NewLine: 2},
+				{Op: models.DiffOpInsert, Text: "c", NewLine: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffLines(tt.oldText, tt.newText)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DiffLines() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("DiffLines() = %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffLinesIsShortest(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		lines := make([]string, random.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for i := 0; i < 2000; i++ {
+		oldText, newText := randomText(), randomText()
+		lines := DiffLines(oldText, newText)
+
+		gotOld, gotNew := rebuild(lines)
+		if gotOld != oldText || gotNew != newText {
+			t.Fatalf("diff of %q and %q rebuilds %q and %q", oldText, newText, gotOld, gotNew)
+		}
+
+		equal := 0
+		for _, line := range lines {
+			if line.Op == models.DiffOpEqual {
+				equal++
+			}
+		}
+		if want := lcsLength(splitLines(oldText), splitLines(newText)); equal != want {
+			t.Fatalf("diff of %q and %q keeps %d lines, want %d", oldText, newText, equal, want)
+		}
+	}
+}
+
+func TestDiffLinesOfLargeTexts(t *testing.T) {
+	oldLines := make([]string, 20000)
+	newLines := make([]string, 20000)
+	for i := range oldLines {
+		oldLines[i] = fmt.Sprintf("line %d", i)
+		newLines[i] = fmt.Sprintf("line %d", i*7)
+	}
+	oldText, newText := strings.Join(oldLines, "\n"), strings.Join(newLines, "\n")
+
+	start := time.Now()
+	gotOld, gotNew := rebuild(DiffLines(oldText, newText))
+	if gotOld != oldText || gotNew != newText {
+		t.Fatal("diff of large texts does not rebuild them")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("diff of large texts took %s", elapsed)
+	}
+}
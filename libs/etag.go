@@ -0,0 +1,28 @@
+package libs
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidETag = errors.New("invalid etag")
+
+// FormatETag formats a document version as a strong entity tag
+func FormatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ParseETag extracts the document version from a strong entity tag such as "3"
+func ParseETag(etag string) (int, error) {
+	etag = strings.TrimSpace(etag)
+	if len(etag) < 2 || !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		return 0, ErrInvalidETag
+	}
+
+	version, err := strconv.Atoi(etag[1 : len(etag)-1])
+	if err != nil || version < 1 {
+		return 0, ErrInvalidETag
+	}
+	return version, nil
+}
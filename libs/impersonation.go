@@ -0,0 +1,88 @@
+package libs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrSessionExpired is returned when the session of a request expires while its data is being replaced
+var ErrSessionExpired = errors.New("session has expired")
+
+// replaceUserSessionData replaces the data of the session of the request, keeping the session and its expiry.
+// The session stays in the index of the user who logged in to it
+func replaceUserSessionData(c *fiber.Ctx, data models.UserSessionData) error {
+	sessionID, err := GetSessionID(c)
+	if err != nil {
+		return err
+	}
+
+	marshaledSessionData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	updated, err := connections.RedisClient.SetArgs(context.TODO(), fmt.Sprintf("sess:%s", sessionID), string(marshaledSessionData), redis.SetArgs{
+		Mode:    "XX",
+		KeepTTL: true,
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if updated == "" {
+		return ErrSessionExpired
+	}
+	return nil
+}
+
+// StartImpersonation turns the session of the request into a session of user, remembering impersonator.
+// Revoking the sessions of the impersonator ends it, while those of user are left alone
+func StartImpersonation(c *fiber.Ctx, impersonator models.Impersonator, user models.UserSessionData) (models.UserSessionData, error) {
+	user.Impersonator = &impersonator
+	return user, replaceUserSessionData(c, user)
+}
+
+// revokedAccessTokenKey marks the access token tokenID as revoked until it expires
+func revokedAccessTokenKey(tokenID string) string {
+	return fmt.Sprintf("revoked_access_token:%s", tokenID)
+}
+
+// isAccessTokenRevoked reports whether the access token tokenID has been revoked
+func isAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	exists, err := connections.RedisClient.Exists(ctx, revokedAccessTokenKey(tokenID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return exists > 0, nil
+}
+
+// StopImpersonation turns the session of the request back into a session of the impersonator, with impersonator
+// as their current data. With AUTH_MODE jwt, the access token of the impersonation is revoked, as it would
+// otherwise act as the user until it expires
+func StopImpersonation(c *fiber.Ctx, impersonator models.UserSessionData) error {
+	impersonator.Impersonator = nil
+	if err := replaceUserSessionData(c, impersonator); err != nil {
+		return err
+	}
+	if configs.Env.AuthMode != AuthModeJWT {
+		return nil
+	}
+
+	claims, err := getAccessTokenClaims(c)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return connections.RedisClient.Set(context.TODO(), revokedAccessTokenKey(claims.ID), 1, ttl).Err()
+}
@@ -0,0 +1,286 @@
+package libs
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/models"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AuthModeSession = "session"
+	AuthModeJWT     = "jwt"
+)
+
+// accessTokenClaimsLocal caches the verified access token of a request
+const accessTokenClaimsLocal = "accessTokenClaims"
+
+var ErrInvalidAccessToken = errors.New("invalid access token")
+
+// jwtKey is a key access tokens are signed or verified with, named by the kid header
+type jwtKey struct {
+	id         string
+	signingKey crypto.PrivateKey
+	verifyKey  crypto.PublicKey
+}
+
+// jwtKeySet holds the keys of the configured algorithm. The first key signs and every key verifies
+type jwtKeySet struct {
+	method jwt.SigningMethod
+	keys   []jwtKey
+}
+
+var jwtKeys *jwtKeySet
+
+// accessTokenClaims are the claims of an access token. User is the session data at the time the token was issued
+type accessTokenClaims struct {
+	jwt.RegisteredClaims
+	SessionID string                 `json:"sid"`
+	User      models.UserSessionData `json:"user"`
+}
+
+// InitJWT loads the keys access tokens are signed with when AUTH_MODE is jwt. To rotate keys, put the new key
+// first and drop the old one once the access tokens it signed have expired
+func InitJWT() {
+	switch configs.Env.AuthMode {
+	case "", AuthModeSession:
+		return
+	case AuthModeJWT:
+		keys, err := loadJWTKeys(configs.Env.JWTAlgorithm, configs.Env.JWTSecrets, configs.Env.JWTKeyFiles)
+		if err != nil {
+			panic(err)
+		}
+		jwtKeys = keys
+	default:
+		panic(fmt.Sprintf("unknown auth mode %q", configs.Env.AuthMode))
+	}
+}
+
+func loadJWTKeys(algorithm string, secrets []string, keyFiles []string) (*jwtKeySet, error) {
+	switch algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if len(secrets) == 0 {
+			secret, err := NewToken()
+			if err != nil {
+				return nil, err
+			}
+			secrets = []string{secret}
+			fmt.Println("JWT_SECRETS is not set, access tokens will not work across restarts or replicas")
+		}
+		keySet := &jwtKeySet{method: jwt.SigningMethodHS256}
+		for _, secret := range secrets {
+			key := []byte(secret)
+			// the kid must not reveal the secret, so it is a truncated hash of it
+			keySet.keys = append(keySet.keys, jwtKey{id: HashToken(secret)[:16], signingKey: key, verifyKey: key})
+		}
+		return keySet, nil
+	case jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg():
+		keySet := &jwtKeySet{method: jwt.GetSigningMethod(algorithm)}
+		if len(keyFiles) == 0 {
+			privateKey, err := generateJWTKey(algorithm)
+			if err != nil {
+				return nil, err
+			}
+			key, err := newJWTKey(privateKey)
+			if err != nil {
+				return nil, err
+			}
+			keySet.keys = append(keySet.keys, key)
+			fmt.Println("JWT_KEY_FILES is not set, access tokens will not work across restarts or replicas")
+			return keySet, nil
+		}
+		for _, keyFile := range keyFiles {
+			privateKey, err := readJWTKeyFile(strings.TrimSpace(keyFile))
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := privateKey.(ed25519.PrivateKey); ok != (algorithm == jwt.SigningMethodEdDSA.Alg()) {
+				return nil, fmt.Errorf("%s is not a key for %s", keyFile, algorithm)
+			}
+			key, err := newJWTKey(privateKey)
+			if err != nil {
+				return nil, err
+			}
+			keySet.keys = append(keySet.keys, key)
+		}
+		return keySet, nil
+	default:
+		return nil, fmt.Errorf("unknown JWT algorithm %q", algorithm)
+	}
+}
+
+func generateJWTKey(algorithm string) (crypto.PrivateKey, error) {
+	if algorithm == jwt.SigningMethodEdDSA.Alg() {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	}
+	return rsa.GenerateKey(rand.Reader, 2048)
+}
+
+// readJWTKeyFile reads a PEM private key, in PKCS #8 or, for RSA, PKCS #1
+func readJWTKeyFile(path string) (crypto.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch privateKey.(type) {
+	case ed25519.PrivateKey, *rsa.PrivateKey:
+		return privateKey, nil
+	default:
+		return nil, fmt.Errorf("%s is neither an Ed25519 nor an RSA key", path)
+	}
+}
+
+// newJWTKey names privateKey after a hash of its public key, so that the kid stays the same across restarts
+func newJWTKey(privateKey crypto.PrivateKey) (jwtKey, error) {
+	publicKey := privateKey.(crypto.Signer).Public()
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return jwtKey{}, err
+	}
+	hash := sha256.Sum256(der)
+	return jwtKey{id: hex.EncodeToString(hash[:8]), signingKey: privateKey, verifyKey: publicKey}, nil
+}
+
+// IssueAccessToken signs an access token for data in the session sessionID
+func IssueAccessToken(sessionID string, data models.UserSessionData) (string, error) {
+	if jwtKeys == nil {
+		return "", errors.New("AUTH_MODE is not jwt")
+	}
+
+	tokenID, err := NewToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	data.AccessToken = nil
+	claims := accessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    configs.Env.AppURL,
+			Subject:   data.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(configs.Env.JWTAccessTokenTTL)),
+		},
+		SessionID: sessionID,
+		User:      data,
+	}
+
+	signingKey := jwtKeys.keys[0]
+	token := jwt.NewWithClaims(jwtKeys.method, claims)
+	token.Header["kid"] = signingKey.id
+	return token.SignedString(signingKey.signingKey)
+}
+
+// parseAccessToken verifies an access token and returns its claims
+func parseAccessToken(tokenString string) (*accessTokenClaims, error) {
+	if jwtKeys == nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	var claims accessTokenClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range jwtKeys.keys {
+			if key.id == kid {
+				return key.verifyKey, nil
+			}
+		}
+		return nil, ErrInvalidAccessToken
+	},
+		jwt.WithValidMethods([]string{jwtKeys.method.Alg()}),
+		jwt.WithIssuer(configs.Env.AppURL),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidAccessToken, err)
+	}
+	return &claims, nil
+}
+
+// getAccessTokenClaims returns the claims of the bearer access token of the request
+func getAccessTokenClaims(c *fiber.Ctx) (*accessTokenClaims, error) {
+	if claims, ok := c.Locals(accessTokenClaimsLocal).(*accessTokenClaims); ok {
+		return claims, nil
+	}
+
+	token, ok := GetBearerToken(c)
+	if !ok {
+		return nil, ErrInvalidAccessToken
+	}
+	claims, err := parseAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+	// only access tokens of impersonations can be revoked, so that the others are never looked up
+	if claims.User.Impersonator != nil {
+		revoked, err := isAccessTokenRevoked(context.TODO(), claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrInvalidAccessToken
+		}
+	}
+
+	c.Locals(accessTokenClaimsLocal, claims)
+	return claims, nil
+}
+
+// GetJWKS returns the public keys access tokens are verified with. Secrets of HS256 are never published
+func GetJWKS() models.JWKS {
+	jwks := models.JWKS{Keys: []models.JWK{}}
+	if jwtKeys == nil {
+		return jwks
+	}
+
+	for _, key := range jwtKeys.keys {
+		jwk := models.JWK{
+			Kid: key.id,
+			Alg: jwtKeys.method.Alg(),
+			Use: "sig",
+		}
+		switch publicKey := key.verifyKey.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
@@ -0,0 +1,151 @@
+package libs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginThrottle counts failed logins per account and per IP in Redis. Once failures reach BackoffAfter,
+// each further failure blocks the account or IP for exponentially longer, and reaching a lockout
+// threshold blocks it for LockoutDuration. Failures are forgotten after FailureWindow without one
+type LoginThrottle struct {
+	Redis *redis.Client
+
+	FailureWindow      time.Duration
+	BackoffAfter       int64
+	BackoffBase        time.Duration
+	BackoffMax         time.Duration
+	LockoutThreshold   int64
+	IPLockoutThreshold int64
+	LockoutDuration    time.Duration
+}
+
+// LoginFailure is the outcome of recording a failed login
+type LoginFailure struct {
+	// AccountLocked is true when this failure locked the account. Failures are not recorded while
+	// an account is locked, so this happens once per lockout
+	AccountLocked bool
+	RetryAfter    time.Duration
+}
+
+const (
+	loginScopeAccount = "account"
+	loginScopeIP      = "ip"
+)
+
+func loginFailuresKey(scope string, id string) string {
+	return fmt.Sprintf("login_failures:%s:%s", scope, id)
+}
+
+func loginBlockKey(scope string, id string) string {
+	return fmt.Sprintf("login_block:%s:%s", scope, id)
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// RetryAfter returns how long logins to email or from ip are blocked, zero when they are not
+func (t *LoginThrottle) RetryAfter(ctx context.Context, email string, ip string) (time.Duration, error) {
+	pipe := t.Redis.Pipeline()
+	accountTTL := pipe.PTTL(ctx, loginBlockKey(loginScopeAccount, normalizeLoginEmail(email)))
+	ipTTL := pipe.PTTL(ctx, loginBlockKey(loginScopeIP, ip))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	// PTTL is negative for keys that do not exist
+	retryAfter := accountTTL.Val()
+	if ipTTL.Val() > retryAfter {
+		retryAfter = ipTTL.Val()
+	}
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return retryAfter, nil
+}
+
+// RecordFailure counts a failed login to email from ip and blocks either of them when needed
+func (t *LoginThrottle) RecordFailure(ctx context.Context, email string, ip string) (LoginFailure, error) {
+	var result LoginFailure
+
+	accountFailures, err := t.countFailure(ctx, loginScopeAccount, normalizeLoginEmail(email))
+	if err != nil {
+		return result, err
+	}
+	ipFailures, err := t.countFailure(ctx, loginScopeIP, ip)
+	if err != nil {
+		return result, err
+	}
+
+	accountBlock := t.blockDuration(accountFailures, t.LockoutThreshold)
+	ipBlock := t.blockDuration(ipFailures, t.IPLockoutThreshold)
+
+	if err = t.block(ctx, loginScopeAccount, normalizeLoginEmail(email), accountBlock); err != nil {
+		return result, err
+	}
+	if err = t.block(ctx, loginScopeIP, ip, ipBlock); err != nil {
+		return result, err
+	}
+
+	result.AccountLocked = t.LockoutThreshold > 0 && accountFailures >= t.LockoutThreshold
+	result.RetryAfter = accountBlock
+	if ipBlock > result.RetryAfter {
+		result.RetryAfter = ipBlock
+	}
+	return result, nil
+}
+
+// Reset forgets the failures of email after a successful login. The failures of the IP are kept,
+// so that an attacker cannot clear them by logging in to an account of their own
+func (t *LoginThrottle) Reset(ctx context.Context, email string) error {
+	return t.Unlock(ctx, email)
+}
+
+// Unlock lifts the block on email and forgets its failures
+func (t *LoginThrottle) Unlock(ctx context.Context, email string) error {
+	id := normalizeLoginEmail(email)
+	return t.Redis.Del(ctx, loginFailuresKey(loginScopeAccount, id), loginBlockKey(loginScopeAccount, id)).Err()
+}
+
+func (t *LoginThrottle) countFailure(ctx context.Context, scope string, id string) (int64, error) {
+	key := loginFailuresKey(scope, id)
+	pipe := t.Redis.TxPipeline()
+	failures := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, t.FailureWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return failures.Val(), nil
+}
+
+// blockDuration is how long to block after the given number of failures: nothing before BackoffAfter,
+// then BackoffBase doubling per failure up to BackoffMax, and LockoutDuration from lockoutThreshold
+func (t *LoginThrottle) blockDuration(failures int64, lockoutThreshold int64) time.Duration {
+	if lockoutThreshold > 0 && failures >= lockoutThreshold {
+		return t.LockoutDuration
+	}
+	if failures < t.BackoffAfter {
+		return 0
+	}
+
+	duration := t.BackoffBase
+	for i := t.BackoffAfter; i < failures && duration < t.BackoffMax; i++ {
+		duration *= 2
+	}
+	if duration > t.BackoffMax {
+		duration = t.BackoffMax
+	}
+	return duration
+}
+
+func (t *LoginThrottle) block(ctx context.Context, scope string, id string, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+	return t.Redis.Set(ctx, loginBlockKey(scope, id), 1, duration).Err()
+}
@@ -0,0 +1,109 @@
+package libs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestLoginThrottle(t *testing.T) (*LoginThrottle, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	rds := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { rds.Close() })
+	return &LoginThrottle{
+		Redis:              rds,
+		FailureWindow:      time.Hour,
+		BackoffAfter:       2,
+		BackoffBase:        time.Second,
+		BackoffMax:         4 * time.Second,
+		LockoutThreshold:   5,
+		IPLockoutThreshold: 8,
+		LockoutDuration:    30 * time.Minute,
+	}, server
+}
+
+func recordFailures(t *testing.T, throttle *LoginThrottle, email string, ip string, n int) LoginFailure {
+	t.Helper()
+	var failure LoginFailure
+	for i := 0; i < n; i++ {
+		var err error
+		if failure, err = throttle.RecordFailure(context.TODO(), email, ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return failure
+}
+
+func wantRetryAfter(t *testing.T, throttle *LoginThrottle, email string, ip string, want time.Duration) {
+	t.Helper()
+	retryAfter, err := throttle.RetryAfter(context.TODO(), email, ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retryAfter != want {
+		t.Fatalf("retry after %s, want %s", retryAfter, want)
+	}
+}
+
+func TestLoginThrottleBacksOffThenLocks(t *testing.T) {
+	throttle, server := newTestLoginThrottle(t)
+	email := "user@example.com"
+
+	failures := []struct {
+		retryAfter time.Duration
+		locked     bool
+	}{
+		{0, false},
+		{time.Second, false},
+		{2 * time.Second, false},
+		{4 * time.Second, false},
+		{30 * time.Minute, true},
+	}
+	for i, want := range failures {
+		failure := recordFailures(t, throttle, email, "10.0.0.1", 1)
+		if failure.RetryAfter != want.retryAfter || failure.AccountLocked != want.locked {
+			t.Fatalf("failure %d: retry after %s, locked %v, want %s, %v", i+1, failure.RetryAfter, failure.AccountLocked, want.retryAfter, want.locked)
+		}
+	}
+
+	// emails are throttled whatever their case and surrounding spaces
+	wantRetryAfter(t, throttle, " USER@example.com ", "10.0.0.2", 30*time.Minute)
+
+	server.FastForward(30 * time.Minute)
+	wantRetryAfter(t, throttle, email, "10.0.0.2", 0)
+}
+
+func TestLoginThrottleResetKeepsIPFailures(t *testing.T) {
+	throttle, _ := newTestLoginThrottle(t)
+	ip := "10.0.0.1"
+
+	recordFailures(t, throttle, "user@example.com", ip, 4)
+	if err := throttle.Reset(context.TODO(), "user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	wantRetryAfter(t, throttle, "user@example.com", "10.0.0.2", 0)
+
+	// the IP keeps its 4 failures, so 4 more to another account lock it
+	failure := recordFailures(t, throttle, "other@example.com", ip, 4)
+	if failure.AccountLocked {
+		t.Fatal("4 failures locked the other account")
+	}
+	wantRetryAfter(t, throttle, "nobody@example.com", ip, 30*time.Minute)
+}
+
+func TestLoginThrottleForgetsFailuresAfterWindow(t *testing.T) {
+	throttle, server := newTestLoginThrottle(t)
+	email := "user@example.com"
+
+	recordFailures(t, throttle, email, "10.0.0.1", 4)
+	server.FastForward(time.Hour)
+
+	failure := recordFailures(t, throttle, email, "10.0.0.1", 1)
+	if failure.RetryAfter != 0 || failure.AccountLocked {
+		t.Fatalf("first failure after the window: retry after %s, locked %v", failure.RetryAfter, failure.AccountLocked)
+	}
+}
@@ -0,0 +1,55 @@
+package libs
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"go_blogs/configs"
+)
+
+var ErrInvalidSealedSecret = errors.New("invalid sealed secret")
+
+// secretBoxCipher derives an AES-256-GCM cipher from the secret encryption key
+func secretBoxCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(configs.Env.SecretEncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SealSecret encrypts a secret that must be stored but read back later, such as a TOTP secret
+func SealSecret(secret string) (string, error) {
+	aead, err := secretBoxCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenSecret decrypts a secret sealed by SealSecret
+func OpenSecret(sealed string) (string, error) {
+	aead, err := secretBoxCipher()
+	if err != nil {
+		return "", err
+	}
+	decoded, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil || len(decoded) < aead.NonceSize() {
+		return "", ErrInvalidSealedSecret
+	}
+	nonce, ciphertext := decoded[:aead.NonceSize()], decoded[aead.NonceSize():]
+	secret, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrInvalidSealedSecret
+	}
+	return string(secret), nil
+}
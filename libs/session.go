@@ -3,17 +3,25 @@ package libs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"go_blogs/configs"
 	"go_blogs/connections"
 	"go_blogs/models"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/redis/go-redis/v9"
 )
 
+const sessionExpiration = time.Hour * 6
+
+// sessionTouchInterval is how stale the last-seen time of a session may get, so that not every request writes it
+const sessionTouchInterval = time.Minute
+
 var sessionStorage = session.New(session.Config{
-	Expiration:     time.Hour * 6,
+	Expiration:     sessionExpiration,
 	CookieHTTPOnly: true,
 })
 
@@ -25,7 +33,26 @@ func getRequestSession(c *fiber.Ctx) (*session.Session, error) {
 	return sess, nil
 }
 
+// sessionTTL is how long a session lasts without being used. Sessions of AUTH_MODE jwt last as long as their refresh token
+func sessionTTL() time.Duration {
+	if configs.Env.AuthMode == AuthModeJWT {
+		return configs.Env.JWTRefreshTokenTTL
+	}
+	return sessionExpiration
+}
+
+// GetUserSessionData returns the data of the session of the request. With AUTH_MODE jwt, it is the data in the
+// bearer access token, which is not looked up so that requests stay stateless
 func GetUserSessionData(c *fiber.Ctx) (*models.UserSessionData, error) {
+	if configs.Env.AuthMode == AuthModeJWT {
+		claims, err := getAccessTokenClaims(c)
+		if err != nil {
+			return &models.UserSessionData{}, err
+		}
+		data := claims.User
+		return &data, nil
+	}
+
 	sess, err := getRequestSession(c)
 	if err != nil {
 		return &models.UserSessionData{}, err
@@ -61,7 +88,18 @@ func SetUserSessionData(c *fiber.Ctx, data models.UserSessionData) error {
 		return err
 	}
 
-	if err = connections.RedisClient.Set(context.TODO(), sessionKey, string(marshaledSessionData), time.Hour*6).Err(); err != nil {
+	if err = connections.RedisClient.Set(context.TODO(), sessionKey, string(marshaledSessionData), sessionExpiration).Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	info := sessionInfo{
+		UserAgent: string(c.Request().Header.UserAgent()),
+		IP:        c.IP(),
+		CreatedAt: now.Unix(),
+		LastSeen:  now.Unix(),
+	}
+	if err = addUserSession(context.TODO(), data.ID, sess.ID(), info); err != nil {
 		return err
 	}
 
@@ -73,13 +111,28 @@ func SetUserSessionData(c *fiber.Ctx, data models.UserSessionData) error {
 }
 
 func DestroyUserSessionData(c *fiber.Ctx) error {
+	if configs.Env.AuthMode == AuthModeJWT {
+		claims, err := getAccessTokenClaims(c)
+		if err != nil {
+			return err
+		}
+		// the access token stays valid until it expires, but cannot be refreshed
+		return revokeUserSession(context.TODO(), claims.User.OwnerID(), claims.SessionID)
+	}
+
 	sess, err := getRequestSession(c)
 	if err != nil {
 		return err
 	}
 
+	if userData, err := GetUserSessionData(c); err == nil {
+		if err = connections.RedisClient.SRem(context.TODO(), userSessionsKey(userData.OwnerID()), sess.ID()).Err(); err != nil {
+			return err
+		}
+	}
+
 	sessionKey := fmt.Sprintf("sess:%s", sess.ID())
-	if err = connections.RedisClient.Del(context.TODO(), sessionKey).Err(); err != nil {
+	if err = connections.RedisClient.Del(context.TODO(), sessionKey, sessionInfoKey(sess.ID())).Err(); err != nil {
 		return err
 	}
 
@@ -89,3 +142,99 @@ func DestroyUserSessionData(c *fiber.Ctx) error {
 
 	return nil
 }
+
+// GetSessionID returns the ID of the session of the request
+func GetSessionID(c *fiber.Ctx) (string, error) {
+	if configs.Env.AuthMode == AuthModeJWT {
+		claims, err := getAccessTokenClaims(c)
+		if err != nil {
+			return "", err
+		}
+		return claims.SessionID, nil
+	}
+
+	sess, err := getRequestSession(c)
+	if err != nil {
+		return "", err
+	}
+	return sess.ID(), nil
+}
+
+// userSessionsKey is the set of the IDs of every session of a user. It may hold expired sessions,
+// which are dropped when the set is next walked
+func userSessionsKey(userID string) string {
+	return fmt.Sprintf("user_sess:%s", userID)
+}
+
+func addUserSession(ctx context.Context, userID string, sessionID string, info sessionInfo) error {
+	key := userSessionsKey(userID)
+	pipe := connections.RedisClient.TxPipeline()
+	pipe.SAdd(ctx, key, sessionID)
+	pipe.Expire(ctx, key, sessionTTL())
+	pipe.HSet(ctx, sessionInfoKey(sessionID), info)
+	pipe.Expire(ctx, sessionInfoKey(sessionID), sessionTTL())
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RevokeUserSessions logs a user out of every session except keepSessionID, which may be empty to revoke them all
+func RevokeUserSessions(ctx context.Context, userID string, keepSessionID string) error {
+	key := userSessionsKey(userID)
+	sessionIDs, err := connections.RedisClient.SMembers(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if sessionID == keepSessionID {
+			continue
+		}
+		if err = revokeUserSession(ctx, userID, sessionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func revokeUserSession(ctx context.Context, userID string, sessionID string) error {
+	pipe := connections.RedisClient.TxPipeline()
+	pipe.Del(ctx, fmt.Sprintf("sess:%s", sessionID), sessionInfoKey(sessionID), refreshTokenKey(sessionID), retiredRefreshTokensKey(sessionID))
+	pipe.SRem(ctx, userSessionsKey(userID), sessionID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// UpdateUserSessionsData replaces the data of every live session of the user data belongs to, keeping their expiry.
+// Sessions impersonating the user are not among them
+func UpdateUserSessionsData(ctx context.Context, data models.UserSessionData) error {
+	// data may be a copy of the session data of an impersonator, which must not spread to the user's own sessions
+	data.Impersonator = nil
+
+	marshaledSessionData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	key := userSessionsKey(data.ID)
+	sessionIDs, err := connections.RedisClient.SMembers(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		// XX leaves sessions that have expired in the meantime expired
+		updated, err := connections.RedisClient.SetArgs(ctx, fmt.Sprintf("sess:%s", sessionID), string(marshaledSessionData), redis.SetArgs{
+			Mode:    "XX",
+			KeepTTL: true,
+		}).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		if updated == "" {
+			if err = connections.RedisClient.SRem(ctx, key, sessionID).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
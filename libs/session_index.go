@@ -0,0 +1,169 @@
+package libs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_blogs/connections"
+	"go_blogs/models"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// sessionInfo describes where a session was created, kept in the sess_info:<id> hash next to sess:<id>
+type sessionInfo struct {
+	UserAgent string `redis:"userAgent"`
+	IP        string `redis:"ip"`
+	CreatedAt int64  `redis:"createdAt"`
+	LastSeen  int64  `redis:"lastSeen"`
+}
+
+func sessionInfoKey(sessionID string) string {
+	return fmt.Sprintf("sess_info:%s", sessionID)
+}
+
+// PublicSessionID identifies a session to its user without revealing the session ID, which is the cookie value
+func PublicSessionID(sessionID string) string {
+	return HashToken(sessionID)[:32]
+}
+
+// touchSessionScript updates the last-seen time of a session at most once per interval, and never
+// recreates the info of a session that has expired
+var touchSessionScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+local lastSeen = tonumber(redis.call("HGET", KEYS[1], "lastSeen") or "0")
+if tonumber(ARGV[1]) - lastSeen < tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("HSET", KEYS[1], "lastSeen", ARGV[1], "ip", ARGV[3])
+return 1
+`)
+
+// TouchUserSession records that the session of the request has just been used
+func TouchUserSession(c *fiber.Ctx) error {
+	sessionID, err := GetSessionID(c)
+	if err != nil {
+		return err
+	}
+	keys := []string{sessionInfoKey(sessionID)}
+	args := []interface{}{time.Now().Unix(), int64(sessionTouchInterval.Seconds()), c.IP()}
+	return touchSessionScript.Run(context.TODO(), connections.RedisClient, keys, args...).Err()
+}
+
+// GetUserSessions lists the live sessions of a user, most recently seen first. currentSessionID is marked as current.
+// Sessions that have expired are dropped from the index on the way
+func GetUserSessions(ctx context.Context, userID string, currentSessionID string) ([]models.UserSession, error) {
+	sessionIDs, err := connections.RedisClient.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := connections.RedisClient.Pipeline()
+	exists := make([]*redis.IntCmd, len(sessionIDs))
+	infos := make([]*redis.MapStringStringCmd, len(sessionIDs))
+	ttls := make([]*redis.DurationCmd, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		exists[i] = pipe.Exists(ctx, fmt.Sprintf("sess:%s", sessionID))
+		infos[i] = pipe.HGetAll(ctx, sessionInfoKey(sessionID))
+		ttls[i] = pipe.TTL(ctx, fmt.Sprintf("sess:%s", sessionID))
+	}
+	if _, err = pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	sessions := []models.UserSession{}
+	for i, sessionID := range sessionIDs {
+		if exists[i].Val() == 0 {
+			if err = revokeUserSession(ctx, userID, sessionID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var info sessionInfo
+		if err = infos[i].Scan(&info); err != nil {
+			return nil, err
+		}
+		session := models.UserSession{
+			ID:        PublicSessionID(sessionID),
+			UserAgent: info.UserAgent,
+			IP:        info.IP,
+			Current:   sessionID == currentSessionID,
+		}
+		// sessions from before the info was kept have none
+		if info.CreatedAt > 0 {
+			createdAt := time.Unix(info.CreatedAt, 0)
+			session.CreatedAt = &createdAt
+		}
+		if info.LastSeen > 0 {
+			lastSeenAt := time.Unix(info.LastSeen, 0)
+			session.LastSeenAt = &lastSeenAt
+		}
+		if ttls[i].Val() > 0 {
+			expiresAt := time.Now().Add(ttls[i].Val()).Truncate(time.Second)
+			session.ExpiresAt = &expiresAt
+		}
+		sessions = append(sessions, session)
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return lastSeenUnix(sessions[i]) > lastSeenUnix(sessions[j])
+	})
+	return sessions, nil
+}
+
+func lastSeenUnix(session models.UserSession) int64 {
+	if session.LastSeenAt == nil {
+		return 0
+	}
+	return session.LastSeenAt.Unix()
+}
+
+// RevokeUserSession logs a user out of the session with the given public ID. It returns false when the user has no such session
+func RevokeUserSession(ctx context.Context, userID string, publicID string) (bool, error) {
+	sessionIDs, err := connections.RedisClient.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return false, err
+	}
+	for _, sessionID := range sessionIDs {
+		if PublicSessionID(sessionID) == publicID {
+			return true, revokeUserSession(ctx, userID, sessionID)
+		}
+	}
+	return false, nil
+}
+
+// PruneUserSessionIndexes drops expired sessions from the session indexes of users. It walks at most count indexes
+// from cursor and returns the cursor to continue from, which is 0 once every index has been walked
+func PruneUserSessionIndexes(ctx context.Context, cursor uint64, count int64) (uint64, error) {
+	keys, next, err := connections.RedisClient.Scan(ctx, cursor, userSessionsKey("*"), count).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		userID := strings.TrimPrefix(key, userSessionsKey(""))
+		sessionIDs, err := connections.RedisClient.SMembers(ctx, key).Result()
+		if err != nil {
+			return 0, err
+		}
+		for _, sessionID := range sessionIDs {
+			exists, err := connections.RedisClient.Exists(ctx, fmt.Sprintf("sess:%s", sessionID)).Result()
+			if err != nil {
+				return 0, err
+			}
+			if exists == 0 {
+				if err = revokeUserSession(ctx, userID, sessionID); err != nil {
+					return 0, err
+				}
+			}
+		}
+	}
+	return next, nil
+}
@@ -0,0 +1,84 @@
+package libs
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const SlugMaxLength = 80
+
+var SlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// latinLetters maps letters that do not decompose into an ASCII base letter
+var latinLetters = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i",
+}
+
+var stripMarks = transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// Slugify converts text into a lowercase, hyphen separated, URL-safe slug.
+// Accented Latin letters are folded to ASCII and Thai is romanized word by word; other scripts are dropped,
+// so the result may be empty
+func Slugify(text string) string {
+	var romanized strings.Builder
+	var thaiRun []rune
+	flushThai := func() {
+		if len(thaiRun) > 0 {
+			for _, word := range SegmentThai(string(thaiRun)) {
+				romanized.WriteString(" " + RomanizeThai(word) + " ")
+			}
+			thaiRun = thaiRun[:0]
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		if unicode.Is(unicode.Thai, r) {
+			thaiRun = append(thaiRun, r)
+			continue
+		}
+		flushThai()
+		if latin, ok := latinLetters[r]; ok {
+			romanized.WriteString(latin)
+		} else {
+			romanized.WriteRune(r)
+		}
+	}
+	flushThai()
+
+	folded, _, err := transform.String(stripMarks, romanized.String())
+	if err != nil {
+		folded = romanized.String()
+	}
+
+	var builder strings.Builder
+	pendingHyphen := false
+	for _, r := range folded {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			pendingHyphen = builder.Len() > 0
+			continue
+		}
+		if pendingHyphen {
+			builder.WriteByte('-')
+			pendingHyphen = false
+		}
+		builder.WriteRune(r)
+	}
+
+	return truncateSlug(builder.String(), SlugMaxLength)
+}
+
+// truncateSlug shortens slug to at most maxLength bytes without cutting a word in half when possible
+func truncateSlug(slug string, maxLength int) string {
+	if len(slug) <= maxLength {
+		return slug
+	}
+	slug = slug[:maxLength]
+	if i := strings.LastIndexByte(slug, '-'); i > 0 {
+		slug = slug[:i]
+	}
+	return strings.TrimSuffix(slug, "-")
+}
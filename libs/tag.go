@@ -0,0 +1,41 @@
+package libs
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeTag lowercases a free-form tag and joins its words with hyphens, keeping only letters, digits
+// and the symbols used in technology names such as c++, c# and .net
+func NormalizeTag(tag string) string {
+	var builder strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#")) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '+' || r == '#' || r == '.':
+			if pendingHyphen {
+				builder.WriteByte('-')
+				pendingHyphen = false
+			}
+			builder.WriteRune(r)
+		case builder.Len() > 0:
+			pendingHyphen = true
+		}
+	}
+	return builder.String()
+}
+
+// NormalizeTags normalizes every tag and drops empty and duplicated ones, keeping the original order
+func NormalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, tag := range tags {
+		normalized := NormalizeTag(tag)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		result = append(result, normalized)
+	}
+	return result
+}
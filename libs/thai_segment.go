@@ -0,0 +1,180 @@
+package libs
+
+import (
+	_ "embed"
+	"strings"
+	"unicode"
+)
+
+//go:embed data/thai_words.txt
+var thaiWordList string
+
+type thaiTrieNode struct {
+	children map[rune]*thaiTrieNode
+	word     bool
+}
+
+var thaiDictionary = newThaiTrie(thaiWordList)
+
+func newThaiTrie(wordList string) *thaiTrieNode {
+	root := &thaiTrieNode{children: map[rune]*thaiTrieNode{}}
+	for _, line := range strings.Split(wordList, "\n") {
+		word := strings.TrimSpace(line)
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		node := root
+		for _, r := range word {
+			child := node.children[r]
+			if child == nil {
+				child = &thaiTrieNode{children: map[rune]*thaiTrieNode{}}
+				node.children[r] = child
+			}
+			node = child
+		}
+		node.word = true
+	}
+	return root
+}
+
+const (
+	thaiMaiyamok   = 'ๆ'
+	thaiPaiyannoi  = 'ฯ'
+	thaiSaraA      = 'ะ'
+	thaiSaraAa     = 'า'
+	thaiSaraAm     = 'ำ'
+	thaiSaraLeadE  = 'เ'
+	thaiSaraLeadAi = 'ไ'
+)
+
+func isThaiRepetitionMark(r rune) bool {
+	return r == thaiMaiyamok || r == thaiPaiyannoi
+}
+
+// continuesThaiCluster reports whether r belongs to the character before it, so no word may start at r
+func continuesThaiCluster(r rune) bool {
+	return unicode.Is(unicode.Mn, r) || r == thaiSaraA || r == thaiSaraAa || r == thaiSaraAm
+}
+
+// thaiClusterEnd returns the end of the smallest unbreakable group of characters starting at i:
+// a consonant with its leading vowel, vowel marks and tone marks
+func thaiClusterEnd(runes []rune, i int) int {
+	j := i
+	if runes[j] >= thaiSaraLeadE && runes[j] <= thaiSaraLeadAi && j+1 < len(runes) {
+		j++
+	}
+	j++
+	for j < len(runes) && continuesThaiCluster(runes[j]) {
+		j++
+	}
+	return j
+}
+
+// SegmentThai splits text into words. Thai, which is written without spaces, is cut at the boundaries of
+// dictionary words, preferring the segmentation that leaves the fewest characters outside known words and then
+// the one with the fewest words. Unknown Thai and other scripts are kept together, so joining the result gives back text
+func SegmentThai(text string) []string {
+	var words []string
+	var run []rune
+	runIsThai := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runIsThai {
+			words = append(words, segmentThaiRun(run)...)
+		} else {
+			words = append(words, string(run))
+		}
+		run = run[:0]
+	}
+	for _, r := range text {
+		isThai := unicode.Is(unicode.Thai, r)
+		if isThai != runIsThai {
+			flush()
+			runIsThai = isThai
+		}
+		run = append(run, r)
+	}
+	flush()
+	return words
+}
+
+type thaiSegmentation struct {
+	reached bool
+	unknown int
+	words   int
+	prev    int
+	known   bool
+}
+
+func (s thaiSegmentation) betterThan(other thaiSegmentation) bool {
+	if !other.reached {
+		return true
+	}
+	if s.unknown != other.unknown {
+		return s.unknown < other.unknown
+	}
+	return s.words < other.words
+}
+
+func segmentThaiRun(runes []rune) []string {
+	best := make([]thaiSegmentation, len(runes)+1)
+	best[0].reached = true
+
+	consider := func(from int, to int, known bool) {
+		candidate := thaiSegmentation{
+			reached: true,
+			unknown: best[from].unknown,
+			words:   best[from].words + 1,
+			prev:    from,
+			known:   known,
+		}
+		if !known {
+			candidate.unknown += to - from
+		}
+		if candidate.betterThan(best[to]) {
+			best[to] = candidate
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		if !best[i].reached {
+			continue
+		}
+		if isThaiRepetitionMark(runes[i]) {
+			consider(i, i+1, true)
+			continue
+		}
+
+		node := thaiDictionary
+		for j := i; j < len(runes); j++ {
+			node = node.children[runes[j]]
+			if node == nil {
+				break
+			}
+			if node.word && (j+1 == len(runes) || !continuesThaiCluster(runes[j+1])) {
+				consider(i, j+1, true)
+			}
+		}
+		consider(i, thaiClusterEnd(runes, i), false)
+	}
+
+	var ends []int
+	for end := len(runes); end > 0; end = best[end].prev {
+		ends = append(ends, end)
+	}
+
+	// consecutive unknown clusters are most likely one word missing from the dictionary
+	words := []string{}
+	start := 0
+	for i := len(ends) - 1; i >= 0; i-- {
+		end := ends[i]
+		if !best[end].known && i > 0 && !best[ends[i-1]].known {
+			continue
+		}
+		words = append(words, string(runes[start:end]))
+		start = end
+	}
+	return words
+}
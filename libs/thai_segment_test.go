@@ -0,0 +1,40 @@
+package libs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSegmentThai(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: nil},
+		{name: "pure thai", text: "ฉันรักภาษาไทย", want: []string{"ฉัน", "รัก", "ภาษา", "ไทย"}},
+		{name: "leading vowels", text: "ไปเที่ยวเชียงใหม่", want: []string{"ไป", "เที่ยว", "เชียงใหม่"}},
+		{name: "longest word wins", text: "นักเรียนไปโรงเรียน", want: []string{"นักเรียน", "ไป", "โรงเรียน"}},
+		{name: "unknown word kept whole", text: "คอมพิวเตอร์", want: []string{"คอมพิวเตอร์"}},
+		{name: "thai and latin without spaces", text: "เขียนGoง่ายมาก", want: []string{"เขียน", "Go", "ง่าย", "มาก"}},
+		{name: "thai and latin with spaces", text: "ใช้ React กับ Next.js", want: []string{"ใช้", " React ", "กับ", " Next.js"}},
+		{name: "arabic digits", text: "ประเทศไทยมีจังหวัด77จังหวัด", want: []string{"ประเทศ", "ไทย", "มี", "จังหวัด", "77", "จังหวัด"}},
+		{name: "digits between words", text: "ราคา 250 บาท", want: []string{"ราคา", " 250 ", "บาท"}},
+		{name: "punctuation", text: "สวัสดี, โลก!", want: []string{"สวัสดี", ", ", "โลก", "!"}},
+		{name: "repetition mark", text: "เด็กๆ เล่นกัน", want: []string{"เด็ก", "ๆ", " ", "เล่น", "กัน"}},
+		{name: "abbreviation mark", text: "กรุงเทพฯ", want: []string{"กรุงเทพ", "ฯ"}},
+		{name: "latin only", text: "Hello world", want: []string{"Hello world"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SegmentThai(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SegmentThai(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if joined := strings.Join(got, ""); joined != tt.text {
+				t.Errorf("SegmentThai(%q) joins back to %q", tt.text, joined)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package libs
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go_blogs/connections"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewToken returns a random URL-safe token for links sent to users
+func NewToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// HashToken hashes a token so it can be stored without the stored value being usable as the token
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func tokenKey(purpose string, token string) string {
+	return fmt.Sprintf("%s:%s", purpose, HashToken(token))
+}
+
+// StoreToken keeps value under the hash of token for ttl, so that a leak of Redis does not leak usable tokens
+func StoreToken(ctx context.Context, purpose string, token string, value interface{}, ttl time.Duration) error {
+	marshaledValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return connections.RedisClient.Set(ctx, tokenKey(purpose, token), string(marshaledValue), ttl).Err()
+}
+
+func userTokensKey(purpose string, userID string) string {
+	return fmt.Sprintf("%s_user:%s", purpose, userID)
+}
+
+// StoreUserToken stores token like StoreToken, and remembers it as a token of userID, so that DeleteUserTokens can
+// invalidate every token of the user at once
+func StoreUserToken(ctx context.Context, purpose string, userID string, token string, value interface{}, ttl time.Duration) error {
+	marshaledValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	key := tokenKey(purpose, token)
+	userKey := userTokensKey(purpose, userID)
+	// the index lives as long as the newest token of the user
+	pipe := connections.RedisClient.TxPipeline()
+	pipe.Set(ctx, key, string(marshaledValue), ttl)
+	pipe.SAdd(ctx, userKey, key)
+	pipe.Expire(ctx, userKey, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// DeleteUserTokens invalidates every token of purpose stored for userID with StoreUserToken
+func DeleteUserTokens(ctx context.Context, purpose string, userID string) error {
+	userKey := userTokensKey(purpose, userID)
+	keys, err := connections.RedisClient.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return err
+	}
+	return connections.RedisClient.Del(ctx, append(keys, userKey)...).Err()
+}
+
+// ConsumeToken loads the value stored for token into value and deletes it, so that a token works once.
+// It returns false when the token is unknown or has expired
+func ConsumeToken(ctx context.Context, purpose string, token string, value interface{}) (bool, error) {
+	result, err := connections.RedisClient.GetDel(ctx, tokenKey(purpose, token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if err = json.Unmarshal([]byte(result), value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadToken loads the value stored for token into value without consuming it, for tokens that
+// survive failed attempts. It returns false when the token is unknown or has expired
+func LoadToken(ctx context.Context, purpose string, token string, value interface{}) (bool, error) {
+	result, err := connections.RedisClient.Get(ctx, tokenKey(purpose, token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if err = json.Unmarshal([]byte(result), value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteToken invalidates token
+func DeleteToken(ctx context.Context, purpose string, token string) error {
+	key := tokenKey(purpose, token)
+	return connections.RedisClient.Del(ctx, key, key+":attempts").Err()
+}
+
+// CountTokenAttempt counts a failed attempt at using token and returns the attempts so far
+func CountTokenAttempt(ctx context.Context, purpose string, token string, ttl time.Duration) (int64, error) {
+	key := tokenKey(purpose, token) + ":attempts"
+	pipe := connections.RedisClient.TxPipeline()
+	attempts := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return attempts.Val(), nil
+}
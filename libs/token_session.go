@@ -0,0 +1,194 @@
+package libs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/models"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token that has already been exchanged is used again,
+	// which means it has leaked. Its session is revoked, logging out whoever holds the latest refresh token too
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// refreshTokenKey holds the hash of the only refresh token of a session that may be exchanged next
+func refreshTokenKey(sessionID string) string {
+	return fmt.Sprintf("sess_refresh:%s", sessionID)
+}
+
+// retiredRefreshTokensKept is how many of the refresh tokens a session has exchanged are remembered, most recent
+// first. Reusing an older one is refused like any unknown refresh token, without revoking the session
+const retiredRefreshTokensKept = 20
+
+// retiredRefreshTokensKey holds the hashes of the refresh tokens of a session that have been exchanged, scored by when
+func retiredRefreshTokensKey(sessionID string) string {
+	return fmt.Sprintf("sess_refresh_retired:%s", sessionID)
+}
+
+// rotateRefreshTokenScript swaps the refresh token of a session for the next one and returns the session data.
+// A refresh token that has already been exchanged revokes the session, while any other is only refused, so that
+// guessing tokens of a session cannot log it out
+var rotateRefreshTokenScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+local data = redis.call("GET", KEYS[2])
+if not current or not data then
+	return {0, ""}
+end
+if current ~= ARGV[1] then
+	if not redis.call("ZSCORE", KEYS[4], ARGV[1]) then
+		return {0, ""}
+	end
+	redis.call("DEL", KEYS[1], KEYS[2], KEYS[3], KEYS[4])
+	return {-1, data}
+end
+local ttl = tonumber(ARGV[3])
+redis.call("SET", KEYS[1], ARGV[2], "PX", ttl)
+redis.call("ZADD", KEYS[4], ARGV[4], ARGV[1])
+redis.call("ZREMRANGEBYRANK", KEYS[4], 0, -tonumber(ARGV[5]) - 1)
+redis.call("PEXPIRE", KEYS[2], ttl)
+redis.call("PEXPIRE", KEYS[3], ttl)
+redis.call("PEXPIRE", KEYS[4], ttl)
+return {1, data}
+`)
+
+// newRefreshToken returns a refresh token of sessionID. The session ID leads the token so that it can be found,
+// and the random rest is what makes it secret
+func newRefreshToken(sessionID string) (string, error) {
+	secret, err := NewToken()
+	if err != nil {
+		return "", err
+	}
+	return sessionID + "." + secret, nil
+}
+
+func newTokenPair(sessionID string, refreshToken string, data models.UserSessionData) (models.TokenPair, error) {
+	accessToken, err := IssueAccessToken(sessionID, data)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	return models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(configs.Env.JWTAccessTokenTTL.Seconds()),
+		User:         data,
+	}, nil
+}
+
+// StartTokenSession starts a session for data without a cookie, for AUTH_MODE jwt. The session lives as long as its
+// refresh token is exchanged within the refresh token TTL, and is listed and revoked like any other session
+func StartTokenSession(c *fiber.Ctx, data models.UserSessionData) (models.TokenPair, error) {
+	ctx := context.TODO()
+
+	sessionID, err := NewToken()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	refreshToken, err := newRefreshToken(sessionID)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	marshaledSessionData, err := json.Marshal(data)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	ttl := configs.Env.JWTRefreshTokenTTL
+	pipe := connections.RedisClient.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("sess:%s", sessionID), string(marshaledSessionData), ttl)
+	pipe.Set(ctx, refreshTokenKey(sessionID), HashToken(refreshToken), ttl)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return models.TokenPair{}, err
+	}
+
+	now := time.Now()
+	info := sessionInfo{
+		UserAgent: string(c.Request().Header.UserAgent()),
+		IP:        c.IP(),
+		CreatedAt: now.Unix(),
+		LastSeen:  now.Unix(),
+	}
+	if err = addUserSession(ctx, data.OwnerID(), sessionID, info); err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return newTokenPair(sessionID, refreshToken, data)
+}
+
+// RefreshTokenSession exchanges a refresh token for a new access token and refresh token. The access token carries
+// the current session data, so changes to the user reach it on refresh
+func RefreshTokenSession(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	sessionID, _, found := strings.Cut(refreshToken, ".")
+	if !found || sessionID == "" {
+		return models.TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	nextRefreshToken, err := newRefreshToken(sessionID)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	keys := []string{
+		refreshTokenKey(sessionID),
+		fmt.Sprintf("sess:%s", sessionID),
+		sessionInfoKey(sessionID),
+		retiredRefreshTokensKey(sessionID),
+	}
+	args := []interface{}{
+		HashToken(refreshToken),
+		HashToken(nextRefreshToken),
+		configs.Env.JWTRefreshTokenTTL.Milliseconds(),
+		time.Now().UnixMilli(),
+		retiredRefreshTokensKept,
+	}
+	result, err := rotateRefreshTokenScript.Run(ctx, connections.RedisClient, keys, args...).Slice()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	status, _ := result[0].(int64)
+	marshaledSessionData, _ := result[1].(string)
+	if status == 0 {
+		return models.TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	var data models.UserSessionData
+	if err = json.Unmarshal([]byte(marshaledSessionData), &data); err != nil {
+		return models.TokenPair{}, err
+	}
+
+	if status < 0 {
+		if err = revokeUserSession(ctx, data.OwnerID(), sessionID); err != nil {
+			return models.TokenPair{}, err
+		}
+		return models.TokenPair{}, ErrRefreshTokenReused
+	}
+
+	// the index of the sessions of the user must outlive the session
+	if err = connections.RedisClient.Expire(ctx, userSessionsKey(data.OwnerID()), configs.Env.JWTRefreshTokenTTL).Err(); err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return newTokenPair(sessionID, nextRefreshToken, data)
+}
+
+// IssueSessionAccessToken returns an access token with the current data of the session of the request, for when the
+// data has changed under an access token that is still valid
+func IssueSessionAccessToken(c *fiber.Ctx, data models.UserSessionData) (models.TokenPair, error) {
+	sessionID, err := GetSessionID(c)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	return newTokenPair(sessionID, "", data)
+}
@@ -0,0 +1,119 @@
+package libs
+
+import (
+	"context"
+	"errors"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/valyala/fasthttp"
+)
+
+// startTestTokenSession starts a token session of a user on an in-memory Redis, with AUTH_MODE jwt
+func startTestTokenSession(t *testing.T) (models.TokenPair, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	connections.RedisClient = redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { connections.RedisClient.Close() })
+
+	configs.Env = models.EnvVar{
+		AppURL:             "http://localhost:8080",
+		AuthMode:           AuthModeJWT,
+		JWTAlgorithm:       "HS256",
+		JWTSecrets:         []string{"jwt-secret"},
+		JWTAccessTokenTTL:  15 * time.Minute,
+		JWTRefreshTokenTTL: 720 * time.Hour,
+	}
+	InitJWT()
+	t.Cleanup(func() { jwtKeys = nil })
+
+	app := fiber.New()
+	c := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(c)
+	tokens, err := StartTokenSession(c, models.UserSessionData{ID: "user-id", Email: "user@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens, server
+}
+
+func mustRefreshTokenSession(t *testing.T, refreshToken string) models.TokenPair {
+	t.Helper()
+	tokens, err := RefreshTokenSession(context.TODO(), refreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.User.ID != "user-id" {
+		t.Fatalf("refreshed the session of %q", tokens.User.ID)
+	}
+	return tokens
+}
+
+func TestRefreshTokenSessionRevokesOnReuse(t *testing.T) {
+	first, server := startTestTokenSession(t)
+	second := mustRefreshTokenSession(t, first.RefreshToken)
+	third := mustRefreshTokenSession(t, second.RefreshToken)
+
+	if _, err := RefreshTokenSession(context.TODO(), first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reusing the first refresh token: %v, want %v", err, ErrRefreshTokenReused)
+	}
+	if _, err := RefreshTokenSession(context.TODO(), third.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("refreshing the revoked session: %v, want %v", err, ErrInvalidRefreshToken)
+	}
+
+	sessionID, _, _ := strings.Cut(first.RefreshToken, ".")
+	for _, key := range server.Keys() {
+		if strings.HasSuffix(key, sessionID) {
+			t.Errorf("%s outlived the revoked session", key)
+		}
+	}
+}
+
+func TestRefreshTokenSessionRefusesUnknownTokens(t *testing.T) {
+	tokens, _ := startTestTokenSession(t)
+	tokens = mustRefreshTokenSession(t, tokens.RefreshToken)
+
+	sessionID, _, _ := strings.Cut(tokens.RefreshToken, ".")
+	for _, refreshToken := range []string{sessionID + ".guessed", sessionID + ".", "unknown." + sessionID, "", "no-dot"} {
+		if _, err := RefreshTokenSession(context.TODO(), refreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Fatalf("refreshing %q: %v, want %v", refreshToken, err, ErrInvalidRefreshToken)
+		}
+	}
+
+	// none of them touched the session
+	mustRefreshTokenSession(t, tokens.RefreshToken)
+}
+
+func TestRefreshTokenSessionKeepsFewRetiredTokens(t *testing.T) {
+	tokens, server := startTestTokenSession(t)
+	refreshTokens := []string{tokens.RefreshToken}
+	for i := 0; i < retiredRefreshTokensKept+5; i++ {
+		tokens = mustRefreshTokenSession(t, tokens.RefreshToken)
+		refreshTokens = append(refreshTokens, tokens.RefreshToken)
+	}
+
+	sessionID, _, _ := strings.Cut(tokens.RefreshToken, ".")
+	retired, err := server.ZMembers(retiredRefreshTokensKey(sessionID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retired) != retiredRefreshTokensKept {
+		t.Fatalf("%d retired refresh tokens are kept, want %d", len(retired), retiredRefreshTokensKept)
+	}
+
+	// the oldest are forgotten, so reusing them no longer revokes the session
+	if _, err = RefreshTokenSession(context.TODO(), refreshTokens[0]); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("reusing a forgotten refresh token: %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	previous := refreshTokens[len(refreshTokens)-2]
+	if _, err = RefreshTokenSession(context.TODO(), previous); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reusing the previous refresh token: %v, want %v", err, ErrRefreshTokenReused)
+	}
+}
@@ -0,0 +1,99 @@
+package libs
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	totpPeriod = 30
+	// totpSkew is how many periods before and after now a code is still accepted, for clocks that drift
+	totpSkew = 1
+
+	recoveryCodeCount = 10
+	// 32 letters and digits without look-alikes, so that each random byte maps to one without bias
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz123456789"
+)
+
+// NewTOTPKey generates a TOTP secret for accountName
+func NewTOTPKey(issuer string, accountName string) (*otp.Key, error) {
+	return totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+	})
+}
+
+// TOTPQRCode renders key as a QR code PNG data URI for authenticator apps to scan
+func TOTPQRCode(key *otp.Key) (string, error) {
+	image, err := key.Image(256, 256)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, image); err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// MatchTOTP checks code against secret around now and returns the time step it belongs to,
+// so that callers can refuse a code whose step has been used already
+func MatchTOTP(secret string, code string, now time.Time) (int64, bool) {
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	step := now.Unix() / totpPeriod
+	for i := int64(-totpSkew); i <= totpSkew; i++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix((step+i)*totpPeriod, 0), opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step + i, true
+		}
+	}
+	return 0, false
+}
+
+// NewRecoveryCodes returns one-time codes formatted as xxxxx-xxxxx, for logging in without the authenticator
+func NewRecoveryCodes() ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		random := make([]byte, 10)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		code := make([]byte, 0, 11)
+		for j, b := range random {
+			if j == 5 {
+				code = append(code, '-')
+			}
+			code = append(code, recoveryCodeAlphabet[b&31])
+		}
+		codes[i] = string(code)
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode hashes a recovery code for storage, ignoring case, spaces and dashes so that
+// codes typed as they were shown still match
+func HashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	return HashToken(normalized)
+}
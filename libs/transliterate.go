@@ -0,0 +1,208 @@
+package libs
+
+import "strings"
+
+type thaiConsonant struct {
+	initial string
+	final   string
+}
+
+// thaiConsonants follows the Royal Thai General System of Transcription
+var thaiConsonants = map[rune]thaiConsonant{
+	'ก': {"k", "k"}, 'ข': {"kh", "k"}, 'ฃ': {"kh", "k"}, 'ค': {"kh", "k"}, 'ฅ': {"kh", "k"}, 'ฆ': {"kh", "k"},
+	'ง': {"ng", "ng"}, 'จ': {"ch", "t"}, 'ฉ': {"ch", "t"}, 'ช': {"ch", "t"}, 'ซ': {"s", "t"}, 'ฌ': {"ch", "t"},
+	'ญ': {"y", "n"}, 'ฎ': {"d", "t"}, 'ฏ': {"t", "t"}, 'ฐ': {"th", "t"}, 'ฑ': {"th", "t"}, 'ฒ': {"th", "t"},
+	'ณ': {"n", "n"}, 'ด': {"d", "t"}, 'ต': {"t", "t"}, 'ถ': {"th", "t"}, 'ท': {"th", "t"}, 'ธ': {"th", "t"},
+	'น': {"n", "n"}, 'บ': {"b", "p"}, 'ป': {"p", "p"}, 'ผ': {"ph", "p"}, 'ฝ': {"f", "p"}, 'พ': {"ph", "p"},
+	'ฟ': {"f", "p"}, 'ภ': {"ph", "p"}, 'ม': {"m", "m"}, 'ย': {"y", "i"}, 'ร': {"r", "n"}, 'ล': {"l", "n"},
+	'ว': {"w", "o"}, 'ศ': {"s", "t"}, 'ษ': {"s", "t"}, 'ส': {"s", "t"}, 'ห': {"h", ""}, 'ฬ': {"l", "n"},
+	'อ': {"", ""}, 'ฮ': {"h", ""},
+}
+
+var thaiFollowingVowels = map[rune]string{
+	'ะ': "a", 'ั': "a", 'า': "a", 'ำ': "am", 'ิ': "i", 'ี': "i", 'ึ': "ue", 'ื': "ue", 'ุ': "u", 'ู': "u", '็': "o",
+}
+
+var thaiLeadingVowels = map[rune]string{
+	'เ': "e", 'แ': "ae", 'โ': "o", 'ใ': "ai", 'ไ': "ai",
+}
+
+var thaiIndependentVowels = map[rune]string{
+	'ฤ': "rue", 'ฦ': "lue",
+}
+
+const (
+	thaiThanthakhat = '์'
+	thaiDigitZero   = '๐'
+	thaiDigitNine   = '๙'
+)
+
+func isThaiToneMark(r rune) bool {
+	return r >= '่' && r <= '๋'
+}
+
+func isThaiVowel(r rune) bool {
+	_, following := thaiFollowingVowels[r]
+	_, leading := thaiLeadingVowels[r]
+	return following || leading
+}
+
+func isThaiClusterConsonant(r rune) bool {
+	return r == 'ร' || r == 'ล' || r == 'ว'
+}
+
+// reorderThaiLeadingVowels moves vowels written before their consonant (and its cluster) to after it,
+// so the text can be transcribed in pronunciation order
+func reorderThaiLeadingVowels(runes []rune) []rune {
+	result := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		_, isLeading := thaiLeadingVowels[runes[i]]
+		_, nextIsConsonant := thaiConsonants[peekRune(runes, i+1)]
+		if !isLeading || !nextIsConsonant {
+			result = append(result, runes[i])
+			continue
+		}
+
+		consonants := 1
+		cluster := peekRune(runes, i+2)
+		_, afterClusterIsConsonant := thaiConsonants[peekRune(runes, i+3)]
+		if isThaiClusterConsonant(cluster) && (afterClusterIsConsonant || peekRune(runes, i+3) == 0) {
+			consonants = 2
+		}
+		result = append(result, runes[i+1:i+1+consonants]...)
+		result = append(result, runes[i])
+		i += consonants
+	}
+	return result
+}
+
+func peekRune(runes []rune, i int) rune {
+	if i < len(runes) {
+		return runes[i]
+	}
+	return 0
+}
+
+// peekThaiRune returns the rune at i ignoring tone marks, and the index it was found at
+func peekThaiRune(runes []rune, i int) (rune, int) {
+	for i < len(runes) && isThaiToneMark(runes[i]) {
+		i++
+	}
+	return peekRune(runes, i), i
+}
+
+// leadingVowelSound transcribes a leading vowel at i combined with the marks that follow it,
+// returning the sound and the number of extra runes consumed
+func leadingVowelSound(runes []rune, i int) (string, int) {
+	next, nextIndex := peekThaiRune(runes, i+1)
+	afterNext, afterNextIndex := peekThaiRune(runes, nextIndex+1)
+
+	switch runes[i] {
+	case 'เ':
+		switch {
+		case next == 'า':
+			return "ao", nextIndex - i
+		case next == 'ี' && afterNext == 'ย':
+			return "ia", afterNextIndex - i
+		case next == 'ื' && afterNext == 'อ':
+			return "uea", afterNextIndex - i
+		case next == 'อ':
+			return "oe", nextIndex - i
+		case next == 'ะ' || next == '็':
+			return "e", nextIndex - i
+		}
+	case 'แ', 'โ':
+		if next == 'ะ' || next == '็' {
+			return thaiLeadingVowels[runes[i]], nextIndex - i
+		}
+	}
+	return thaiLeadingVowels[runes[i]], 0
+}
+
+// RomanizeThai transcribes Thai text into Latin letters. The transcription is phonetically approximate:
+// it is meant for readable identifiers such as slugs, not for linguistic use
+func RomanizeThai(text string) string {
+	const (
+		none = iota
+		initial
+		vowel
+		final
+	)
+
+	runes := reorderThaiLeadingVowels([]rune(text))
+
+	var builder strings.Builder
+	lastKind := none
+	write := func(sound string, kind int) {
+		builder.WriteString(sound)
+		lastKind = kind
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next, nextIndex := peekThaiRune(runes, i+1)
+
+		if consonant, ok := thaiConsonants[r]; ok {
+			_, nextIsConsonant := thaiConsonants[next]
+			switch {
+			case next == thaiThanthakhat:
+				// a consonant carrying a thanthakhat is silent
+				i = nextIndex
+			case lastKind == final && nextIsConsonant && peekRune(runes, nextIndex+1) == thaiThanthakhat:
+				// so is a cluster ending with one after a final consonant, as in จันทร์
+				i = nextIndex + 1
+			case r == 'อ' && lastKind == initial && !isThaiVowel(next):
+				write("o", vowel)
+			case isThaiVowel(next) || next == 'อ' && lastKind != initial:
+				write(consonant.initial, initial)
+			case r == 'ว' && lastKind == initial && nextIsConsonant:
+				// a ว between consonants is the vowel ua, as in ด้วย
+				write("ua", vowel)
+			case lastKind == initial:
+				builder.WriteString("o")
+				write(consonant.final, final)
+			case lastKind == vowel:
+				if r == 'ย' && strings.HasSuffix(builder.String(), "i") {
+					write("", final)
+				} else {
+					write(consonant.final, final)
+				}
+			default:
+				write(consonant.initial, initial)
+			}
+			continue
+		}
+
+		if sound, ok := thaiFollowingVowels[r]; ok {
+			switch {
+			case r == 'ั' && next == 'ว':
+				write("ua", vowel)
+				i = nextIndex
+			case r == 'ื' && next == 'อ':
+				write("ue", vowel)
+				i = nextIndex
+			default:
+				write(sound, vowel)
+			}
+			continue
+		}
+
+		if _, ok := thaiLeadingVowels[r]; ok {
+			sound, consumed := leadingVowelSound(runes, i)
+			write(sound, vowel)
+			i += consumed
+			continue
+		}
+
+		switch {
+		case thaiIndependentVowels[r] != "":
+			write(thaiIndependentVowels[r], vowel)
+		case r >= thaiDigitZero && r <= thaiDigitNine:
+			write(string('0'+(r-thaiDigitZero)), none)
+		case isThaiToneMark(r) || r == thaiThanthakhat:
+		default:
+			write(string(r), none)
+		}
+	}
+	return builder.String()
+}
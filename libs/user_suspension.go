@@ -0,0 +1,29 @@
+package libs
+
+import (
+	"context"
+	"fmt"
+	"go_blogs/connections"
+)
+
+// userSuspendedKey flags a suspended user, so that requests are refused without reading the user from MongoDB
+func userSuspendedKey(userID string) string {
+	return fmt.Sprintf("user_suspended:%s", userID)
+}
+
+// SuspendUser flags a user as suspended and logs them out of every session
+func SuspendUser(ctx context.Context, userID string) error {
+	if err := connections.RedisClient.Set(ctx, userSuspendedKey(userID), 1, 0).Err(); err != nil {
+		return err
+	}
+	return RevokeUserSessions(ctx, userID, "")
+}
+
+func UnsuspendUser(ctx context.Context, userID string) error {
+	return connections.RedisClient.Del(ctx, userSuspendedKey(userID)).Err()
+}
+
+func IsUserSuspended(ctx context.Context, userID string) (bool, error) {
+	count, err := connections.RedisClient.Exists(ctx, userSuspendedKey(userID)).Result()
+	return count > 0, err
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"go_blogs/configs"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Mailer delivers email messages
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+var Default Mailer
+
+func InitMailer() {
+	switch configs.Env.Mailer {
+	case "", "log":
+		Default = LogMailer{}
+	case "smtp":
+		Default = NewSMTPMailer(
+			configs.Env.SMTPHost,
+			configs.Env.SMTPPort,
+			configs.Env.SMTPUsername,
+			configs.Env.SMTPPassword,
+			configs.Env.MailFrom,
+		)
+	case "outbox":
+		Default = NewOutbox(configs.Env.MailOutboxDir, configs.Env.MailFrom)
+	default:
+		panic(fmt.Sprintf("unknown mailer %q", configs.Env.Mailer))
+	}
+}
+
+// LogMailer prints messages instead of sending them, for local development
+type LogMailer struct{}
+
+func (LogMailer) Send(_ context.Context, message Message) error {
+	fmt.Printf("Mail to %s: %s\n%s\n", message.To, message.Subject, message.Text)
+	return nil
+}
@@ -0,0 +1,76 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outbox keeps sent messages in memory instead of delivering them, for local development and tests.
+// When dir is set, each message is also written there as an .eml file
+type Outbox struct {
+	mu       sync.Mutex
+	dir      string
+	from     string
+	messages []Message
+}
+
+func NewOutbox(dir string, from string) *Outbox {
+	return &Outbox{dir: dir, from: from}
+}
+
+func (o *Outbox) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.dir != "" {
+		if err := os.MkdirAll(o.dir, 0o755); err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), sanitizeFileName(message.To))
+		if err := os.WriteFile(filepath.Join(o.dir, name), formatMessage(o.from, message), 0o644); err != nil {
+			return err
+		}
+	}
+
+	o.messages = append(o.messages, message)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first
+func (o *Outbox) Messages() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]Message(nil), o.messages...)
+}
+
+// Last returns the latest message sent to address
+func (o *Outbox) Last(address string) (Message, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i := len(o.messages) - 1; i >= 0; i-- {
+		if strings.EqualFold(o.messages[i].To, address) {
+			return o.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, name)
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"go_blogs/configs"
 	"go_blogs/connections"
+	"go_blogs/jobs"
+	"go_blogs/libs"
+	"go_blogs/mailer"
 	"go_blogs/models"
 	"go_blogs/routes"
+	"go_blogs/search"
 
 	"github.com/goccy/go-json"
 	"github.com/gofiber/fiber/v2"
@@ -22,6 +27,10 @@ func main() {
 
 	connections.InitDatabaseConnection()
 
+	mailer.InitMailer()
+
+	libs.InitJWT()
+
 	app := fiber.New(fiber.Config{
 		AppName:     "Go Blogs",
 		JSONEncoder: json.Marshal,
@@ -34,13 +43,31 @@ func main() {
 		},
 	})
 
+	database := connections.MongoClient.Database(configs.Env.MongoDatabase)
+
+	searchBackend, err := search.NewBackend(configs.Env.SearchBackend)
+	if err != nil {
+		panic(err)
+	}
+	search.InitBlogIndex(searchBackend, database.Collection("blogs"), connections.RedisClient)
+
 	routes.InitRoute(app)
 
+	scheduler := jobs.NewScheduler(
+		connections.RedisClient,
+		libs.SystemClock{},
+		configs.Env.SchedulerInterval,
+		jobs.NewBlogScheduleJob(database.Collection("blogs"), search.Blogs),
+		jobs.NewBlogPurgeJob(database.Collection("blogs"), database.Collection("blog_revisions"), configs.Env.TrashRetention),
+		jobs.NewSessionCleanupJob(connections.RedisClient),
+	)
+	go scheduler.Start(context.Background())
+
 	if configs.Env.AppEnv != "production" {
 		app.Get("/swagger/*", swagger.HandlerDefault)
 	}
 
-	err := app.Listen(fmt.Sprintf(":%d", configs.Env.Port))
+	err = app.Listen(fmt.Sprintf(":%d", configs.Env.Port))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"go_blogs/libs"
+	"go_blogs/models"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuthorizeScope authorizes the user like AuthorizeUser, and also accepts an Authorization: Bearer personal access
+// token granting scope. Routes behind AuthorizeUser alone cannot be used with personal access tokens
+func AuthorizeScope(scope models.AccessTokenScope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !libs.HasAccessToken(c) {
+			return AuthorizeUser(c)
+		}
+
+		userData, err := libs.GetRequestUserData(c, scope)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusUnauthorized).JSON(models.ErrorResponse{
+				Message: "Access token is invalid or has expired",
+			})
+		} else if errors.Is(err, libs.ErrAccessTokenScope) {
+			return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+				Message: fmt.Sprintf("Access token does not have the %s scope", scope),
+			})
+		} else if err != nil {
+			return err
+		}
+
+		c.Locals("user", userData)
+
+		return c.Next()
+	}
+}
@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"context"
+	"fmt"
 	"go_blogs/libs"
 	"go_blogs/models"
 
@@ -15,6 +17,32 @@ func AuthorizeUser(c *fiber.Ctx) error {
 		})
 	}
 
+	// suspending a user revokes their sessions, and this catches any started while that happened
+	suspended, err := libs.IsUserSuspended(context.TODO(), userData.ID)
+	if err != nil {
+		return err
+	}
+	// an impersonation lasts only as long as the admin behind it may act
+	if !suspended && userData.Impersonator != nil {
+		suspended, err = libs.IsUserSuspended(context.TODO(), userData.Impersonator.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if suspended {
+		if err = libs.DestroyUserSessionData(c); err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Message: "Account is suspended",
+		})
+	}
+
+	// the last-seen time is informational, so failing to record it does not fail the request
+	if err = libs.TouchUserSession(c); err != nil {
+		fmt.Println("AuthorizeUser:", err.Error())
+	}
+
 	c.Locals("user", userData)
 
 	return c.Next()
@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"context"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/libs"
+	"go_blogs/models"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RequireVerifiedEmail rejects users whose email is not verified when REQUIRE_VERIFIED_EMAIL_TO_POST is set.
+// It must run after AuthorizeUser
+func RequireVerifiedEmail(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.UserSessionData)
+	if !configs.Env.RequireVerifiedEmailToPost || user.EmailVerified {
+		return c.Next()
+	}
+
+	// sessions created before the email was verified elsewhere may be stale, so the user is checked once more
+	verified, err := isEmailVerified(user.ID)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Message: "Email is not verified. Please open the verification link sent to your email",
+		})
+	}
+
+	updated := *user
+	updated.EmailVerified = true
+	if err = libs.UpdateUserSessionsData(context.TODO(), updated); err != nil {
+		return err
+	}
+	c.Locals("user", &updated)
+
+	return c.Next()
+}
+
+func isEmailVerified(userID string) (bool, error) {
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, err
+	}
+	userColl := connections.MongoClient.Database(configs.Env.MongoDatabase).Collection("users")
+	filter := bson.M{
+		"_id":           userObjectID,
+		"emailVerified": true,
+	}
+	count, err := userColl.CountDocuments(context.TODO(), filter, options.Count().SetLimit(1))
+	return count > 0, err
+}
@@ -0,0 +1,19 @@
+package middlewares
+
+import (
+	"go_blogs/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ForbidImpersonation rejects sessions impersonating a user, for actions only the user themself may take.
+// It must run after AuthorizeUser
+func ForbidImpersonation(c *fiber.Ctx) error {
+	user := c.Locals("user").(*models.UserSessionData)
+	if user.Impersonator != nil {
+		return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+			Message: "Not allowed while impersonating a user",
+		})
+	}
+	return c.Next()
+}
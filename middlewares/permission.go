@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"go_blogs/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission rejects users who lack any of permissions. It must run after AuthorizeUser
+func RequirePermission(permissions ...models.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := c.Locals("user").(*models.UserSessionData)
+		for _, permission := range permissions {
+			if !user.Can(permission) {
+				return c.Status(fiber.StatusForbidden).JSON(models.ErrorResponse{
+					Message: "Access Denied",
+				})
+			}
+		}
+		return c.Next()
+	}
+}
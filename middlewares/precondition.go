@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"go_blogs/libs"
+	"go_blogs/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireIfMatch rejects requests without a valid If-Match header and stores the expected version in locals
+func RequireIfMatch(c *fiber.Ctx) error {
+	ifMatch := c.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(models.ErrorResponse{
+			Message: "If-Match header is required",
+		})
+	}
+
+	version, err := libs.ParseETag(ifMatch)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(models.ErrorResponse{
+			Message: "If-Match header must be a blog version ETag",
+		})
+	}
+
+	c.Locals("ifMatch", version)
+
+	return c.Next()
+}
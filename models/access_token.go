@@ -0,0 +1,50 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// AccessTokenScope limits what a personal access token may be used for
+type AccessTokenScope string
+
+const (
+	// AccessTokenScopeBlogsRead allows reading the user's own blogs, including unpublished ones and their revisions
+	AccessTokenScopeBlogsRead AccessTokenScope = "blogs:read"
+	// AccessTokenScopeBlogsWrite allows creating, editing, publishing and trashing blogs
+	AccessTokenScopeBlogsWrite AccessTokenScope = "blogs:write"
+	// AccessTokenScopeCategoriesWrite allows managing categories, for users with the permission to
+	AccessTokenScopeCategoriesWrite AccessTokenScope = "categories:write"
+)
+
+// AccessToken is a personal access token that API clients authenticate as its user with. Only its hash is stored
+type AccessToken struct {
+	ID         string              `bson:"_id" json:"_id"`
+	UserID     string              `json:"userId"`
+	Name       string              `json:"name"`
+	TokenHash  string              `json:"-"`
+	Hint       string              `json:"hint" example:"gb_pat_...x9Qa"`
+	Scopes     []AccessTokenScope  `json:"scopes"`
+	ExpiresAt  *primitive.DateTime `json:"expiresAt,omitempty" swaggertype:"string"`
+	LastUsedAt *primitive.DateTime `json:"lastUsedAt,omitempty" swaggertype:"string"`
+	CreatedAt  primitive.DateTime  `json:"createdAt" swaggertype:"string"`
+}
+
+// CreatedAccessToken is returned once, when a token is created, as the token cannot be recovered later
+type CreatedAccessToken struct {
+	AccessToken
+	Token string `json:"token"`
+}
+
+// AccessTokenGrant is what the personal access token a request is authenticated with grants
+type AccessTokenGrant struct {
+	ID     string
+	Scopes []AccessTokenScope
+}
+
+// Allows reports whether the token grants scope
+func (g *AccessTokenGrant) Allows(scope AccessTokenScope) bool {
+	for _, s := range g.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
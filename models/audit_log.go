@@ -0,0 +1,21 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type AuditAction string
+
+const (
+	AuditActionImpersonationStart AuditAction = "impersonation.start"
+	AuditActionImpersonationStop  AuditAction = "impersonation.stop"
+)
+
+// AuditLog records an admin acting on a user
+type AuditLog struct {
+	ID        string             `bson:"_id" json:"_id"`
+	Action    AuditAction        `json:"action"`
+	ActorID   string             `json:"actorId"`
+	TargetID  string             `json:"targetId"`
+	IP        string             `json:"ip"`
+	UserAgent string             `json:"userAgent"`
+	CreatedAt primitive.DateTime `json:"createdAt" swaggertype:"string"`
+}
@@ -2,10 +2,51 @@ package models
 
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
+type BlogStatus string
+
+const (
+	BlogStatusDraft     BlogStatus = "draft"
+	BlogStatusInReview  BlogStatus = "in_review"
+	BlogStatusPublished BlogStatus = "published"
+	BlogStatusArchived  BlogStatus = "archived"
+)
+
+// blogStatusTransitions lists the statuses a blog may move to from each status
+var blogStatusTransitions = map[BlogStatus][]BlogStatus{
+	BlogStatusDraft:     {BlogStatusInReview, BlogStatusPublished, BlogStatusArchived},
+	BlogStatusInReview:  {BlogStatusDraft, BlogStatusPublished, BlogStatusArchived},
+	BlogStatusPublished: {BlogStatusDraft, BlogStatusArchived},
+	BlogStatusArchived:  {BlogStatusDraft},
+}
+
+func (s BlogStatus) CanTransitionTo(next BlogStatus) bool {
+	for _, allowed := range blogStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type Blog struct {
-	ID        string             `bson:"_id"`
-	Title     string             `json:"title"`
-	Content   string             `json:"content"`
-	CreatedBy string             `json:"createdBy"`
-	CreatedAt primitive.DateTime `json:"createdAt" swaggertype:"string"`
+	ID          string              `bson:"_id"`
+	Title       string              `json:"title"`
+	Content     string              `json:"content"`
+	Slug        string              `json:"slug"`
+	CustomSlug  bool                `json:"customSlug"`
+	SlugHistory []string            `json:"-"`
+	Tags        []string            `json:"tags"`
+	CategoryID  string              `json:"categoryId,omitempty"`
+	Status      BlogStatus          `json:"status"`
+	Revision    int                 `json:"revision"`
+	Version     int                 `json:"version"`
+	CreatedBy   string              `json:"createdBy"`
+	Author      *PublicUser         `json:"author,omitempty" bson:"-"`
+	CreatedAt   primitive.DateTime  `json:"createdAt" swaggertype:"string"`
+	PublishedAt *primitive.DateTime `json:"publishedAt,omitempty" swaggertype:"string"`
+	ArchivedAt  *primitive.DateTime `json:"archivedAt,omitempty" swaggertype:"string"`
+	PublishAt   *primitive.DateTime `json:"publishAt,omitempty" swaggertype:"string"`
+	UnpublishAt *primitive.DateTime `json:"unpublishAt,omitempty" swaggertype:"string"`
+	DeletedAt   *primitive.DateTime `json:"deletedAt,omitempty" swaggertype:"string"`
+	DeletedBy   string              `json:"deletedBy,omitempty"`
 }
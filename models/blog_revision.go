@@ -0,0 +1,36 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type BlogRevision struct {
+	ID           string             `bson:"_id"`
+	BlogID       string             `json:"blogId"`
+	Revision     int                `json:"revision"`
+	Title        string             `json:"title"`
+	Content      string             `json:"content,omitempty"`
+	CreatedBy    string             `json:"createdBy"`
+	CreatedAt    primitive.DateTime `json:"createdAt" swaggertype:"string"`
+	RestoredFrom int                `json:"restoredFrom,omitempty"`
+}
+
+type DiffOp string
+
+const (
+	DiffOpEqual  DiffOp = "equal"
+	DiffOpInsert DiffOp = "insert"
+	DiffOpDelete DiffOp = "delete"
+)
+
+type DiffLine struct {
+	Op      DiffOp `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
+
+type BlogRevisionDiff struct {
+	From    int        `json:"from"`
+	To      int        `json:"to"`
+	Title   []DiffLine `json:"title"`
+	Content []DiffLine `json:"content"`
+}
@@ -0,0 +1,9 @@
+package models
+
+type BlogSearchHit struct {
+	Blog  Blog    `json:"blog"`
+	Score float64 `json:"score"`
+	// Title and Snippet are HTML escaped, with matched words wrapped in <mark>
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
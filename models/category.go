@@ -0,0 +1,26 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type Category struct {
+	ID        string             `bson:"_id"`
+	Name      string             `json:"name"`
+	Slug      string             `json:"slug"`
+	ParentID  string             `json:"parentId,omitempty"`
+	CreatedAt primitive.DateTime `json:"createdAt" swaggertype:"string"`
+}
+
+type CategoryNode struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Slug           string         `json:"slug"`
+	ParentID       string         `json:"parentId,omitempty"`
+	BlogCount      int64          `json:"blogCount"`
+	TotalBlogCount int64          `json:"totalBlogCount"`
+	Children       []CategoryNode `json:"children"`
+}
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
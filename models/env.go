@@ -1,9 +1,57 @@
 package models
 
+import "time"
+
 type EnvVar struct {
 	AppEnv string
+	AppURL string
 	Port   int
 
+	SchedulerInterval time.Duration
+	TrashRetention    time.Duration
+
+	SearchBackend string
+
+	CursorSecret string
+
+	Mailer        string
+	MailFrom      string
+	MailOutboxDir string
+	SMTPHost      string
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
+
+	EmailTokenSecret            string
+	EmailVerificationTTL        time.Duration
+	RequireVerifiedEmailToLogin bool
+	RequireVerifiedEmailToPost  bool
+	PasswordResetTTL            time.Duration
+
+	LoginFailureWindow      time.Duration
+	LoginBackoffAfter       int64
+	LoginBackoffBase        time.Duration
+	LoginBackoffMax         time.Duration
+	LoginLockoutThreshold   int64
+	LoginIPLockoutThreshold int64
+	LoginLockoutDuration    time.Duration
+
+	SecretEncryptionKey string
+	TOTPIssuer          string
+
+	WebAuthnRPID      string
+	WebAuthnRPName    string
+	WebAuthnRPOrigins []string
+
+	BootstrapAdminEmail string
+
+	AuthMode           string
+	JWTAlgorithm       string
+	JWTSecrets         []string
+	JWTKeyFiles        []string
+	JWTAccessTokenTTL  time.Duration
+	JWTRefreshTokenTTL time.Duration
+
 	MongoEndpoint string
 	MongoUsername string
 	MongoPassword string
@@ -14,3 +14,34 @@ type ValidationErrorResponse struct {
 type SuccessResponse struct {
 	Message string `json:"message"`
 }
+
+type CreatedResponse struct {
+	Message string `json:"message"`
+	ID      string `json:"id"`
+}
+
+type PreconditionFailedResponse struct {
+	Message        string `json:"message"`
+	CurrentVersion int    `json:"currentVersion"`
+}
+
+// PaginationLinks are the URLs of a page and the pages around it, relative to the API host
+type PaginationLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// PaginatedResponse is a page of a list. Total is a lower bound when TotalIsEstimate is set.
+// Next and Prev are the cursors of the neighbouring pages on lists paginated by cursor
+type PaginatedResponse[T any] struct {
+	Items           []T             `json:"items"`
+	Total           int64           `json:"total"`
+	TotalIsEstimate bool            `json:"totalIsEstimate,omitempty"`
+	Limit           int             `json:"limit"`
+	Next            string          `json:"next,omitempty"`
+	Prev            string          `json:"prev,omitempty"`
+	Links           PaginationLinks `json:"links"`
+}
@@ -0,0 +1,79 @@
+package models
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleEditor    Role = "editor"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission string
+
+const (
+	// PermissionViewAnyBlog allows reading unpublished blogs of other users
+	PermissionViewAnyBlog Permission = "blogs:view_any"
+	// PermissionEditAnyBlog allows editing blogs of other users and restoring their revisions
+	PermissionEditAnyBlog Permission = "blogs:edit_any"
+	// PermissionPublishAnyBlog allows changing the status of blogs of other users
+	PermissionPublishAnyBlog Permission = "blogs:publish_any"
+	// PermissionDeleteAnyBlog allows trashing and restoring blogs of other users
+	PermissionDeleteAnyBlog Permission = "blogs:delete_any"
+	// PermissionManageCategories allows creating, updating and deleting categories
+	PermissionManageCategories Permission = "categories:manage"
+	// PermissionManageRoles allows assigning roles and permissions to users
+	PermissionManageRoles Permission = "users:manage_roles"
+	// PermissionManageUsers allows listing, suspending and forcing password resets of users
+	PermissionManageUsers Permission = "users:manage"
+	// PermissionImpersonateUsers allows acting as users who have no permission the impersonator lacks
+	PermissionImpersonateUsers Permission = "users:impersonate"
+)
+
+// Permissions lists every permission, for validating grants
+var Permissions = []Permission{
+	PermissionViewAnyBlog,
+	PermissionEditAnyBlog,
+	PermissionPublishAnyBlog,
+	PermissionDeleteAnyBlog,
+	PermissionManageCategories,
+	PermissionManageRoles,
+	PermissionManageUsers,
+	PermissionImpersonateUsers,
+}
+
+// rolePermissions lists the permissions each role grants. Every user may manage their own blogs without any
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {},
+	RoleEditor: {
+		PermissionViewAnyBlog,
+		PermissionEditAnyBlog,
+		PermissionPublishAnyBlog,
+		PermissionManageCategories,
+	},
+	RoleModerator: {
+		PermissionViewAnyBlog,
+		PermissionPublishAnyBlog,
+		PermissionDeleteAnyBlog,
+	},
+	RoleAdmin: Permissions,
+}
+
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permissions returns the permissions r grants. Unknown roles, including none, grant those of RoleUser
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+func (p Permission) IsValid() bool {
+	for _, permission := range Permissions {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// UserSession is a device a user is logged in on
+type UserSession struct {
+	ID         string     `json:"id"`
+	UserAgent  string     `json:"userAgent"`
+	IP         string     `json:"ip"`
+	Current    bool       `json:"current"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
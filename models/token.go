@@ -0,0 +1,27 @@
+package models
+
+// TokenPair is what logging in returns instead of setting a session cookie when AUTH_MODE is jwt
+type TokenPair struct {
+	AccessToken string `json:"accessToken"`
+	// RefreshToken is only returned when a session starts or is refreshed
+	RefreshToken string          `json:"refreshToken,omitempty"`
+	TokenType    string          `json:"tokenType" example:"Bearer"`
+	ExpiresIn    int             `json:"expiresIn"`
+	User         UserSessionData `json:"user"`
+}
+
+// JWK is a public key access tokens are verified with, as in RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
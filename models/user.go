@@ -1,14 +1,227 @@
 package models
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 type User struct {
-	ID       string `bson:"_id"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
+	ID              string              `bson:"_id"`
+	Email           string              `json:"email"`
+	EmailVerified   bool                `json:"emailVerified"`
+	EmailVerifiedAt *primitive.DateTime `json:"emailVerifiedAt,omitempty" swaggertype:"string"`
+	Password        string              `json:"password"`
+	Name            string              `json:"name"`
+	Handle          string              `json:"handle"`
+	Bio             string              `json:"bio"`
+	AvatarURL       string              `json:"avatarUrl"`
+	Role            Role                `json:"role"`
+	Permissions     []Permission        `json:"permissions"`
+	CreatedAt       primitive.DateTime  `json:"createdAt" swaggertype:"string"`
+	TOTP            *UserTOTP           `json:"-" bson:"totp,omitempty"`
+
+	SuspendedAt           *primitive.DateTime `json:"suspendedAt,omitempty" swaggertype:"string"`
+	SuspensionReason      string              `json:"suspensionReason,omitempty"`
+	PasswordResetRequired bool                `json:"passwordResetRequired"`
+}
+
+// UserTOTP is the TOTP two-factor authentication of a user. Secret is sealed with libs.SealSecret
+// and recovery codes are kept as hashes
+type UserTOTP struct {
+	Secret        string              `bson:"secret"`
+	Enabled       bool                `bson:"enabled"`
+	EnabledAt     *primitive.DateTime `bson:"enabledAt,omitempty"`
+	LastUsedStep  int64               `bson:"lastUsedStep"`
+	RecoveryCodes []string            `bson:"recoveryCodes"`
+}
+
+// EffectivePermissions returns the permissions of the role of u together with those granted to u directly
+func (u *User) EffectivePermissions() []Permission {
+	permissions := append([]Permission{}, u.Role.Permissions()...)
+	for _, granted := range u.Permissions {
+		if !hasPermission(permissions, granted) {
+			permissions = append(permissions, granted)
+		}
+	}
+	return permissions
+}
+
+// LoginBlockedReason tells why the user may not log in whatever factors they pass, or is empty when they may
+func (u *User) LoginBlockedReason() string {
+	if u.SuspendedAt != nil {
+		return "Account is suspended"
+	}
+	if u.PasswordResetRequired {
+		return "Password must be reset. Please open the link sent to your email"
+	}
+	return ""
+}
+
+// TwoFactorEnabled reports whether logging in as the user takes a second factor
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTP != nil && u.TOTP.Enabled
+}
+
+// PublicUser is what other users may see of a user
+type PublicUser struct {
+	ID        string `bson:"_id" json:"_id"`
+	Name      string `json:"name"`
+	Handle    string `json:"handle"`
+	AvatarURL string `json:"avatarUrl,omitempty"`
+}
+
+// UserProfile is the public profile of a user
+type UserProfile struct {
+	ID        string             `json:"_id"`
+	Name      string             `json:"name"`
+	Handle    string             `json:"handle"`
+	Bio       string             `json:"bio"`
+	AvatarURL string             `json:"avatarUrl"`
+	JoinedAt  primitive.DateTime `json:"joinedAt" swaggertype:"string"`
+	PostCount int64              `json:"postCount"`
+}
+
+// AdminUser is what admins see of a user
+type AdminUser struct {
+	ID                    string              `json:"_id"`
+	Email                 string              `json:"email"`
+	EmailVerified         bool                `json:"emailVerified"`
+	Name                  string              `json:"name"`
+	Handle                string              `json:"handle"`
+	Role                  Role                `json:"role"`
+	Permissions           []Permission        `json:"permissions"`
+	TwoFactorEnabled      bool                `json:"twoFactorEnabled"`
+	SuspendedAt           *primitive.DateTime `json:"suspendedAt,omitempty" swaggertype:"string"`
+	SuspensionReason      string              `json:"suspensionReason,omitempty"`
+	PasswordResetRequired bool                `json:"passwordResetRequired"`
+	CreatedAt             primitive.DateTime  `json:"createdAt" swaggertype:"string"`
+}
+
+func NewAdminUser(user *User) AdminUser {
+	return AdminUser{
+		ID:                    user.ID,
+		Email:                 user.Email,
+		EmailVerified:         user.EmailVerified,
+		Name:                  user.Name,
+		Handle:                user.Handle,
+		Role:                  user.Role,
+		Permissions:           user.Permissions,
+		TwoFactorEnabled:      user.TwoFactorEnabled(),
+		SuspendedAt:           user.SuspendedAt,
+		SuspensionReason:      user.SuspensionReason,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             user.CreatedAt,
+	}
+}
+
+// UserStats is the activity of a user, for admins
+type UserStats struct {
+	UserID       string               `json:"userId"`
+	Blogs        map[BlogStatus]int64 `json:"blogs"`
+	TrashedBlogs int64                `json:"trashedBlogs"`
+	Passkeys     int64                `json:"passkeys"`
+	Sessions     int                  `json:"sessions"`
+	LastSeenAt   *time.Time           `json:"lastSeenAt,omitempty"`
 }
 
 type UserSessionData struct {
-	ID    string `json:"_id"`
-	Email string `json:"email"`
-	Name  string `json:"name"`
+	ID            string       `json:"_id"`
+	Email         string       `json:"email"`
+	EmailVerified bool         `json:"emailVerified"`
+	Name          string       `json:"name"`
+	Role          Role         `json:"role"`
+	Permissions   []Permission `json:"permissions"`
+	// Impersonator is set while an admin acts as the user in the session
+	Impersonator *Impersonator `json:"impersonator,omitempty"`
+	// AccessToken is set when the request is authenticated with a personal access token rather than a session
+	AccessToken *AccessTokenGrant `json:"-"`
+}
+
+// Impersonator is the admin behind a session impersonating a user
+type Impersonator struct {
+	ID        string    `json:"_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// NewUserSessionData returns the session data of user, which must be rewritten whenever any of it changes
+func NewUserSessionData(user *User) UserSessionData {
+	return UserSessionData{
+		ID:            user.ID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Name:          user.Name,
+		Role:          user.Role,
+		Permissions:   user.EffectivePermissions(),
+	}
+}
+
+// OwnerID returns the ID of the user who logged in to the session, who is the impersonator while impersonating
+func (u *UserSessionData) OwnerID() string {
+	if u.Impersonator != nil {
+		return u.Impersonator.ID
+	}
+	return u.ID
+}
+
+// Can reports whether the user has permission. A nil user, who is not logged in, has none
+func (u *UserSessionData) Can(permission Permission) bool {
+	return u != nil && hasPermission(u.Permissions, permission)
+}
+
+// CanAll reports whether the user has every one of permissions
+func (u *UserSessionData) CanAll(permissions []Permission) bool {
+	for _, permission := range permissions {
+		if !u.Can(permission) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasPermission(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// TOTPEnrolment is what an authenticator app needs to generate codes for a user
+type TOTPEnrolment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode string `json:"qrCode" example:"data:image/png;base64,iVBORw0KGgo..."`
+}
+
+// RecoveryCodes are shown once, when two-factor authentication is enabled
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// LoginChallenge is returned by Login instead of a session when the user has two-factor authentication
+type LoginChallenge struct {
+	Challenge string `json:"challenge"`
+	ExpiresIn int    `json:"expiresIn"`
+}
+
+// Passkey is a WebAuthn credential a user logs in with
+type Passkey struct {
+	ID              string              `bson:"_id" json:"_id"`
+	UserID          string              `json:"-"`
+	Name            string              `json:"name"`
+	CredentialID    []byte              `json:"-"`
+	PublicKey       []byte              `json:"-"`
+	AttestationType string              `json:"-"`
+	Transports      []string            `json:"transports"`
+	AAGUID          []byte              `json:"-"`
+	SignCount       uint32              `json:"-"`
+	BackupEligible  bool                `json:"backupEligible"`
+	BackupState     bool                `json:"backupState"`
+	CloneWarning    bool                `json:"cloneWarning"`
+	CreatedAt       primitive.DateTime  `json:"createdAt" swaggertype:"string"`
+	LastUsedAt      *primitive.DateTime `json:"lastUsedAt,omitempty" swaggertype:"string"`
 }
@@ -0,0 +1,39 @@
+// Package policies decides what a user may do with a resource. Each policy takes the user of the request,
+// nil when nobody is logged in, and the resource loaded with at least the fields the policy reads
+package policies
+
+import "go_blogs/models"
+
+func isAuthor(user *models.UserSessionData, blog *models.Blog) bool {
+	return user != nil && user.ID == blog.CreatedBy
+}
+
+// CanViewBlog reports whether user may read blog. Published blogs are public. Reads status and createdBy
+func CanViewBlog(user *models.UserSessionData, blog *models.Blog) bool {
+	return blog.Status == models.BlogStatusPublished || isAuthor(user, blog) || user.Can(models.PermissionViewAnyBlog)
+}
+
+// CanEditBlog reports whether user may change the content of blog, including through its revisions. Reads createdBy
+func CanEditBlog(user *models.UserSessionData, blog *models.Blog) bool {
+	return isAuthor(user, blog) || user.Can(models.PermissionEditAnyBlog)
+}
+
+// CanChangeBlogStatus reports whether user may move blog to next, be it submitting, publishing, unpublishing or
+// archiving it. Reads createdBy
+func CanChangeBlogStatus(user *models.UserSessionData, blog *models.Blog, next models.BlogStatus) bool {
+	if next == models.BlogStatusPublished {
+		return CanPublishBlog(user, blog)
+	}
+	return isAuthor(user, blog) || user.Can(models.PermissionPublishAnyBlog)
+}
+
+// CanPublishBlog reports whether user may publish blog, right away or by scheduling it. Authors cannot publish
+// their own blogs without PermissionPublishAnyBlog, so that blogs go through review. Reads nothing
+func CanPublishBlog(user *models.UserSessionData, blog *models.Blog) bool {
+	return user.Can(models.PermissionPublishAnyBlog)
+}
+
+// CanDeleteBlog reports whether user may move blog to the trash and back. Reads createdBy
+func CanDeleteBlog(user *models.UserSessionData, blog *models.Blog) bool {
+	return isAuthor(user, blog) || user.Can(models.PermissionDeleteAnyBlog)
+}
@@ -0,0 +1,51 @@
+package routes_test
+
+import (
+	"go_blogs/models"
+	"net/http"
+	"testing"
+)
+
+// createAccessToken creates a personal access token of the user logged in with cookies, granting scopes
+func (app *testApp) createAccessToken(cookies []*http.Cookie, scopes ...models.AccessTokenScope) string {
+	app.t.Helper()
+	var created models.CreatedAccessToken
+	app.mustDo(http.StatusCreated, testRequest{
+		method:  http.MethodPost,
+		path:    "/api/me/tokens",
+		body:    map[string]interface{}{"name": "CI", "scopes": scopes},
+		cookies: cookies,
+	}).decode(app.t, &created)
+	return created.Token
+}
+
+func TestAccessTokenReadsOwnDrafts(t *testing.T) {
+	app := newTestApp(t, nil)
+	author := app.createUser(models.RoleUser)
+	cookies := app.loginCookies(author)
+
+	var created models.CreatedResponse
+	app.mustDo(http.StatusCreated, testRequest{
+		method:  http.MethodPost,
+		path:    "/api/blogs",
+		body:    map[string]string{"title": "A draft read by CI", "content": "content"},
+		cookies: cookies,
+	}).decode(t, &created)
+	var blog models.Blog
+	app.mustDo(http.StatusOK, testRequest{method: http.MethodGet, path: "/api/blogs/" + created.ID, cookies: cookies}).decode(t, &blog)
+
+	readToken := app.createAccessToken(cookies, models.AccessTokenScopeBlogsRead)
+	writeToken := app.createAccessToken(cookies, models.AccessTokenScopeBlogsWrite)
+	strangerToken := app.createAccessToken(app.loginCookies(app.createUser(models.RoleUser)), models.AccessTokenScopeBlogsRead)
+
+	for _, path := range []string{"/api/blogs/" + created.ID, "/api/blogs/by-slug/" + blog.Slug} {
+		if resp := app.do(bearerRequest(http.MethodGet, path, readToken)); resp.StatusCode != http.StatusOK {
+			t.Errorf("%s with a blogs:read token of the author: %d %s", path, resp.StatusCode, resp.body)
+		}
+		for name, token := range map[string]string{"a blogs:write token of the author": writeToken, "a token of another user": strangerToken} {
+			if resp := app.do(bearerRequest(http.MethodGet, path, token)); resp.StatusCode != http.StatusNotFound {
+				t.Errorf("%s with %s: %d %s, want 404", path, name, resp.StatusCode, resp.body)
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package routes_test
+
+import (
+	"context"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"net/http"
+	"testing"
+)
+
+// newJWTTestApp builds the test app with AUTH_MODE jwt
+func newJWTTestApp(t *testing.T) *testApp {
+	return newTestApp(t, func(env *models.EnvVar) {
+		env.AuthMode = libs.AuthModeJWT
+	})
+}
+
+// tokenLogin logs user in and returns their token pair
+func (app *testApp) tokenLogin(user testUser) models.TokenPair {
+	app.t.Helper()
+	resp := app.login(user.Email, testPassword)
+	if resp.StatusCode != http.StatusOK {
+		app.t.Fatalf("login as %s: %d %s", user.Email, resp.StatusCode, resp.body)
+	}
+	var tokens models.TokenPair
+	resp.decode(app.t, &tokens)
+	return tokens
+}
+
+// bearerRequest is a request with accessToken as its bearer token
+func bearerRequest(method string, path string, accessToken string) testRequest {
+	return testRequest{
+		method:  method,
+		path:    path,
+		headers: map[string]string{"Authorization": "Bearer " + accessToken},
+	}
+}
+
+// impersonate has the admin holding adminToken impersonate user and returns the token pair of the impersonation
+func (app *testApp) impersonate(adminToken string, user testUser) models.TokenPair {
+	app.t.Helper()
+	resp := app.do(bearerRequest(http.MethodPost, "/api/admin/users/"+user.ID+"/impersonate", adminToken))
+	if resp.StatusCode != http.StatusOK {
+		app.t.Fatalf("impersonate %s: %d %s", user.Email, resp.StatusCode, resp.body)
+	}
+	var tokens models.TokenPair
+	resp.decode(app.t, &tokens)
+	if tokens.User.Impersonator == nil {
+		app.t.Fatalf("impersonation token of %s has no impersonator", user.Email)
+	}
+	return tokens
+}
+
+func TestStopImpersonationRevokesImpersonationAccessToken(t *testing.T) {
+	app := newJWTTestApp(t)
+	admin := app.createUser(models.RoleAdmin)
+	user := app.createUser(models.RoleUser)
+
+	impersonation := app.impersonate(app.tokenLogin(admin).AccessToken, user)
+	if resp := app.do(bearerRequest(http.MethodGet, "/api/me/sessions", impersonation.AccessToken)); resp.StatusCode != http.StatusOK {
+		t.Fatalf("impersonating: %d %s", resp.StatusCode, resp.body)
+	}
+
+	resp := app.do(bearerRequest(http.MethodPost, "/api/auth/impersonation/stop", impersonation.AccessToken))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("stop: %d %s", resp.StatusCode, resp.body)
+	}
+	var stopped models.TokenPair
+	resp.decode(t, &stopped)
+	if stopped.User.ID != admin.ID || stopped.User.Impersonator != nil {
+		t.Fatalf("stop returned the data of %s, impersonator %v", stopped.User.ID, stopped.User.Impersonator)
+	}
+
+	for _, request := range []testRequest{
+		bearerRequest(http.MethodGet, "/api/me/sessions", impersonation.AccessToken),
+		bearerRequest(http.MethodPost, "/api/auth/impersonation/stop", impersonation.AccessToken),
+	} {
+		if resp := app.do(request); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s %s with the stopped impersonation token: %d %s, want 401", request.method, request.path, resp.StatusCode, resp.body)
+		}
+	}
+	if resp := app.do(bearerRequest(http.MethodGet, "/api/me/sessions", stopped.AccessToken)); resp.StatusCode != http.StatusOK {
+		t.Errorf("admin token after stop: %d %s", resp.StatusCode, resp.body)
+	}
+}
+
+func TestImpersonationEndsWhenImpersonatorIsSuspended(t *testing.T) {
+	for _, authMode := range []string{libs.AuthModeSession, libs.AuthModeJWT} {
+		t.Run(authMode, func(t *testing.T) {
+			app := newTestApp(t, func(env *models.EnvVar) {
+				env.AuthMode = authMode
+			})
+			admin := app.createUser(models.RoleAdmin)
+			user := app.createUser(models.RoleUser)
+
+			var request testRequest
+			if authMode == libs.AuthModeJWT {
+				impersonation := app.impersonate(app.tokenLogin(admin).AccessToken, user)
+				request = bearerRequest(http.MethodGet, "/api/me/sessions", impersonation.AccessToken)
+			} else {
+				cookies := app.loginCookies(admin)
+				resp := app.do(testRequest{method: http.MethodPost, path: "/api/admin/users/" + user.ID + "/impersonate", cookies: cookies})
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("impersonate: %d %s", resp.StatusCode, resp.body)
+				}
+				request = testRequest{method: http.MethodGet, path: "/api/me/sessions", cookies: cookies}
+			}
+			if resp := app.do(request); resp.StatusCode != http.StatusOK {
+				t.Fatalf("impersonating: %d %s", resp.StatusCode, resp.body)
+			}
+
+			// only the flag is set, as a session started while suspending the admin would escape revocation
+			if err := app.redis.Set("user_suspended:"+admin.ID, "1"); err != nil {
+				t.Fatal(err)
+			}
+			resp := app.do(request)
+			var body models.ErrorResponse
+			resp.decode(t, &body)
+			if resp.StatusCode != http.StatusForbidden || body.Message != "Account is suspended" {
+				t.Fatalf("impersonating for a suspended admin: %d %s, want 403", resp.StatusCode, resp.body)
+			}
+
+			suspended, err := libs.IsUserSuspended(context.TODO(), user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if suspended {
+				t.Error("the impersonated user was suspended")
+			}
+		})
+	}
+}
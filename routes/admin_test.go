@@ -0,0 +1,82 @@
+package routes_test
+
+import (
+	"go_blogs/models"
+	"net/http"
+	"testing"
+)
+
+// wantDenied checks that resp is a 403 with message
+func wantDenied(t *testing.T, resp *testResponse, message string) {
+	t.Helper()
+	var body models.ErrorResponse
+	resp.decode(t, &body)
+	if resp.StatusCode != http.StatusForbidden || body.Message != message {
+		t.Fatalf("%d %s, want 403 %q", resp.StatusCode, resp.body, message)
+	}
+}
+
+func TestUpdateUserRoleRefusesPermissionsTheCallerLacks(t *testing.T) {
+	app := newTestApp(t, nil)
+	roleManager := app.createUser(models.RoleUser, models.PermissionManageRoles)
+	cookies := app.loginCookies(roleManager)
+	updateRole := func(user testUser, role models.Role, permissions ...models.Permission) *testResponse {
+		if permissions == nil {
+			permissions = []models.Permission{}
+		}
+		return app.do(testRequest{
+			method:  http.MethodPut,
+			path:    "/api/admin/users/" + user.ID + "/role",
+			body:    map[string]interface{}{"role": role, "permissions": permissions},
+			cookies: cookies,
+		})
+	}
+
+	// a second account of the role manager must not become an admin
+	user := app.createUser(models.RoleUser)
+	wantDenied(t, updateRole(user, models.RoleAdmin), "You cannot grant permissions you do not have")
+	wantDenied(t, updateRole(user, models.RoleUser, models.PermissionManageUsers), "You cannot grant permissions you do not have")
+
+	for _, target := range []testUser{
+		app.createUser(models.RoleAdmin),
+		app.createUser(models.RoleUser, models.PermissionManageRoles, models.PermissionImpersonateUsers),
+	} {
+		wantDenied(t, updateRole(target, models.RoleUser), "You cannot change the role of a user with permissions you do not have")
+	}
+
+	resp := updateRole(user, models.RoleUser, models.PermissionManageRoles)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("granting a permission the role manager has: %d %s", resp.StatusCode, resp.body)
+	}
+	var data models.UserSessionData
+	resp.decode(t, &data)
+	if data.Role != models.RoleUser || len(data.Permissions) != 1 || data.Permissions[0] != models.PermissionManageRoles {
+		t.Fatalf("role %s, permissions %v", data.Role, data.Permissions)
+	}
+}
+
+func TestManagingUsersRefusesUsersWithPermissionsTheCallerLacks(t *testing.T) {
+	app := newTestApp(t, nil)
+	userManager := app.createUser(models.RoleUser, models.PermissionManageUsers)
+	cookies := app.loginCookies(userManager)
+
+	for _, action := range []string{"suspend", "unsuspend", "password-reset"} {
+		admin := app.createUser(models.RoleAdmin)
+		resp := app.do(testRequest{method: http.MethodPost, path: "/api/admin/users/" + admin.ID + "/" + action, body: map[string]string{}, cookies: cookies})
+		wantDenied(t, resp, "You cannot manage a user with permissions you do not have")
+
+		// the admin is left alone
+		if resp := app.login(admin.Email, testPassword); resp.StatusCode != http.StatusOK {
+			t.Fatalf("login of the admin after %s was refused: %d %s", action, resp.StatusCode, resp.body)
+		}
+	}
+
+	// users with no more permissions than the user manager are theirs to manage
+	user := app.createUser(models.RoleUser, models.PermissionManageUsers)
+	for _, action := range []string{"suspend", "unsuspend", "password-reset"} {
+		resp := app.do(testRequest{method: http.MethodPost, path: "/api/admin/users/" + user.ID + "/" + action, body: map[string]string{}, cookies: cookies})
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("%s of a user: %d %s", action, resp.StatusCode, resp.body)
+		}
+	}
+}
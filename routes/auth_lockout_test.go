@@ -0,0 +1,171 @@
+package routes_test
+
+import (
+	"fmt"
+	"go_blogs/models"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type loginAttempt struct {
+	password       string
+	wantStatus     int
+	wantRetryAfter string
+}
+
+func (app *testApp) expectLogins(email string, attempts ...loginAttempt) {
+	app.t.Helper()
+	for i, attempt := range attempts {
+		resp := app.login(email, attempt.password)
+		if resp.StatusCode != attempt.wantStatus {
+			app.t.Fatalf("login %d to %s: status %d %s, want %d", i+1, email, resp.StatusCode, resp.body, attempt.wantStatus)
+		}
+		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != attempt.wantRetryAfter {
+			app.t.Fatalf("login %d to %s: Retry-After %q, want %q", i+1, email, retryAfter, attempt.wantRetryAfter)
+		}
+	}
+}
+
+var (
+	wrongLogin   = loginAttempt{password: "wrong-password", wantStatus: http.StatusBadRequest}
+	correctLogin = loginAttempt{password: testPassword, wantStatus: http.StatusOK}
+)
+
+func blockedLogin(password string, retryAfter string) loginAttempt {
+	return loginAttempt{password: password, wantStatus: http.StatusTooManyRequests, wantRetryAfter: retryAfter}
+}
+
+func TestLoginBacksOffThenLocksAccount(t *testing.T) {
+	app := newTestApp(t, func(env *models.EnvVar) {
+		env.LoginBackoffAfter = 2
+		env.LoginBackoffBase = time.Second
+		env.LoginBackoffMax = 2 * time.Second
+		env.LoginLockoutThreshold = 4
+		env.LoginLockoutDuration = 30 * time.Minute
+	})
+	user := app.createUser(models.RoleUser)
+
+	app.expectLogins(user.Email, wrongLogin, wrongLogin, blockedLogin(testPassword, "1"))
+
+	app.redis.FastForward(time.Second)
+	app.expectLogins(user.Email, wrongLogin, blockedLogin(testPassword, "2"))
+
+	// the backoff stops doubling at its maximum
+	app.redis.FastForward(2 * time.Second)
+	app.expectLogins(user.Email, wrongLogin, blockedLogin("wrong-password", "1800"))
+
+	// the lockout outlasts the backoff and holds even for the right password
+	app.redis.FastForward(10 * time.Minute)
+	app.expectLogins(user.Email, blockedLogin(testPassword, "1200"))
+
+	// the owner is mailed a link that lifts the lockout
+	resp := app.do(testRequest{
+		method: http.MethodPost,
+		path:   "/api/auth/unlock",
+		body:   map[string]string{"token": app.mailer.lastLinkToken(t, user.Email)},
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unlock: %d %s", resp.StatusCode, resp.body)
+	}
+	app.expectLogins(user.Email, correctLogin)
+}
+
+func TestLoginLockoutDoesNotWaitForUnlockMail(t *testing.T) {
+	app := newTestApp(t, func(env *models.EnvVar) {
+		env.LoginBackoffAfter = 10
+		env.LoginLockoutThreshold = 2
+	})
+	user := app.createUser(models.RoleUser)
+
+	hold := make(chan struct{})
+	app.mailer.hold = hold
+	release := time.AfterFunc(5*time.Second, func() { close(hold) })
+
+	// the login that locks the account of a user answers as fast as one to an unknown email
+	start := time.Now()
+	app.expectLogins(user.Email, wrongLogin, wrongLogin)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("locking the account took %s, waiting for the unlock mail", elapsed)
+	}
+
+	if release.Stop() {
+		close(hold)
+	}
+	if token := app.mailer.lastLinkToken(t, user.Email); token == "" {
+		t.Fatal("the unlock link has no token")
+	}
+}
+
+func TestLoginLockoutExpires(t *testing.T) {
+	app := newTestApp(t, func(env *models.EnvVar) {
+		env.LoginBackoffAfter = 10
+		env.LoginLockoutThreshold = 3
+		env.LoginLockoutDuration = 30 * time.Minute
+	})
+	user := app.createUser(models.RoleUser)
+
+	app.expectLogins(user.Email, wrongLogin, wrongLogin, wrongLogin, blockedLogin(testPassword, "1800"))
+
+	app.redis.FastForward(30 * time.Minute)
+	app.expectLogins(user.Email, correctLogin)
+}
+
+func TestLoginLocksIPAcrossAccounts(t *testing.T) {
+	app := newTestApp(t, func(env *models.EnvVar) {
+		env.LoginBackoffAfter = 100
+		env.LoginLockoutThreshold = 100
+		env.LoginIPLockoutThreshold = 5
+		env.LoginLockoutDuration = 30 * time.Minute
+	})
+	user := app.createUser(models.RoleUser)
+
+	// failures for emails nobody has count against the IP all the same
+	for i := 0; i < 5; i++ {
+		app.expectLogins(fmt.Sprintf("nobody%d@example.com", i), wrongLogin)
+	}
+	app.expectLogins(user.Email, blockedLogin(testPassword, "1800"))
+	app.expectLogins("someone@example.com", blockedLogin("wrong-password", "1800"))
+
+	app.redis.FastForward(30 * time.Minute)
+	app.expectLogins(user.Email, correctLogin)
+}
+
+func TestLoginResetsAccountFailuresButNotIPFailures(t *testing.T) {
+	app := newTestApp(t, func(env *models.EnvVar) {
+		env.LoginBackoffAfter = 100
+		env.LoginLockoutThreshold = 3
+		env.LoginIPLockoutThreshold = 5
+		env.LoginLockoutDuration = 30 * time.Minute
+	})
+	user := app.createUser(models.RoleUser)
+
+	app.expectLogins(user.Email, wrongLogin, wrongLogin, correctLogin)
+	if app.redis.Exists("login_failures:account:" + user.Email) {
+		t.Fatal("account failures are kept after a successful login")
+	}
+
+	// were the first two failures still counted, the account would be locked by now
+	app.expectLogins(user.Email, wrongLogin, wrongLogin, correctLogin)
+
+	// the IP has failed four times, and the logins of its own account did not clear them
+	app.expectLogins(user.Email, wrongLogin, blockedLogin(testPassword, "1800"))
+}
+
+func TestLoginFailuresAreForgottenAfterWindow(t *testing.T) {
+	app := newTestApp(t, func(env *models.EnvVar) {
+		env.LoginFailureWindow = time.Hour
+		env.LoginBackoffAfter = 3
+		env.LoginBackoffBase = time.Minute
+		env.LoginLockoutThreshold = 100
+	})
+	user := app.createUser(models.RoleUser)
+
+	// each failure renews the window, so only a quiet hour forgets them
+	app.expectLogins(user.Email, wrongLogin, wrongLogin)
+	app.redis.FastForward(59 * time.Minute)
+	app.expectLogins(user.Email, wrongLogin, blockedLogin(testPassword, "60"))
+
+	app.redis.FastForward(time.Hour)
+	app.expectLogins(user.Email, wrongLogin, wrongLogin, wrongLogin, blockedLogin(testPassword, "60"))
+}
@@ -0,0 +1,307 @@
+package routes_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"go_blogs/models"
+	"net/http"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	authenticatorFlagUserPresent  = 0x01
+	authenticatorFlagUserVerified = 0x04
+	authenticatorFlagAttestedData = 0x40
+	// coseCurveP256 identifies P-256 in a COSE key
+	coseCurveP256 = 1
+)
+
+// softwareAuthenticator is a passkey kept in memory that answers the ceremonies the way a browser and an
+// authenticator would together
+type softwareAuthenticator struct {
+	t            *testing.T
+	origin       string
+	rpID         string
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftwareAuthenticator(t *testing.T) *softwareAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialID := make([]byte, 16)
+	if _, err = rand.Read(credentialID); err != nil {
+		t.Fatal(err)
+	}
+	return &softwareAuthenticator{
+		t:            t,
+		origin:       "http://localhost:8080",
+		rpID:         "localhost",
+		key:          key,
+		credentialID: credentialID,
+	}
+}
+
+var base64URL = base64.RawURLEncoding
+
+// ceremonyOptions is the part of the options of BeginPasskeyRegistration and BeginPasskeyLogin the authenticator reads
+type ceremonyOptions struct {
+	PublicKey struct {
+		Challenge string `json:"challenge"`
+		User      struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	} `json:"publicKey"`
+}
+
+func (a *softwareAuthenticator) clientData(ceremony string, challenge string) []byte {
+	clientData, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge,
+		"origin":    a.origin,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return clientData
+}
+
+func (a *softwareAuthenticator) authenticatorData(flags byte, attestedCredentialData []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attestedCredentialData...)
+}
+
+// create answers navigator.credentials.create() with a "none" attestation
+func (a *softwareAuthenticator) create(options ceremonyOptions) map[string]interface{} {
+	userHandle, err := base64URL.DecodeString(options.PublicKey.User.ID)
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	a.userHandle = userHandle
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  coseCurveP256,
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	attestedCredentialData := make([]byte, 16)
+	attestedCredentialData = binary.BigEndian.AppendUint16(attestedCredentialData, uint16(len(a.credentialID)))
+	attestedCredentialData = append(attestedCredentialData, a.credentialID...)
+	attestedCredentialData = append(attestedCredentialData, publicKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authenticatorData(authenticatorFlagUserPresent|authenticatorFlagUserVerified|authenticatorFlagAttestedData, attestedCredentialData),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	return map[string]interface{}{
+		"id":    base64URL.EncodeToString(a.credentialID),
+		"rawId": base64URL.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON":    base64URL.EncodeToString(a.clientData("webauthn.create", options.PublicKey.Challenge)),
+			"attestationObject": base64URL.EncodeToString(attestationObject),
+			"transports":        []string{"internal"},
+		},
+	}
+}
+
+// get answers navigator.credentials.get() with an assertion signed with the counter as it stands
+func (a *softwareAuthenticator) get(options ceremonyOptions) map[string]interface{} {
+	clientData := a.clientData("webauthn.get", options.PublicKey.Challenge)
+	authenticatorData := a.authenticatorData(authenticatorFlagUserPresent|authenticatorFlagUserVerified, nil)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authenticatorData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	return map[string]interface{}{
+		"id":    base64URL.EncodeToString(a.credentialID),
+		"rawId": base64URL.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON":    base64URL.EncodeToString(clientData),
+			"authenticatorData": base64URL.EncodeToString(authenticatorData),
+			"signature":         base64URL.EncodeToString(signature),
+			"userHandle":        base64URL.EncodeToString(a.userHandle),
+		},
+	}
+}
+
+func (app *testApp) beginPasskeyCeremony(path string, cookies []*http.Cookie) ceremonyOptions {
+	app.t.Helper()
+	resp := app.do(testRequest{method: http.MethodPost, path: path, cookies: cookies})
+	if resp.StatusCode != http.StatusOK {
+		app.t.Fatalf("%s: %d %s", path, resp.StatusCode, resp.body)
+	}
+	var options ceremonyOptions
+	resp.decode(app.t, &options)
+	return options
+}
+
+// registerPasskey adds a passkey of authenticator to user
+func (app *testApp) registerPasskey(user testUser, authenticator *softwareAuthenticator) {
+	app.t.Helper()
+	cookies := app.loginCookies(user)
+	options := app.beginPasskeyCeremony("/api/me/passkeys/register/begin", cookies)
+	resp := app.do(testRequest{
+		method:  http.MethodPost,
+		path:    "/api/me/passkeys/register/finish?name=Laptop",
+		body:    authenticator.create(options),
+		cookies: cookies,
+	})
+	if resp.StatusCode != http.StatusCreated {
+		app.t.Fatalf("finish registration: %d %s", resp.StatusCode, resp.body)
+	}
+}
+
+func (app *testApp) passkeyLogin(assertion map[string]interface{}) *testResponse {
+	app.t.Helper()
+	return app.do(testRequest{method: http.MethodPost, path: "/api/auth/passkey/finish", body: assertion})
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	app := newTestApp(t, nil)
+	user := app.createUser(models.RoleUser)
+	authenticator := newSoftwareAuthenticator(t)
+
+	app.registerPasskey(user, authenticator)
+
+	resp := app.do(testRequest{method: http.MethodGet, path: "/api/me/passkeys", cookies: app.loginCookies(user)})
+	var passkeys []models.Passkey
+	resp.decode(t, &passkeys)
+	if len(passkeys) != 1 || passkeys[0].Name != "Laptop" {
+		t.Fatalf("passkeys: %s", resp.body)
+	}
+
+	for i := 0; i < 2; i++ {
+		authenticator.signCount++
+		resp = app.passkeyLogin(authenticator.get(app.beginPasskeyCeremony("/api/auth/passkey/begin", nil)))
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("passkey login %d: %d %s", i+1, resp.StatusCode, resp.body)
+		}
+		var sessionData models.UserSessionData
+		resp.decode(t, &sessionData)
+		if sessionData.ID != user.ID {
+			t.Fatalf("passkey login %d logged in as %s, want %s", i+1, sessionData.ID, user.ID)
+		}
+	}
+
+	var stored bson.M
+	if err := app.database.Collection("passkeys").FindOne(context.TODO(), bson.M{"userId": user.ID}).Decode(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored["signCount"] != int64(2) || stored["lastUsedAt"] == nil {
+		t.Fatalf("passkey after two logins: %v", stored)
+	}
+}
+
+func TestPasskeyRegistrationRejectsReusedChallenge(t *testing.T) {
+	app := newTestApp(t, nil)
+	user := app.createUser(models.RoleUser)
+	cookies := app.loginCookies(user)
+
+	options := app.beginPasskeyCeremony("/api/me/passkeys/register/begin", cookies)
+	credential := newSoftwareAuthenticator(t).create(options)
+	finish := testRequest{
+		method:  http.MethodPost,
+		path:    "/api/me/passkeys/register/finish",
+		body:    credential,
+		cookies: cookies,
+	}
+	if resp := app.do(finish); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("finish registration: %d %s", resp.StatusCode, resp.body)
+	}
+
+	// another key answering the same challenge is not added
+	finish.body = newSoftwareAuthenticator(t).create(options)
+	if resp := app.do(finish); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("registration with a used challenge: %d %s", resp.StatusCode, resp.body)
+	}
+}
+
+func TestPasskeyLoginRejectsReusedChallenge(t *testing.T) {
+	app := newTestApp(t, nil)
+	user := app.createUser(models.RoleUser)
+	authenticator := newSoftwareAuthenticator(t)
+	app.registerPasskey(user, authenticator)
+
+	options := app.beginPasskeyCeremony("/api/auth/passkey/begin", nil)
+	authenticator.signCount++
+	assertion := authenticator.get(options)
+	if resp := app.passkeyLogin(assertion); resp.StatusCode != http.StatusOK {
+		t.Fatalf("passkey login: %d %s", resp.StatusCode, resp.body)
+	}
+
+	// a replayed assertion is refused, and so is a fresh signature over the spent challenge
+	if resp := app.passkeyLogin(assertion); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("replayed assertion: %d %s", resp.StatusCode, resp.body)
+	}
+	authenticator.signCount++
+	if resp := app.passkeyLogin(authenticator.get(options)); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("assertion over a used challenge: %d %s", resp.StatusCode, resp.body)
+	}
+}
+
+func TestPasskeyLoginRejectsSignCountRegression(t *testing.T) {
+	app := newTestApp(t, nil)
+	user := app.createUser(models.RoleUser)
+	authenticator := newSoftwareAuthenticator(t)
+	app.registerPasskey(user, authenticator)
+
+	authenticator.signCount = 5
+	if resp := app.passkeyLogin(authenticator.get(app.beginPasskeyCeremony("/api/auth/passkey/begin", nil))); resp.StatusCode != http.StatusOK {
+		t.Fatalf("passkey login: %d %s", resp.StatusCode, resp.body)
+	}
+
+	// a copy of the key signs with a counter that has fallen behind
+	authenticator.signCount = 3
+	resp := app.passkeyLogin(authenticator.get(app.beginPasskeyCeremony("/api/auth/passkey/begin", nil)))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("passkey login with a lower counter: %d %s", resp.StatusCode, resp.body)
+	}
+
+	var stored bson.M
+	if err := app.database.Collection("passkeys").FindOne(context.TODO(), bson.M{"userId": user.ID}).Decode(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored["cloneWarning"] != true || stored["signCount"] != int64(5) {
+		t.Fatalf("passkey after a counter regression: %v", stored)
+	}
+
+	// a repeated counter is just as suspect
+	authenticator.signCount = 5
+	resp = app.passkeyLogin(authenticator.get(app.beginPasskeyCeremony("/api/auth/passkey/begin", nil)))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("passkey login with a repeated counter: %d %s", resp.StatusCode, resp.body)
+	}
+}
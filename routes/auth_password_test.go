@@ -0,0 +1,49 @@
+package routes_test
+
+import (
+	"go_blogs/models"
+	"net/http"
+	"testing"
+)
+
+func TestResetPasswordInvalidatesOtherLinks(t *testing.T) {
+	app := newTestApp(t, nil)
+	user := app.createUser(models.RoleUser)
+
+	for _, email := range []string{"nobody@example.com", user.Email} {
+		app.mustDo(http.StatusAccepted, testRequest{
+			method: http.MethodPost,
+			path:   "/api/auth/forgot-password",
+			body:   map[string]string{"email": email},
+		})
+	}
+	forgotToken := app.mailer.lastLinkToken(t, user.Email)
+
+	// a forced reset mails its link before answering
+	app.mustDo(http.StatusAccepted, testRequest{
+		method:  http.MethodPost,
+		path:    "/api/admin/users/" + user.ID + "/password-reset",
+		body:    map[string]string{},
+		cookies: app.loginCookies(app.createUser(models.RoleAdmin)),
+	})
+	forcedToken := app.mailer.lastLinkToken(t, user.Email)
+	if forcedToken == forgotToken {
+		t.Fatal("the forced reset mailed the link of the forgotten password")
+	}
+
+	resetPassword := func(token string, password string) *testResponse {
+		return app.do(testRequest{
+			method: http.MethodPost,
+			path:   "/api/auth/reset-password",
+			body:   map[string]string{"token": token, "password": password},
+		})
+	}
+	if resp := resetPassword(forcedToken, "new-password"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("reset: %d %s", resp.StatusCode, resp.body)
+	}
+	if resp := resetPassword(forgotToken, "leaked-password"); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("reset with the older link: %d %s, want 400", resp.StatusCode, resp.body)
+	}
+
+	app.expectLogins(user.Email, loginAttempt{password: "new-password", wantStatus: http.StatusOK})
+}
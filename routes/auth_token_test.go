@@ -0,0 +1,81 @@
+package routes_test
+
+import (
+	"go_blogs/models"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// refresh exchanges refreshToken at /api/auth/token/refresh
+func (app *testApp) refresh(refreshToken string) *testResponse {
+	app.t.Helper()
+	return app.do(testRequest{
+		method: http.MethodPost,
+		path:   "/api/auth/token/refresh",
+		body:   map[string]string{"refreshToken": refreshToken},
+	})
+}
+
+// mustRefresh exchanges refreshToken and returns the new token pair
+func (app *testApp) mustRefresh(refreshToken string) models.TokenPair {
+	app.t.Helper()
+	resp := app.refresh(refreshToken)
+	if resp.StatusCode != http.StatusOK {
+		app.t.Fatalf("refresh: %d %s", resp.StatusCode, resp.body)
+	}
+	var tokens models.TokenPair
+	resp.decode(app.t, &tokens)
+	return tokens
+}
+
+// wantRefreshRefused checks that refreshToken is refused with message
+func (app *testApp) wantRefreshRefused(refreshToken string, message string) {
+	app.t.Helper()
+	resp := app.refresh(refreshToken)
+	var body models.ErrorResponse
+	resp.decode(app.t, &body)
+	if resp.StatusCode != http.StatusUnauthorized || body.Message != message {
+		app.t.Fatalf("refresh: %d %s, want 401 %q", resp.StatusCode, resp.body, message)
+	}
+}
+
+const (
+	refreshTokenReusedMessage  = "Refresh token has already been used. Please log in again"
+	refreshTokenInvalidMessage = "Refresh token is invalid or has expired"
+)
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	app := newJWTTestApp(t)
+	user := app.createUser(models.RoleUser)
+
+	first := app.tokenLogin(user)
+	second := app.mustRefresh(first.RefreshToken)
+	third := app.mustRefresh(second.RefreshToken)
+
+	// any token exchanged before, not only the last one, gives the leak away
+	app.wantRefreshRefused(first.RefreshToken, refreshTokenReusedMessage)
+	app.wantRefreshRefused(third.RefreshToken, refreshTokenInvalidMessage)
+
+	sessionID, _, _ := strings.Cut(first.RefreshToken, ".")
+	for _, key := range []string{"sess:", "sess_info:", "sess_refresh:", "sess_refresh_retired:"} {
+		if app.redis.Exists(key + sessionID) {
+			t.Errorf("%s%s outlived the revoked session", key, sessionID)
+		}
+	}
+}
+
+func TestRefreshTokenWithUnknownSecretLeavesSession(t *testing.T) {
+	app := newJWTTestApp(t)
+	user := app.createUser(models.RoleUser)
+
+	tokens := app.tokenLogin(user)
+	tokens = app.mustRefresh(tokens.RefreshToken)
+
+	sessionID, _, _ := strings.Cut(tokens.RefreshToken, ".")
+	for _, refreshToken := range []string{sessionID + ".guessed", sessionID + ".", "unknown." + sessionID} {
+		app.wantRefreshRefused(refreshToken, refreshTokenInvalidMessage)
+	}
+
+	app.mustRefresh(tokens.RefreshToken)
+}
@@ -0,0 +1,47 @@
+package routes_test
+
+import (
+	"context"
+	"go_blogs/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSearchTotalLeavesOutUnsearchableHits(t *testing.T) {
+	app := newTestApp(t, nil)
+	authorCookies := app.loginCookies(app.createUser(models.RoleUser))
+	editorCookies := app.loginCookies(app.createUser(models.RoleEditor))
+
+	var blogIDs []string
+	for _, title := range []string{"Searching for kestrels", "More about kestrels"} {
+		var created models.CreatedResponse
+		app.mustDo(http.StatusCreated, testRequest{
+			method:  http.MethodPost,
+			path:    "/api/blogs",
+			body:    map[string]string{"title": title, "content": "content"},
+			cookies: authorCookies,
+		}).decode(t, &created)
+		app.mustDo(http.StatusOK, testRequest{method: http.MethodPost, path: "/api/blogs/" + created.ID + "/publish", cookies: editorCookies})
+		blogIDs = append(blogIDs, created.ID)
+	}
+
+	// trashed behind the back of the index, as by another replica whose notification has not arrived yet
+	blogObjectID, _ := primitive.ObjectIDFromHex(blogIDs[0])
+	_, err := app.database.Collection("blogs").UpdateByID(context.TODO(), blogObjectID, bson.M{"$set": bson.M{"deletedAt": time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var page models.PaginatedResponse[models.BlogSearchHit]
+	app.mustDo(http.StatusOK, testRequest{method: http.MethodGet, path: "/api/blogs/search?q=kestrels"}).decode(t, &page)
+	if len(page.Items) != 1 || page.Items[0].Blog.ID != blogIDs[1] {
+		t.Fatalf("found %+v, want only %s", page.Items, blogIDs[1])
+	}
+	if page.Total != 1 {
+		t.Fatalf("total %d, want 1", page.Total)
+	}
+}
@@ -0,0 +1,73 @@
+package routes_test
+
+import (
+	"go_blogs/libs"
+	"go_blogs/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAuthorsGoThroughReviewToPublish(t *testing.T) {
+	app := newTestApp(t, nil)
+	author := app.createUser(models.RoleUser)
+	authorCookies := app.loginCookies(author)
+	editorCookies := app.loginCookies(app.createUser(models.RoleEditor))
+	publishAt := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+
+	wantAccessDenied := func(resp *testResponse) {
+		t.Helper()
+		var body models.ErrorResponse
+		resp.decode(t, &body)
+		if resp.StatusCode != http.StatusConflict || body.Message != "Access Denied" {
+			t.Fatalf("%d %s, want 409 Access Denied", resp.StatusCode, resp.body)
+		}
+	}
+
+	// scheduling a blog would publish it all the same
+	wantAccessDenied(app.do(testRequest{
+		method:  http.MethodPost,
+		path:    "/api/blogs",
+		body:    map[string]interface{}{"title": "A scheduled blog", "content": "content", "publishAt": publishAt},
+		cookies: authorCookies,
+	}))
+
+	var created models.CreatedResponse
+	app.mustDo(http.StatusCreated, testRequest{
+		method:  http.MethodPost,
+		path:    "/api/blogs",
+		body:    map[string]string{"title": "A reviewed blog", "content": "content"},
+		cookies: authorCookies,
+	}).decode(t, &created)
+	blogPath := "/api/blogs/" + created.ID
+
+	wantAccessDenied(app.do(testRequest{method: http.MethodPost, path: blogPath + "/publish", cookies: authorCookies}))
+	app.mustDo(http.StatusOK, testRequest{method: http.MethodPost, path: blogPath + "/submit", cookies: authorCookies})
+	wantAccessDenied(app.do(testRequest{method: http.MethodPost, path: blogPath + "/publish", cookies: authorCookies}))
+
+	update := func(cookies []*http.Cookie, version int, publishAt time.Time) *testResponse {
+		return app.do(testRequest{
+			method:  http.MethodPut,
+			path:    blogPath,
+			body:    map[string]interface{}{"title": "A reviewed blog", "content": "content", "publishAt": publishAt},
+			headers: map[string]string{fiber.HeaderIfMatch: libs.FormatETag(version)},
+			cookies: cookies,
+		})
+	}
+	wantAccessDenied(update(authorCookies, 2, publishAt))
+
+	// the author may keep the schedule the editor set, but not move it
+	if resp := update(editorCookies, 2, publishAt); resp.StatusCode != http.StatusOK {
+		t.Fatalf("scheduling as the editor: %d %s", resp.StatusCode, resp.body)
+	}
+	if resp := update(authorCookies, 3, publishAt); resp.StatusCode != http.StatusOK {
+		t.Fatalf("keeping the schedule: %d %s", resp.StatusCode, resp.body)
+	}
+	wantAccessDenied(update(authorCookies, 4, publishAt.Add(time.Minute)))
+
+	app.mustDo(http.StatusOK, testRequest{method: http.MethodPost, path: blogPath + "/publish", cookies: editorCookies})
+	// unpublishing and archiving stay with the author
+	app.mustDo(http.StatusOK, testRequest{method: http.MethodPost, path: blogPath + "/archive", cookies: authorCookies})
+}
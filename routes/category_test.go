@@ -0,0 +1,42 @@
+package routes_test
+
+import (
+	"go_blogs/models"
+	"net/http"
+	"testing"
+)
+
+func TestCategoryFallbackSlugsAreNumbered(t *testing.T) {
+	app := newTestApp(t, nil)
+	cookies := app.loginCookies(app.createUser(models.RoleUser, models.PermissionManageCategories))
+
+	// names without a letter or digit have no slug of their own
+	var ids []string
+	for _, name := range []string{"🎉", "★★★"} {
+		var created models.CreatedResponse
+		app.mustDo(http.StatusCreated, testRequest{
+			method:  http.MethodPost,
+			path:    "/api/categories",
+			body:    map[string]string{"name": name},
+			cookies: cookies,
+		}).decode(t, &created)
+		ids = append(ids, created.ID)
+	}
+	// renaming keeps the number of the category
+	app.mustDo(http.StatusOK, testRequest{
+		method:  http.MethodPut,
+		path:    "/api/categories/" + ids[1],
+		body:    map[string]string{"name": "☆☆☆"},
+		cookies: cookies,
+	})
+
+	var categories []models.CategoryNode
+	app.mustDo(http.StatusOK, testRequest{method: http.MethodGet, path: "/api/categories"}).decode(t, &categories)
+	slugs := map[string]string{}
+	for _, category := range categories {
+		slugs[category.ID] = category.Slug
+	}
+	if slugs[ids[0]] != "category" || slugs[ids[1]] != "category-2" {
+		t.Fatalf("slugs %q and %q, want category and category-2", slugs[ids[0]], slugs[ids[1]])
+	}
+}
@@ -0,0 +1,287 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go_blogs/configs"
+	"go_blogs/connections"
+	"go_blogs/controllers"
+	"go_blogs/libs"
+	"go_blogs/mailer"
+	"go_blogs/models"
+	"go_blogs/routes"
+	"go_blogs/search"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "password123"
+
+// testMailer keeps the messages it is asked to send
+type testMailer struct {
+	mu       sync.Mutex
+	messages []mailer.Message
+	// hold, when set, keeps messages from being sent until it is closed, like a slow mail server
+	hold chan struct{}
+}
+
+func (m *testMailer) Send(_ context.Context, message mailer.Message) error {
+	if m.hold != nil {
+		<-m.hold
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// lastLinkToken returns the token of the link in the last message sent to email. Some links are mailed in the
+// background, so it waits a little for one to arrive
+func (m *testMailer) lastLinkToken(t *testing.T, email string) string {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if token, ok := m.findLinkToken(email); ok {
+			return token
+		}
+	}
+	t.Fatalf("no link has been mailed to %s", email)
+	return ""
+}
+
+func (m *testMailer) findLinkToken(email string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To != email {
+			continue
+		}
+		if match := regexp.MustCompile(`token=([^\s]+)`).FindStringSubmatch(m.messages[i].Text); match != nil {
+			token, err := url.QueryUnescape(match[1])
+			return token, err == nil
+		}
+	}
+	return "", false
+}
+
+// testApp is the app as main sets it up, on an empty Mongo database and an in-memory Redis
+type testApp struct {
+	*fiber.App
+	t        *testing.T
+	database *mongo.Database
+	redis    *miniredis.Miniredis
+	mailer   *testMailer
+}
+
+type testUser struct {
+	ID    string
+	Email string
+}
+
+// newTestApp builds the routes of InitRoute on the Mongo of TEST_MONGO_URI, in a database dropped once the test
+// ends. Tests are skipped when TEST_MONGO_URI is not set. configure may change the environment before the
+// controllers read it
+func newTestApp(t *testing.T, configure func(env *models.EnvVar)) *testApp {
+	t.Helper()
+	uri := os.Getenv("TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("TEST_MONGO_URI is not set, see Testing in README.md")
+	}
+
+	ctx := context.TODO()
+	redisServer := miniredis.RunT(t)
+	connections.RedisClient = redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetTimeout(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	connections.MongoClient = client
+	databaseName := "go_blogs_test_" + uuid.NewString()[:8]
+	t.Cleanup(func() {
+		client.Database(databaseName).Drop(ctx)
+		client.Disconnect(ctx)
+		connections.RedisClient.Close()
+	})
+
+	configs.Env = models.EnvVar{
+		AppEnv:                     "test",
+		AppURL:                     "http://localhost:8080",
+		SchedulerInterval:          30 * time.Second,
+		TrashRetention:             720 * time.Hour,
+		CursorSecret:               "cursor-secret",
+		EmailTokenSecret:           "email-token-secret",
+		EmailVerificationTTL:       48 * time.Hour,
+		RequireVerifiedEmailToPost: true,
+		PasswordResetTTL:           time.Hour,
+		LoginFailureWindow:         time.Hour,
+		LoginBackoffAfter:          3,
+		LoginBackoffBase:           time.Second,
+		LoginBackoffMax:            5 * time.Minute,
+		LoginLockoutThreshold:      10,
+		LoginIPLockoutThreshold:    100,
+		LoginLockoutDuration:       30 * time.Minute,
+		SecretEncryptionKey:        strings.Repeat("k", 32),
+		TOTPIssuer:                 "Go Blogs",
+		WebAuthnRPID:               "localhost",
+		WebAuthnRPName:             "Go Blogs",
+		WebAuthnRPOrigins:          []string{"http://localhost:8080"},
+		AuthMode:                   libs.AuthModeSession,
+		JWTAlgorithm:               "HS256",
+		JWTSecrets:                 []string{"jwt-secret"},
+		JWTAccessTokenTTL:          15 * time.Minute,
+		JWTRefreshTokenTTL:         720 * time.Hour,
+		MongoDatabase:              databaseName,
+	}
+	if configure != nil {
+		configure(&configs.Env)
+	}
+	libs.InitJWT()
+
+	testMailer := &testMailer{}
+	mailer.Default = testMailer
+
+	database := client.Database(databaseName)
+	search.Blogs = search.NewBlogIndex(search.NewMemoryBackend(), database.Collection("blogs"), connections.RedisClient)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			t.Log("ErrorHandler:", err.Error())
+			return c.Status(fiber.StatusInternalServerError).JSON(models.ErrorResponse{
+				Message: "Something went wrong",
+			})
+		},
+	})
+	routes.InitRoute(app)
+	// mails still being sent would otherwise use the connections and environment of the next test
+	t.Cleanup(controllers.WaitForBackgroundWork)
+
+	return &testApp{
+		App:      app,
+		t:        t,
+		database: database,
+		redis:    redisServer,
+		mailer:   testMailer,
+	}
+}
+
+// createUser inserts a verified user with testPassword as the password
+func (app *testApp) createUser(role models.Role, permissions ...models.Permission) testUser {
+	app.t.Helper()
+	id := primitive.NewObjectID()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		app.t.Fatal(err)
+	}
+	if permissions == nil {
+		permissions = []models.Permission{}
+	}
+	user := testUser{ID: id.Hex(), Email: fmt.Sprintf("%s@example.com", id.Hex())}
+	_, err = app.database.Collection("users").InsertOne(context.TODO(), bson.D{
+		{Key: "_id", Value: id},
+		{Key: "email", Value: user.Email},
+		{Key: "emailVerified", Value: true},
+		{Key: "password", Value: string(hashedPassword)},
+		{Key: "name", Value: "User " + id.Hex()},
+		{Key: "handle", Value: "user-" + id.Hex()},
+		{Key: "role", Value: role},
+		{Key: "permissions", Value: permissions},
+		{Key: "createdAt", Value: time.Now()},
+	})
+	if err != nil {
+		app.t.Fatal(err)
+	}
+	return user
+}
+
+// testRequest is a request to the app. Body is sent as JSON
+type testRequest struct {
+	method  string
+	path    string
+	body    interface{}
+	headers map[string]string
+	cookies []*http.Cookie
+}
+
+type testResponse struct {
+	*http.Response
+	body []byte
+}
+
+func (r *testResponse) decode(t *testing.T, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(r.body, v); err != nil {
+		t.Fatalf("response %s is not JSON: %v", r.body, err)
+	}
+}
+
+func (app *testApp) do(request testRequest) *testResponse {
+	app.t.Helper()
+	var body io.Reader
+	if request.body != nil {
+		encoded, err := json.Marshal(request.body)
+		if err != nil {
+			app.t.Fatal(err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+	req := httptest.NewRequest(request.method, request.path, body)
+	if request.body != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	for key, value := range request.headers {
+		req.Header.Set(key, value)
+	}
+	for _, cookie := range request.cookies {
+		req.AddCookie(cookie)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		app.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		app.t.Fatal(err)
+	}
+	return &testResponse{Response: resp, body: respBody}
+}
+
+func (app *testApp) login(email string, password string) *testResponse {
+	app.t.Helper()
+	return app.do(testRequest{
+		method: http.MethodPost,
+		path:   "/api/auth/login",
+		body:   map[string]string{"email": email, "password": password},
+	})
+}
+
+// loginCookies logs user in and returns the cookies of their session
+func (app *testApp) loginCookies(user testUser) []*http.Cookie {
+	app.t.Helper()
+	resp := app.login(user.Email, testPassword)
+	if resp.StatusCode != http.StatusOK {
+		app.t.Fatalf("login as %s: %d %s", user.Email, resp.StatusCode, resp.body)
+	}
+	return resp.Cookies()
+}
@@ -4,6 +4,7 @@ import (
 	"go_blogs/constants"
 	"go_blogs/controllers"
 	"go_blogs/middlewares"
+	"go_blogs/models"
 	"go_blogs/validators"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,6 +13,11 @@ import (
 func InitRoute(app *fiber.App) {
 	authControllers := controllers.NewAuthControllers()
 	blogControllers := controllers.NewBlogControllers()
+	categoryControllers := controllers.NewCategoryControllers()
+	userControllers := controllers.NewUserControllers()
+	adminControllers := controllers.NewAdminControllers()
+
+	app.Get("/.well-known/jwks.json", authControllers.GetJWKS)
 
 	api := app.Group("/api")
 
@@ -27,8 +33,51 @@ func InitRoute(app *fiber.App) {
 		validators.ValidateAuthPayload(constants.RouteName.REGISTER),
 		authControllers.Register,
 	)
+	authApi.Post(
+		"/login/2fa",
+		validators.ValidateAuthPayload(constants.RouteName.LOGIN_TWO_FACTOR),
+		authControllers.LoginTwoFactor,
+	)
+	authApi.Post("/passkey/begin", authControllers.BeginPasskeyLogin)
+	authApi.Post("/passkey/finish", authControllers.FinishPasskeyLogin)
+	authApi.Post(
+		"/verify-email",
+		validators.ValidateAuthPayload(constants.RouteName.VERIFY_EMAIL),
+		authControllers.VerifyEmail,
+	)
+	authApi.Post(
+		"/verify-email/resend",
+		validators.ValidateAuthPayload(constants.RouteName.RESEND_EMAIL_VERIFICATION),
+		authControllers.ResendEmailVerification,
+	)
+	authApi.Post(
+		"/forgot-password",
+		validators.ValidateAuthPayload(constants.RouteName.FORGOT_PASSWORD),
+		authControllers.ForgotPassword,
+	)
+	authApi.Post(
+		"/reset-password",
+		validators.ValidateAuthPayload(constants.RouteName.RESET_PASSWORD),
+		authControllers.ResetPassword,
+	)
+	authApi.Post(
+		"/unlock",
+		validators.ValidateAuthPayload(constants.RouteName.UNLOCK_LOGIN),
+		authControllers.UnlockLogin,
+	)
+	authApi.Post(
+		"/token/refresh",
+		validators.ValidateAuthPayload(constants.RouteName.REFRESH_TOKEN),
+		authControllers.RefreshToken,
+	)
 	authApi.Post("/logout", middlewares.AuthorizeUser, authControllers.Logout)
+	authApi.Post("/impersonation/stop", middlewares.AuthorizeUser, adminControllers.StopImpersonation)
 	authApi.Get("/user", authControllers.GetUserData)
+	authApi.Post(
+		"/email-change/confirm",
+		validators.ValidateUserPayload(constants.RouteName.CONFIRM_EMAIL_CHANGE),
+		userControllers.ConfirmEmailChange,
+	)
 
 	// /api/blogs
 	blogsApi := api.Group("/blogs")
@@ -37,25 +86,295 @@ func InitRoute(app *fiber.App) {
 		validators.ValidateBlogQuery(constants.RouteName.GET_BLOGS),
 		blogControllers.GetBlogs,
 	)
+	blogsApi.Get(
+		"/search",
+		validators.ValidateBlogQuery(constants.RouteName.SEARCH_BLOGS),
+		blogControllers.SearchBlogs,
+	)
+	blogsApi.Get(
+		"/by-slug/:slug",
+		validators.ValidateBlogParams(constants.RouteName.GET_BLOG_BY_SLUG),
+		blogControllers.GetBlogBySlug,
+	)
 	blogsApi.Get(
 		"/:id",
 		validators.ValidateBlogParams(constants.RouteName.GET_BLOG_BY_ID),
+		validators.ValidateBlogQuery(constants.RouteName.GET_BLOG_BY_ID),
 		blogControllers.GetBlogByID,
 	)
 	blogsApi.Post("/",
-		middlewares.AuthorizeUser,
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsWrite),
+		middlewares.RequireVerifiedEmail,
 		validators.ValidateBlogPayload(constants.RouteName.CREATE_BLOG),
 		blogControllers.CreateBlog,
 	)
 	blogsApi.Put("/:id",
-		middlewares.AuthorizeUser,
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsWrite),
 		validators.ValidateBlogParams(constants.RouteName.UPDATE_BLOG),
+		middlewares.RequireIfMatch,
 		validators.ValidateBlogPayload(constants.RouteName.UPDATE_BLOG),
 		blogControllers.UpdateBlog,
 	)
 	blogsApi.Delete("/:id",
-		middlewares.AuthorizeUser,
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsWrite),
 		validators.ValidateBlogParams(constants.RouteName.DELETE_BLOG),
+		middlewares.RequireIfMatch,
 		blogControllers.DeleteBlog,
 	)
+	blogsApi.Post("/:id/submit",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsWrite),
+		validators.ValidateBlogParams(constants.RouteName.SUBMIT_BLOG),
+		blogControllers.SubmitBlog,
+	)
+	blogsApi.Post("/:id/publish",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsWrite),
+		validators.ValidateBlogParams(constants.RouteName.PUBLISH_BLOG),
+		blogControllers.PublishBlog,
+	)
+	blogsApi.Post("/:id/unpublish",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsWrite),
+		validators.ValidateBlogParams(constants.RouteName.UNPUBLISH_BLOG),
+		blogControllers.UnpublishBlog,
+	)
+	blogsApi.Post("/:id/archive",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsWrite),
+		validators.ValidateBlogParams(constants.RouteName.ARCHIVE_BLOG),
+		blogControllers.ArchiveBlog,
+	)
+	blogsApi.Post("/:id/restore",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsWrite),
+		validators.ValidateBlogParams(constants.RouteName.RESTORE_BLOG),
+		blogControllers.RestoreBlog,
+	)
+	blogsApi.Get("/:id/revisions",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsRead),
+		validators.ValidateBlogParams(constants.RouteName.GET_BLOG_REVISIONS),
+		validators.ValidateBlogQuery(constants.RouteName.GET_BLOG_REVISIONS),
+		blogControllers.GetBlogRevisions,
+	)
+	blogsApi.Get("/:id/revisions/diff",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsRead),
+		validators.ValidateBlogParams(constants.RouteName.DIFF_BLOG_REVISIONS),
+		validators.ValidateBlogQuery(constants.RouteName.DIFF_BLOG_REVISIONS),
+		blogControllers.DiffBlogRevisions,
+	)
+	blogsApi.Get("/:id/revisions/:rev",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsRead),
+		validators.ValidateBlogParams(constants.RouteName.GET_BLOG_REVISION),
+		blogControllers.GetBlogRevision,
+	)
+	blogsApi.Post("/:id/revisions/:rev/restore",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsWrite),
+		validators.ValidateBlogParams(constants.RouteName.RESTORE_BLOG_REVISION),
+		middlewares.RequireIfMatch,
+		blogControllers.RestoreBlogRevision,
+	)
+
+	// /api/tags
+	api.Get(
+		"/tags",
+		validators.ValidateBlogQuery(constants.RouteName.GET_TAGS),
+		blogControllers.GetTags,
+	)
+
+	// /api/categories
+	categoriesApi := api.Group("/categories")
+	categoriesApi.Get("/", categoryControllers.GetCategories)
+	categoriesApi.Post("/",
+		middlewares.AuthorizeScope(models.AccessTokenScopeCategoriesWrite),
+		middlewares.RequirePermission(models.PermissionManageCategories),
+		validators.ValidateCategoryPayload(constants.RouteName.CREATE_CATEGORY),
+		categoryControllers.CreateCategory,
+	)
+	categoriesApi.Put("/:id",
+		middlewares.AuthorizeScope(models.AccessTokenScopeCategoriesWrite),
+		middlewares.RequirePermission(models.PermissionManageCategories),
+		validators.ValidateCategoryParams(constants.RouteName.UPDATE_CATEGORY),
+		validators.ValidateCategoryPayload(constants.RouteName.UPDATE_CATEGORY),
+		categoryControllers.UpdateCategory,
+	)
+	categoriesApi.Delete("/:id",
+		middlewares.AuthorizeScope(models.AccessTokenScopeCategoriesWrite),
+		middlewares.RequirePermission(models.PermissionManageCategories),
+		validators.ValidateCategoryParams(constants.RouteName.DELETE_CATEGORY),
+		categoryControllers.DeleteCategory,
+	)
+
+	// /api/users
+	usersApi := api.Group("/users")
+	usersApi.Get(
+		"/by-handle/:handle",
+		validators.ValidateUserParams(constants.RouteName.GET_USER_BY_HANDLE),
+		userControllers.GetUserByHandle,
+	)
+	usersApi.Get(
+		"/:id",
+		validators.ValidateUserParams(constants.RouteName.GET_USER),
+		userControllers.GetUser,
+	)
+	usersApi.Get(
+		"/:id/blogs",
+		validators.ValidateBlogParams(constants.RouteName.GET_USER_BLOGS),
+		validators.ValidateBlogQuery(constants.RouteName.GET_USER_BLOGS),
+		blogControllers.GetUserBlogs,
+	)
+
+	// /api/me
+	meApi := api.Group("/me")
+	meApi.Patch(
+		"/",
+		middlewares.AuthorizeUser,
+		validators.ValidateUserPayload(constants.RouteName.UPDATE_ME),
+		userControllers.UpdateMe,
+	)
+	meApi.Post(
+		"/password",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserPayload(constants.RouteName.CHANGE_PASSWORD),
+		userControllers.ChangePassword,
+	)
+	meApi.Post(
+		"/email",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserPayload(constants.RouteName.CHANGE_EMAIL),
+		userControllers.ChangeEmail,
+	)
+	meApi.Post(
+		"/2fa/totp",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		userControllers.EnrolTOTP,
+	)
+	meApi.Post(
+		"/2fa/totp/confirm",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserPayload(constants.RouteName.CONFIRM_TOTP),
+		userControllers.ConfirmTOTP,
+	)
+	meApi.Delete(
+		"/2fa/totp",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserPayload(constants.RouteName.DISABLE_TOTP),
+		userControllers.DisableTOTP,
+	)
+	meApi.Get("/sessions", middlewares.AuthorizeUser, userControllers.GetMySessions)
+	meApi.Delete(
+		"/sessions",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserQuery(constants.RouteName.DELETE_MY_SESSIONS),
+		userControllers.DeleteMySessions,
+	)
+	meApi.Delete(
+		"/sessions/:id",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserParams(constants.RouteName.DELETE_MY_SESSION),
+		userControllers.DeleteMySession,
+	)
+	meApi.Get("/passkeys", middlewares.AuthorizeUser, authControllers.GetMyPasskeys)
+	meApi.Post(
+		"/passkeys/register/begin",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		authControllers.BeginPasskeyRegistration,
+	)
+	meApi.Post(
+		"/passkeys/register/finish",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserQuery(constants.RouteName.FINISH_PASSKEY_REGISTRATION),
+		authControllers.FinishPasskeyRegistration,
+	)
+	meApi.Delete(
+		"/passkeys/:id",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserParams(constants.RouteName.DELETE_PASSKEY),
+		authControllers.DeletePasskey,
+	)
+	meApi.Get(
+		"/blogs",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsRead),
+		validators.ValidateBlogQuery(constants.RouteName.GET_MY_BLOGS),
+		blogControllers.GetMyBlogs,
+	)
+	meApi.Get(
+		"/trash",
+		middlewares.AuthorizeScope(models.AccessTokenScopeBlogsRead),
+		validators.ValidateBlogQuery(constants.RouteName.GET_MY_TRASH),
+		blogControllers.GetMyTrash,
+	)
+	meApi.Get("/tokens", middlewares.AuthorizeUser, userControllers.GetMyAccessTokens)
+	meApi.Post(
+		"/tokens",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserPayload(constants.RouteName.CREATE_ACCESS_TOKEN),
+		userControllers.CreateAccessToken,
+	)
+	meApi.Delete(
+		"/tokens/:id",
+		middlewares.AuthorizeUser,
+		middlewares.ForbidImpersonation,
+		validators.ValidateUserParams(constants.RouteName.DELETE_ACCESS_TOKEN),
+		userControllers.DeleteAccessToken,
+	)
+
+	// /api/admin
+	adminApi := api.Group("/admin", middlewares.AuthorizeUser, middlewares.ForbidImpersonation)
+	adminApi.Get(
+		"/users",
+		middlewares.RequirePermission(models.PermissionManageUsers),
+		validators.ValidateAdminQuery(constants.RouteName.GET_USERS),
+		adminControllers.GetUsers,
+	)
+	adminApi.Get(
+		"/users/:id/stats",
+		middlewares.RequirePermission(models.PermissionManageUsers),
+		validators.ValidateAdminParams(constants.RouteName.GET_USER_STATS),
+		adminControllers.GetUserStats,
+	)
+	adminApi.Put(
+		"/users/:id/role",
+		middlewares.RequirePermission(models.PermissionManageRoles),
+		validators.ValidateAdminParams(constants.RouteName.UPDATE_USER_ROLE),
+		validators.ValidateAdminPayload(constants.RouteName.UPDATE_USER_ROLE),
+		adminControllers.UpdateUserRole,
+	)
+	adminApi.Post(
+		"/users/:id/suspend",
+		middlewares.RequirePermission(models.PermissionManageUsers),
+		validators.ValidateAdminParams(constants.RouteName.SUSPEND_USER),
+		validators.ValidateAdminPayload(constants.RouteName.SUSPEND_USER),
+		adminControllers.SuspendUser,
+	)
+	adminApi.Post(
+		"/users/:id/unsuspend",
+		middlewares.RequirePermission(models.PermissionManageUsers),
+		validators.ValidateAdminParams(constants.RouteName.UNSUSPEND_USER),
+		adminControllers.UnsuspendUser,
+	)
+	adminApi.Post(
+		"/users/:id/password-reset",
+		middlewares.RequirePermission(models.PermissionManageUsers),
+		validators.ValidateAdminParams(constants.RouteName.FORCE_PASSWORD_RESET),
+		adminControllers.ForcePasswordReset,
+	)
+	adminApi.Post(
+		"/users/:id/impersonate",
+		middlewares.RequirePermission(models.PermissionImpersonateUsers),
+		validators.ValidateAdminParams(constants.RouteName.IMPERSONATE_USER),
+		adminControllers.ImpersonateUser,
+	)
+	adminApi.Get(
+		"/audit-logs",
+		middlewares.RequirePermission(models.PermissionManageUsers),
+		validators.ValidateAdminQuery(constants.RouteName.GET_AUDIT_LOGS),
+		adminControllers.GetAuditLogs,
+	)
 }
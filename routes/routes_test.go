@@ -0,0 +1,698 @@
+package routes_test
+
+import (
+	"context"
+	"go_blogs/configs"
+	"go_blogs/libs"
+	"go_blogs/models"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pquerna/otp/totp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// routeActor is who sends the requests of a row of the route matrix
+type routeActor struct {
+	name      string
+	anonymous bool
+	role      models.Role
+	// granted are permissions granted on top of those of the role
+	granted []models.Permission
+	// author actors have written the blogs of the fixture
+	author bool
+	// impersonated actors are an admin's session impersonating a user with role
+	impersonated bool
+}
+
+func (a routeActor) can(permission models.Permission) bool {
+	if a.anonymous {
+		return false
+	}
+	for _, p := range append(a.role.Permissions(), a.granted...) {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func routeActors() []routeActor {
+	actors := []routeActor{
+		{name: "anonymous", anonymous: true},
+		{name: "user", role: models.RoleUser},
+		{name: "editor", role: models.RoleEditor},
+		{name: "moderator", role: models.RoleModerator},
+		{name: "admin", role: models.RoleAdmin},
+		{name: "author", role: models.RoleUser, author: true},
+		{name: "admin impersonating user", role: models.RoleUser, impersonated: true},
+	}
+	for _, permission := range models.Permissions {
+		actors = append(actors, routeActor{
+			name:    "user granted " + string(permission),
+			role:    models.RoleUser,
+			granted: []models.Permission{permission},
+		})
+	}
+	return actors
+}
+
+// routeFixture is what a row of the route matrix runs against. Everything is created for the row alone
+type routeFixture struct {
+	app *testApp
+	// actor is the user behind the actor of the row. Anonymous rows have one too, for the routes that need a user
+	actor testUser
+	// author wrote the blogs of the fixture. It is the actor in the rows of author actors
+	author testUser
+}
+
+// session logs the actor in on a session of their own, for setting up what the request of the row needs
+func (f *routeFixture) session() []*http.Cookie {
+	return f.app.loginCookies(f.actor)
+}
+
+type fixtureBlog struct {
+	ID      string
+	Slug    string
+	Version int
+}
+
+// draftBlog is a draft of the author with two revisions
+func (f *routeFixture) draftBlog() fixtureBlog {
+	app := f.app
+	app.t.Helper()
+	cookies := app.loginCookies(f.author)
+	resp := app.do(testRequest{
+		method:  http.MethodPost,
+		path:    "/api/blogs",
+		body:    map[string]string{"title": "A blog about routes", "content": "first draft"},
+		cookies: cookies,
+	})
+	if resp.StatusCode != http.StatusCreated {
+		app.t.Fatalf("create blog: %d %s", resp.StatusCode, resp.body)
+	}
+	var created models.CreatedResponse
+	resp.decode(app.t, &created)
+
+	resp = app.do(testRequest{
+		method:  http.MethodPut,
+		path:    "/api/blogs/" + created.ID,
+		body:    map[string]string{"title": "A blog about routes", "content": "second draft"},
+		headers: map[string]string{fiber.HeaderIfMatch: libs.FormatETag(1)},
+		cookies: cookies,
+	})
+	if resp.StatusCode != http.StatusOK {
+		app.t.Fatalf("update blog: %d %s", resp.StatusCode, resp.body)
+	}
+
+	blogObjectID, _ := primitive.ObjectIDFromHex(created.ID)
+	var blog models.Blog
+	if err := app.database.Collection("blogs").FindOne(context.TODO(), bson.M{"_id": blogObjectID}).Decode(&blog); err != nil {
+		app.t.Fatal(err)
+	}
+	return fixtureBlog{ID: blog.ID, Slug: blog.Slug, Version: blog.Version}
+}
+
+// publishedBlog is a blog of the author that an editor has published
+func (f *routeFixture) publishedBlog() fixtureBlog {
+	blog := f.draftBlog()
+	f.app.mustDo(http.StatusOK, testRequest{
+		method:  http.MethodPost,
+		path:    "/api/blogs/" + blog.ID + "/publish",
+		cookies: f.app.loginCookies(f.app.createUser(models.RoleEditor)),
+	})
+	return blog
+}
+
+// trashedBlog is a blog of the author in the trash
+func (f *routeFixture) trashedBlog() fixtureBlog {
+	blog := f.draftBlog()
+	f.app.mustDo(http.StatusOK, testRequest{
+		method:  http.MethodDelete,
+		path:    "/api/blogs/" + blog.ID,
+		headers: map[string]string{fiber.HeaderIfMatch: libs.FormatETag(blog.Version)},
+		cookies: f.app.loginCookies(f.author),
+	})
+	return blog
+}
+
+func (f *routeFixture) category() string {
+	admin := f.app.createUser(models.RoleAdmin)
+	resp := f.app.mustDo(http.StatusCreated, testRequest{
+		method:  http.MethodPost,
+		path:    "/api/categories",
+		body:    map[string]string{"name": categoryName()},
+		cookies: f.app.loginCookies(admin),
+	})
+	var created models.CreatedResponse
+	resp.decode(f.app.t, &created)
+	return created.ID
+}
+
+// enrolTOTP starts two-factor authentication for the actor and returns the secret of their authenticator app
+func (f *routeFixture) enrolTOTP() string {
+	resp := f.app.mustDo(http.StatusOK, testRequest{method: http.MethodPost, path: "/api/me/2fa/totp", cookies: f.session()})
+	var enrolment models.TOTPEnrolment
+	resp.decode(f.app.t, &enrolment)
+	return enrolment.Secret
+}
+
+// enableTOTP turns two-factor authentication on for the actor and returns their recovery codes
+func (f *routeFixture) enableTOTP() []string {
+	secret := f.enrolTOTP()
+	resp := f.app.mustDo(http.StatusOK, testRequest{
+		method:  http.MethodPost,
+		path:    "/api/me/2fa/totp/confirm",
+		body:    map[string]string{"code": totpCode(f.app.t, secret)},
+		cookies: f.session(),
+	})
+	var recoveryCodes models.RecoveryCodes
+	resp.decode(f.app.t, &recoveryCodes)
+	return recoveryCodes.RecoveryCodes
+}
+
+func totpCode(t *testing.T, secret string) string {
+	code, err := totp.GenerateCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func (app *testApp) mustDo(wantStatus int, request testRequest) *testResponse {
+	app.t.Helper()
+	resp := app.do(request)
+	if resp.StatusCode != wantStatus {
+		app.t.Fatalf("%s %s: %d %s, want %d", request.method, request.path, resp.StatusCode, resp.body, wantStatus)
+	}
+	return resp
+}
+
+// routeCase is a route of InitRoute and who may use it
+type routeCase struct {
+	route string
+	// public routes need no login
+	public bool
+	// selfOnly routes are refused to sessions impersonating a user
+	selfOnly bool
+	// permission is required by RequirePermission. With blogPolicy, it is the permission of the policy that lets
+	// users other than the author through
+	permission models.Permission
+	blogPolicy bool
+	// notForAuthors blog routes need the permission from the author too
+	notForAuthors bool
+	// deniedStatus is the status of users the blog policy refuses. Blogs one may not view are not found
+	deniedStatus int
+	// impersonationOnly routes are only of use to sessions impersonating a user, and are a conflict otherwise
+	impersonationOnly bool
+	okStatus          int
+	// request builds the request of the row, setting up what it needs. Its cookies are those of the actor
+	request func(f *routeFixture) testRequest
+}
+
+// wantStatus is the status the actor gets from the route, and the message of the error when it is refused
+func (rc routeCase) wantStatus(actor routeActor) (int, string) {
+	switch {
+	case actor.anonymous && !rc.public:
+		return http.StatusUnauthorized, "Unauthorized"
+	case actor.impersonated && rc.selfOnly:
+		return http.StatusForbidden, "Not allowed while impersonating a user"
+	case rc.impersonationOnly && !actor.impersonated:
+		return http.StatusConflict, "You are not impersonating a user"
+	case rc.permission == "" || actor.can(rc.permission):
+		return rc.okStatus, ""
+	case rc.blogPolicy && actor.author && !rc.notForAuthors:
+		return rc.okStatus, ""
+	case rc.blogPolicy && rc.deniedStatus == http.StatusNotFound:
+		return http.StatusNotFound, "Blog not found"
+	case rc.blogPolicy:
+		return http.StatusConflict, "Access Denied"
+	default:
+		return http.StatusForbidden, "Access Denied"
+	}
+}
+
+func blogRoute(route string, permission models.Permission, okStatus int, request func(f *routeFixture) testRequest) routeCase {
+	return routeCase{route: route, permission: permission, blogPolicy: true, okStatus: okStatus, request: request}
+}
+
+// notForAuthors makes blog route rc need its permission from authors too
+func notForAuthors(rc routeCase) routeCase {
+	rc.notForAuthors = true
+	return rc
+}
+
+func userRoute(route string, okStatus int, request func(f *routeFixture) testRequest) routeCase {
+	return routeCase{route: route, okStatus: okStatus, request: request}
+}
+
+func selfRoute(route string, okStatus int, request func(f *routeFixture) testRequest) routeCase {
+	return routeCase{route: route, selfOnly: true, okStatus: okStatus, request: request}
+}
+
+func publicRoute(route string, okStatus int, request func(f *routeFixture) testRequest) routeCase {
+	return routeCase{route: route, public: true, okStatus: okStatus, request: request}
+}
+
+func adminRoute(route string, permission models.Permission, okStatus int, request func(f *routeFixture) testRequest) routeCase {
+	return routeCase{route: route, selfOnly: true, permission: permission, okStatus: okStatus, request: request}
+}
+
+func get(path string) testRequest {
+	return testRequest{method: http.MethodGet, path: path}
+}
+
+func post(path string, body interface{}) testRequest {
+	return testRequest{method: http.MethodPost, path: path, body: body}
+}
+
+func ifMatch(request testRequest, version int) testRequest {
+	request.headers = map[string]string{fiber.HeaderIfMatch: libs.FormatETag(version)}
+	return request
+}
+
+// categoryName is a name, and so a slug, no other category has
+func categoryName() string {
+	return "Category " + primitive.NewObjectID().Hex()
+}
+
+func newEmail() string {
+	return primitive.NewObjectID().Hex() + "@example.com"
+}
+
+// routeCases covers every route of InitRoute
+func routeCases() []routeCase {
+	return []routeCase{
+		publicRoute("GET /.well-known/jwks.json", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/.well-known/jwks.json")
+		}),
+
+		// /api/auth
+		publicRoute("POST /api/auth/login", http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/auth/login", map[string]string{"email": f.actor.Email, "password": testPassword})
+		}),
+		publicRoute("POST /api/auth/register", http.StatusCreated, func(f *routeFixture) testRequest {
+			return post("/api/auth/register", map[string]string{"email": newEmail(), "password": testPassword, "name": "New user"})
+		}),
+		publicRoute("POST /api/auth/login/2fa", http.StatusOK, func(f *routeFixture) testRequest {
+			recoveryCodes := f.enableTOTP()
+			resp := f.app.mustDo(http.StatusAccepted, post("/api/auth/login", map[string]string{"email": f.actor.Email, "password": testPassword}))
+			var challenge models.LoginChallenge
+			resp.decode(f.app.t, &challenge)
+			return post("/api/auth/login/2fa", map[string]string{"challenge": challenge.Challenge, "recoveryCode": recoveryCodes[0]})
+		}),
+		publicRoute("POST /api/auth/passkey/begin", http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/auth/passkey/begin", nil)
+		}),
+		publicRoute("POST /api/auth/passkey/finish", http.StatusOK, func(f *routeFixture) testRequest {
+			authenticator := newSoftwareAuthenticator(f.app.t)
+			f.app.registerPasskey(f.actor, authenticator)
+			authenticator.signCount++
+			return post("/api/auth/passkey/finish", authenticator.get(f.app.beginPasskeyCeremony("/api/auth/passkey/begin", nil)))
+		}),
+		publicRoute("POST /api/auth/verify-email", http.StatusOK, func(f *routeFixture) testRequest {
+			email := newEmail()
+			f.app.mustDo(http.StatusCreated, post("/api/auth/register", map[string]string{"email": email, "password": testPassword, "name": "New user"}))
+			return post("/api/auth/verify-email", map[string]string{"token": f.app.mailer.lastLinkToken(f.app.t, email)})
+		}),
+		publicRoute("POST /api/auth/verify-email/resend", http.StatusAccepted, func(f *routeFixture) testRequest {
+			return post("/api/auth/verify-email/resend", map[string]string{"email": f.actor.Email})
+		}),
+		publicRoute("POST /api/auth/forgot-password", http.StatusAccepted, func(f *routeFixture) testRequest {
+			return post("/api/auth/forgot-password", map[string]string{"email": f.actor.Email})
+		}),
+		publicRoute("POST /api/auth/reset-password", http.StatusOK, func(f *routeFixture) testRequest {
+			f.app.mustDo(http.StatusAccepted, post("/api/auth/forgot-password", map[string]string{"email": f.actor.Email}))
+			token := f.app.mailer.lastLinkToken(f.app.t, f.actor.Email)
+			return post("/api/auth/reset-password", map[string]string{"token": token, "password": "new-password"})
+		}),
+		publicRoute("POST /api/auth/unlock", http.StatusOK, func(f *routeFixture) testRequest {
+			for i := int64(0); i < configs.Env.LoginLockoutThreshold; i++ {
+				f.app.mustDo(http.StatusBadRequest, post("/api/auth/login", map[string]string{"email": f.actor.Email, "password": "wrong-password"}))
+			}
+			return post("/api/auth/unlock", map[string]string{"token": f.app.mailer.lastLinkToken(f.app.t, f.actor.Email)})
+		}),
+		publicRoute("POST /api/auth/token/refresh", http.StatusOK, func(f *routeFixture) testRequest {
+			// refresh tokens are only issued when AUTH_MODE is jwt
+			configs.Env.AuthMode = libs.AuthModeJWT
+			libs.InitJWT()
+			defer func() { configs.Env.AuthMode = libs.AuthModeSession }()
+			resp := f.app.mustDo(http.StatusOK, post("/api/auth/login", map[string]string{"email": f.actor.Email, "password": testPassword}))
+			var tokens models.TokenPair
+			resp.decode(f.app.t, &tokens)
+			return post("/api/auth/token/refresh", map[string]string{"refreshToken": tokens.RefreshToken})
+		}),
+		userRoute("POST /api/auth/logout", http.StatusNoContent, func(f *routeFixture) testRequest {
+			return post("/api/auth/logout", nil)
+		}),
+		{
+			route:             "POST /api/auth/impersonation/stop",
+			impersonationOnly: true,
+			okStatus:          http.StatusOK,
+			request: func(f *routeFixture) testRequest {
+				return post("/api/auth/impersonation/stop", nil)
+			},
+		},
+		userRoute("GET /api/auth/user", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/auth/user")
+		}),
+		publicRoute("POST /api/auth/email-change/confirm", http.StatusOK, func(f *routeFixture) testRequest {
+			email := newEmail()
+			f.app.mustDo(http.StatusAccepted, testRequest{
+				method:  http.MethodPost,
+				path:    "/api/me/email",
+				body:    map[string]string{"email": email, "password": testPassword},
+				cookies: f.session(),
+			})
+			return post("/api/auth/email-change/confirm", map[string]string{"token": f.app.mailer.lastLinkToken(f.app.t, email)})
+		}),
+
+		// /api/blogs
+		publicRoute("GET /api/blogs", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/blogs")
+		}),
+		publicRoute("GET /api/blogs/search", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/blogs/search?q=routes")
+		}),
+		{
+			route:        "GET /api/blogs/by-slug/:slug",
+			public:       true,
+			permission:   models.PermissionViewAnyBlog,
+			blogPolicy:   true,
+			deniedStatus: http.StatusNotFound,
+			okStatus:     http.StatusOK,
+			request: func(f *routeFixture) testRequest {
+				return get("/api/blogs/by-slug/" + f.draftBlog().Slug)
+			},
+		},
+		{
+			route:        "GET /api/blogs/:id",
+			public:       true,
+			permission:   models.PermissionViewAnyBlog,
+			blogPolicy:   true,
+			deniedStatus: http.StatusNotFound,
+			okStatus:     http.StatusOK,
+			request: func(f *routeFixture) testRequest {
+				return get("/api/blogs/" + f.draftBlog().ID)
+			},
+		},
+		userRoute("POST /api/blogs", http.StatusCreated, func(f *routeFixture) testRequest {
+			return post("/api/blogs", map[string]string{"title": "Another blog about routes", "content": "content"})
+		}),
+		blogRoute("PUT /api/blogs/:id", models.PermissionEditAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			blog := f.draftBlog()
+			return ifMatch(testRequest{
+				method: http.MethodPut,
+				path:   "/api/blogs/" + blog.ID,
+				body:   map[string]string{"title": "A blog about routes", "content": "third draft"},
+			}, blog.Version)
+		}),
+		blogRoute("DELETE /api/blogs/:id", models.PermissionDeleteAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			blog := f.draftBlog()
+			return ifMatch(testRequest{method: http.MethodDelete, path: "/api/blogs/" + blog.ID}, blog.Version)
+		}),
+		blogRoute("POST /api/blogs/:id/submit", models.PermissionPublishAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/blogs/"+f.draftBlog().ID+"/submit", nil)
+		}),
+		notForAuthors(blogRoute("POST /api/blogs/:id/publish", models.PermissionPublishAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/blogs/"+f.draftBlog().ID+"/publish", nil)
+		})),
+		blogRoute("POST /api/blogs/:id/unpublish", models.PermissionPublishAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/blogs/"+f.publishedBlog().ID+"/unpublish", nil)
+		}),
+		blogRoute("POST /api/blogs/:id/archive", models.PermissionPublishAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/blogs/"+f.draftBlog().ID+"/archive", nil)
+		}),
+		blogRoute("POST /api/blogs/:id/restore", models.PermissionDeleteAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/blogs/"+f.trashedBlog().ID+"/restore", nil)
+		}),
+		blogRoute("GET /api/blogs/:id/revisions", models.PermissionEditAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/blogs/" + f.draftBlog().ID + "/revisions")
+		}),
+		blogRoute("GET /api/blogs/:id/revisions/diff", models.PermissionEditAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/blogs/" + f.draftBlog().ID + "/revisions/diff?from=1&to=2")
+		}),
+		blogRoute("GET /api/blogs/:id/revisions/:rev", models.PermissionEditAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/blogs/" + f.draftBlog().ID + "/revisions/1")
+		}),
+		blogRoute("POST /api/blogs/:id/revisions/:rev/restore", models.PermissionEditAnyBlog, http.StatusOK, func(f *routeFixture) testRequest {
+			blog := f.draftBlog()
+			return ifMatch(post("/api/blogs/"+blog.ID+"/revisions/1/restore", nil), blog.Version)
+		}),
+
+		publicRoute("GET /api/tags", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/tags")
+		}),
+
+		// /api/categories
+		publicRoute("GET /api/categories", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/categories")
+		}),
+		{
+			route:      "POST /api/categories",
+			permission: models.PermissionManageCategories,
+			okStatus:   http.StatusCreated,
+			request: func(f *routeFixture) testRequest {
+				return post("/api/categories", map[string]string{"name": categoryName()})
+			},
+		},
+		{
+			route:      "PUT /api/categories/:id",
+			permission: models.PermissionManageCategories,
+			okStatus:   http.StatusOK,
+			request: func(f *routeFixture) testRequest {
+				return testRequest{method: http.MethodPut, path: "/api/categories/" + f.category(), body: map[string]string{"name": categoryName()}}
+			},
+		},
+		{
+			route:      "DELETE /api/categories/:id",
+			permission: models.PermissionManageCategories,
+			okStatus:   http.StatusOK,
+			request: func(f *routeFixture) testRequest {
+				return testRequest{method: http.MethodDelete, path: "/api/categories/" + f.category()}
+			},
+		},
+
+		// /api/users
+		publicRoute("GET /api/users/by-handle/:handle", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/users/by-handle/user-" + f.author.ID)
+		}),
+		publicRoute("GET /api/users/:id", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/users/" + f.author.ID)
+		}),
+		publicRoute("GET /api/users/:id/blogs", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/users/" + f.author.ID + "/blogs")
+		}),
+
+		// /api/me
+		userRoute("PATCH /api/me", http.StatusOK, func(f *routeFixture) testRequest {
+			return testRequest{method: http.MethodPatch, path: "/api/me", body: map[string]string{"name": "Renamed"}}
+		}),
+		selfRoute("POST /api/me/password", http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/me/password", map[string]string{"currentPassword": testPassword, "newPassword": "new-password"})
+		}),
+		selfRoute("POST /api/me/email", http.StatusAccepted, func(f *routeFixture) testRequest {
+			return post("/api/me/email", map[string]string{"email": newEmail(), "password": testPassword})
+		}),
+		selfRoute("POST /api/me/2fa/totp", http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/me/2fa/totp", nil)
+		}),
+		selfRoute("POST /api/me/2fa/totp/confirm", http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/me/2fa/totp/confirm", map[string]string{"code": totpCode(f.app.t, f.enrolTOTP())})
+		}),
+		selfRoute("DELETE /api/me/2fa/totp", http.StatusOK, func(f *routeFixture) testRequest {
+			recoveryCodes := f.enableTOTP()
+			return testRequest{
+				method: http.MethodDelete,
+				path:   "/api/me/2fa/totp",
+				body:   map[string]string{"password": testPassword, "recoveryCode": recoveryCodes[0]},
+			}
+		}),
+		userRoute("GET /api/me/sessions", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/me/sessions")
+		}),
+		selfRoute("DELETE /api/me/sessions", http.StatusNoContent, func(f *routeFixture) testRequest {
+			return testRequest{method: http.MethodDelete, path: "/api/me/sessions?exceptCurrent=true"}
+		}),
+		selfRoute("DELETE /api/me/sessions/:id", http.StatusOK, func(f *routeFixture) testRequest {
+			resp := f.app.mustDo(http.StatusOK, testRequest{method: http.MethodGet, path: "/api/me/sessions", cookies: f.session()})
+			var sessions []models.UserSession
+			resp.decode(f.app.t, &sessions)
+			for _, session := range sessions {
+				if session.Current {
+					return testRequest{method: http.MethodDelete, path: "/api/me/sessions/" + session.ID}
+				}
+			}
+			f.app.t.Fatalf("no current session in %s", resp.body)
+			return testRequest{}
+		}),
+		userRoute("GET /api/me/passkeys", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/me/passkeys")
+		}),
+		selfRoute("POST /api/me/passkeys/register/begin", http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/me/passkeys/register/begin", nil)
+		}),
+		selfRoute("POST /api/me/passkeys/register/finish", http.StatusCreated, func(f *routeFixture) testRequest {
+			options := f.app.beginPasskeyCeremony("/api/me/passkeys/register/begin", f.session())
+			return post("/api/me/passkeys/register/finish", newSoftwareAuthenticator(f.app.t).create(options))
+		}),
+		selfRoute("DELETE /api/me/passkeys/:id", http.StatusOK, func(f *routeFixture) testRequest {
+			f.app.registerPasskey(f.actor, newSoftwareAuthenticator(f.app.t))
+			resp := f.app.mustDo(http.StatusOK, testRequest{method: http.MethodGet, path: "/api/me/passkeys", cookies: f.session()})
+			var passkeys []models.Passkey
+			resp.decode(f.app.t, &passkeys)
+			return testRequest{method: http.MethodDelete, path: "/api/me/passkeys/" + passkeys[0].ID}
+		}),
+		userRoute("GET /api/me/blogs", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/me/blogs")
+		}),
+		userRoute("GET /api/me/trash", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/me/trash")
+		}),
+		userRoute("GET /api/me/tokens", http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/me/tokens")
+		}),
+		selfRoute("POST /api/me/tokens", http.StatusCreated, func(f *routeFixture) testRequest {
+			return post("/api/me/tokens", map[string]interface{}{"name": "CI", "scopes": []string{"blogs:read"}})
+		}),
+		selfRoute("DELETE /api/me/tokens/:id", http.StatusOK, func(f *routeFixture) testRequest {
+			resp := f.app.mustDo(http.StatusCreated, testRequest{
+				method:  http.MethodPost,
+				path:    "/api/me/tokens",
+				body:    map[string]interface{}{"name": "CI", "scopes": []string{"blogs:read"}},
+				cookies: f.session(),
+			})
+			var created models.CreatedAccessToken
+			resp.decode(f.app.t, &created)
+			return testRequest{method: http.MethodDelete, path: "/api/me/tokens/" + created.ID}
+		}),
+
+		// /api/admin
+		adminRoute("GET /api/admin/users", models.PermissionManageUsers, http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/admin/users")
+		}),
+		adminRoute("GET /api/admin/users/:id/stats", models.PermissionManageUsers, http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/admin/users/" + f.author.ID + "/stats")
+		}),
+		adminRoute("PUT /api/admin/users/:id/role", models.PermissionManageRoles, http.StatusOK, func(f *routeFixture) testRequest {
+			return testRequest{method: http.MethodPut, path: "/api/admin/users/" + f.author.ID + "/role", body: map[string]interface{}{
+				"role":        "user",
+				"permissions": []models.Permission{models.PermissionManageRoles},
+			}}
+		}),
+		adminRoute("POST /api/admin/users/:id/suspend", models.PermissionManageUsers, http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/admin/users/"+f.author.ID+"/suspend", map[string]string{"reason": "spam"})
+		}),
+		adminRoute("POST /api/admin/users/:id/unsuspend", models.PermissionManageUsers, http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/admin/users/"+f.author.ID+"/unsuspend", nil)
+		}),
+		adminRoute("POST /api/admin/users/:id/password-reset", models.PermissionManageUsers, http.StatusAccepted, func(f *routeFixture) testRequest {
+			return post("/api/admin/users/"+f.author.ID+"/password-reset", nil)
+		}),
+		adminRoute("POST /api/admin/users/:id/impersonate", models.PermissionImpersonateUsers, http.StatusOK, func(f *routeFixture) testRequest {
+			return post("/api/admin/users/"+f.author.ID+"/impersonate", nil)
+		}),
+		adminRoute("GET /api/admin/audit-logs", models.PermissionManageUsers, http.StatusOK, func(f *routeFixture) testRequest {
+			return get("/api/admin/audit-logs")
+		}),
+	}
+}
+
+// newRouteFixture creates the users of a row, and logs the actor in unless they are anonymous
+func newRouteFixture(app *testApp, actor routeActor) (*routeFixture, []*http.Cookie) {
+	app.t.Helper()
+	f := &routeFixture{app: app, actor: app.createUser(actor.role, actor.granted...)}
+	f.author = app.createUser(models.RoleUser)
+	if actor.author {
+		f.author = f.actor
+	}
+
+	switch {
+	case actor.anonymous:
+		return f, nil
+	case actor.impersonated:
+		cookies := app.loginCookies(app.createUser(models.RoleAdmin))
+		app.mustDo(http.StatusOK, testRequest{
+			method:  http.MethodPost,
+			path:    "/api/admin/users/" + f.actor.ID + "/impersonate",
+			cookies: cookies,
+		})
+		return f, cookies
+	default:
+		return f, app.loginCookies(f.actor)
+	}
+}
+
+func TestRouteCasesCoverEveryRoute(t *testing.T) {
+	app := newTestApp(t, nil)
+
+	covered := map[string]bool{}
+	for _, rc := range routeCases() {
+		if covered[rc.route] {
+			t.Errorf("%s is in the route matrix twice", rc.route)
+		}
+		covered[rc.route] = true
+	}
+	for _, route := range app.GetRoutes(true) {
+		// fiber answers HEAD with the GET handlers
+		if route.Method == http.MethodHead {
+			continue
+		}
+		name := route.Method + " " + strings.TrimSuffix(route.Path, "/")
+		if !covered[name] {
+			t.Errorf("%s is not in the route matrix", name)
+		}
+		delete(covered, name)
+	}
+	for name := range covered {
+		t.Errorf("%s is in the route matrix but not in InitRoute", name)
+	}
+}
+
+// TestRouteAccess sends every route of InitRoute as every kind of user, and checks who gets through each
+// RequirePermission, ForbidImpersonation and blog policy and who is turned away
+func TestRouteAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the route matrix is slow")
+	}
+	for _, rc := range routeCases() {
+		rc := rc
+		t.Run(rc.route, func(t *testing.T) {
+			app := newTestApp(t, func(env *models.EnvVar) {
+				// every row logs in from the same address, and the unlock rows fail logins on purpose
+				env.LoginBackoffAfter = 1000
+				env.LoginLockoutThreshold = 3
+				env.LoginIPLockoutThreshold = 1000
+			})
+			for _, actor := range routeActors() {
+				actor := actor
+				t.Run(actor.name, func(t *testing.T) {
+					parent := app.t
+					app.t = t
+					defer func() { app.t = parent }()
+
+					f, cookies := newRouteFixture(app, actor)
+					request := rc.request(f)
+					request.cookies = cookies
+					resp := app.do(request)
+
+					wantStatus, wantMessage := rc.wantStatus(actor)
+					if resp.StatusCode != wantStatus {
+						t.Fatalf("%s: %d %s, want %d", rc.route, resp.StatusCode, resp.body, wantStatus)
+					}
+					if wantMessage != "" {
+						var errorResponse models.ErrorResponse
+						resp.decode(t, &errorResponse)
+						if errorResponse.Message != wantMessage {
+							t.Fatalf("%s: message %q, want %q", rc.route, errorResponse.Message, wantMessage)
+						}
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package routes_test
+
+import (
+	"context"
+	"go_blogs/models"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestConfirmEmailChangeRefusesTakenEmails(t *testing.T) {
+	app := newTestApp(t, nil)
+	first := app.createUser(models.RoleUser)
+	second := app.createUser(models.RoleUser)
+	email := "taken@example.com"
+
+	// both links are sent before either is confirmed
+	var tokens []string
+	for _, user := range []testUser{first, second} {
+		app.mustDo(http.StatusAccepted, testRequest{
+			method:  http.MethodPost,
+			path:    "/api/me/email",
+			body:    map[string]string{"email": email, "password": testPassword},
+			cookies: app.loginCookies(user),
+		})
+		tokens = append(tokens, app.mailer.lastLinkToken(t, email))
+	}
+
+	confirm := func(token string) *testResponse {
+		return app.do(testRequest{
+			method: http.MethodPost,
+			path:   "/api/auth/email-change/confirm",
+			body:   map[string]string{"token": token},
+		})
+	}
+	if resp := confirm(tokens[0]); resp.StatusCode != http.StatusOK {
+		t.Fatalf("confirming the first link: %d %s", resp.StatusCode, resp.body)
+	}
+	if resp := confirm(tokens[1]); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("confirming the second link: %d %s, want 409", resp.StatusCode, resp.body)
+	}
+
+	count, err := app.database.Collection("users").CountDocuments(context.TODO(), bson.M{"email": email})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("%d users have %s", count, email)
+	}
+
+	// the index refuses duplicates whatever writes them
+	_, err = app.database.Collection("users").InsertOne(context.TODO(), bson.M{"email": email})
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("inserting a user with a taken email: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Document is the searchable part of a published blog
+type Document struct {
+	ID          string
+	Title       string
+	Content     string
+	Tags        []string
+	CreatedBy   string
+	PublishedAt time.Time
+}
+
+// Query describes a search. Text supports "quoted phrases" and prefix* terms; every term and phrase must match
+type Query struct {
+	Text   string
+	Author string
+	Tag    string
+	Since  *time.Time
+	Until  *time.Time
+	Offset int
+	Limit  int
+}
+
+type Hit struct {
+	ID    string
+	Score float64
+	// Title and Snippet are HTML escaped, with matched words wrapped in <mark>
+	Title   string
+	Snippet string
+}
+
+type Result struct {
+	Total int
+	Hits  []Hit
+}
+
+// Backend stores documents and answers queries over them. Implementations must be safe for concurrent use
+type Backend interface {
+	Index(ctx context.Context, document Document) error
+	Remove(ctx context.Context, id string) error
+	Search(ctx context.Context, query Query) (Result, error)
+}
+
+// NewBackend creates the backend configured by name
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case "", "memory":
+		return NewMemoryBackend(), nil
+	}
+	return nil, fmt.Errorf("unknown search backend %q", name)
+}
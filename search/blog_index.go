@@ -0,0 +1,160 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go_blogs/models"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// blogChangesChannel carries the IDs of changed blogs so that every replica refreshes its index
+const blogChangesChannel = "search:blogs"
+
+var Blogs *BlogIndex
+
+// BlogIndex keeps a Backend in sync with the published blogs stored in Mongo
+type BlogIndex struct {
+	Backend       Backend
+	MongoBlogColl *mongo.Collection
+	Redis         *redis.Client
+	instanceID    string
+}
+
+// InitBlogIndex fills Blogs from Mongo and starts listening for blog changes made by other replicas
+func InitBlogIndex(backend Backend, blogColl *mongo.Collection, rds *redis.Client) {
+	Blogs = NewBlogIndex(backend, blogColl, rds)
+	if err := Blogs.Rebuild(context.TODO()); err != nil {
+		panic(err)
+	}
+	go Blogs.Listen(context.Background())
+}
+
+func NewBlogIndex(backend Backend, blogColl *mongo.Collection, rds *redis.Client) *BlogIndex {
+	return &BlogIndex{
+		Backend:       backend,
+		MongoBlogColl: blogColl,
+		Redis:         rds,
+		instanceID:    uuid.NewString(),
+	}
+}
+
+func searchableBlogFilter() bson.M {
+	return bson.M{
+		"status":    models.BlogStatusPublished,
+		"deletedAt": nil,
+	}
+}
+
+func blogDocument(blog *models.Blog) Document {
+	document := Document{
+		ID:        blog.ID,
+		Title:     blog.Title,
+		Content:   blog.Content,
+		Tags:      blog.Tags,
+		CreatedBy: blog.CreatedBy,
+	}
+	if blog.PublishedAt != nil {
+		document.PublishedAt = blog.PublishedAt.Time()
+	} else {
+		document.PublishedAt = blog.CreatedAt.Time()
+	}
+	return document
+}
+
+// Rebuild indexes every searchable blog
+func (ix *BlogIndex) Rebuild(ctx context.Context) error {
+	cursor, err := ix.MongoBlogColl.Find(ctx, searchableBlogFilter())
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	count := 0
+	for cursor.Next(ctx) {
+		var blog models.Blog
+		if err = cursor.Decode(&blog); err != nil {
+			return err
+		}
+		if err = ix.Backend.Index(ctx, blogDocument(&blog)); err != nil {
+			return err
+		}
+		count++
+	}
+	if err = cursor.Err(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Search index built with %d blogs\n", count)
+	return nil
+}
+
+// Refresh indexes the blogs with blogIDs again, or removes them when they are no longer searchable
+func (ix *BlogIndex) Refresh(ctx context.Context, blogIDs ...string) error {
+	for _, blogID := range blogIDs {
+		blogObjectID, err := primitive.ObjectIDFromHex(blogID)
+		if err != nil {
+			return err
+		}
+
+		filter := searchableBlogFilter()
+		filter["_id"] = blogObjectID
+
+		var blog *models.Blog
+		err = ix.MongoBlogColl.FindOne(ctx, filter).Decode(&blog)
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			err = ix.Backend.Remove(ctx, blogID)
+		case err == nil:
+			err = ix.Backend.Index(ctx, blogDocument(blog))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Notify refreshes the blogs with blogIDs and tells the other replicas to do the same.
+// Failures are logged rather than returned since the blogs themselves have already been saved
+func (ix *BlogIndex) Notify(ctx context.Context, blogIDs ...string) {
+	if err := ix.Refresh(ctx, blogIDs...); err != nil {
+		fmt.Println("BlogIndex:", err.Error())
+	}
+	for _, blogID := range blogIDs {
+		if err := ix.Redis.Publish(ctx, blogChangesChannel, ix.instanceID+":"+blogID).Err(); err != nil {
+			fmt.Println("BlogIndex:", err.Error())
+		}
+	}
+}
+
+// Listen refreshes the blogs changed by other replicas until ctx is cancelled
+func (ix *BlogIndex) Listen(ctx context.Context) {
+	subscription := ix.Redis.Subscribe(ctx, blogChangesChannel)
+	defer subscription.Close()
+
+	messages := subscription.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			origin, blogID, found := strings.Cut(message.Payload, ":")
+			if !found || origin == ix.instanceID {
+				continue
+			}
+			if err := ix.Refresh(ctx, blogID); err != nil {
+				fmt.Println("BlogIndex:", err.Error())
+			}
+		}
+	}
+}
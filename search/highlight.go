@@ -0,0 +1,119 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// snippetWords is the length of a snippet, which starts up to snippetLeadWords before its first match
+const (
+	snippetWords     = 30
+	snippetLeadWords = 5
+)
+
+type highlighter struct {
+	terms    map[string]bool
+	prefixes []string
+}
+
+func newHighlighter(clauses []clause) highlighter {
+	h := highlighter{terms: map[string]bool{}}
+	for _, cl := range clauses {
+		if cl.prefix {
+			h.prefixes = append(h.prefixes, cl.terms[0])
+			continue
+		}
+		for _, term := range cl.terms {
+			h.terms[term] = true
+		}
+	}
+	return h
+}
+
+func (h highlighter) matches(term string) bool {
+	if h.terms[term] {
+		return true
+	}
+	for _, prefix := range h.prefixes {
+		if strings.HasPrefix(term, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// all marks every matching word of text
+func (h highlighter) all(text string) string {
+	tokens := Tokenize(text)
+	if len(tokens) == 0 {
+		return html.EscapeString(text)
+	}
+	return html.EscapeString(text[:tokens[0].Start]) +
+		h.render(text, tokens) +
+		html.EscapeString(text[tokens[len(tokens)-1].End:])
+}
+
+// snippet returns the part of text with the most matching words, marked, with an ellipsis where text was cut
+func (h highlighter) snippet(text string) string {
+	tokens := Tokenize(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	var matched []int
+	for i, token := range tokens {
+		if h.matches(token.Term) {
+			matched = append(matched, i)
+		}
+	}
+
+	start, best := 0, 0
+	for i, first := range matched {
+		count := 0
+		for _, next := range matched[i:] {
+			if next >= first+snippetWords-snippetLeadWords {
+				break
+			}
+			count++
+		}
+		if count > best {
+			start, best = first-snippetLeadWords, count
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetWords
+	if end > len(tokens) {
+		end = len(tokens)
+	}
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("… ")
+	}
+	builder.WriteString(h.render(text, tokens[start:end]))
+	if end < len(tokens) {
+		builder.WriteString(" …")
+	} else {
+		builder.WriteString(html.EscapeString(strings.TrimSpace(text[tokens[end-1].End:])))
+	}
+	return builder.String()
+}
+
+// render escapes text from the first to the last of tokens, wrapping matching tokens in <mark>
+func (h highlighter) render(text string, tokens []Token) string {
+	var builder strings.Builder
+	cursor := tokens[0].Start
+	for _, token := range tokens {
+		builder.WriteString(html.EscapeString(text[cursor:token.Start]))
+		word := html.EscapeString(text[token.Start:token.End])
+		if h.matches(token.Term) {
+			builder.WriteString("<mark>" + word + "</mark>")
+		} else {
+			builder.WriteString(word)
+		}
+		cursor = token.End
+	}
+	return builder.String()
+}
@@ -0,0 +1,321 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	fieldTitle = iota
+	fieldContent
+	fieldCount
+)
+
+// fieldWeights makes a match in the title count more than the same match in the content
+var fieldWeights = [fieldCount]float64{fieldTitle: 3, fieldContent: 1}
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// maxPrefixExpansions bounds the words a prefix term is expanded into, most common words first
+	maxPrefixExpansions = 50
+)
+
+// positions lists where a term occurs in each field of a document
+type positions [fieldCount][]int
+
+type memoryDocument struct {
+	Document
+	lengths [fieldCount]int
+	terms   []string
+}
+
+// MemoryBackend is an in-process inverted index ranking documents with BM25.
+// Every replica keeps its own copy, so it needs no external service but is rebuilt on start
+type MemoryBackend struct {
+	mu           sync.RWMutex
+	documents    map[string]*memoryDocument
+	index        map[string]map[string]positions
+	totalLengths [fieldCount]int
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		documents: map[string]*memoryDocument{},
+		index:     map[string]map[string]positions{},
+	}
+}
+
+func (b *MemoryBackend) Index(_ context.Context, document Document) error {
+	fields := [fieldCount][]Token{
+		fieldTitle:   Tokenize(document.Title),
+		fieldContent: Tokenize(document.Content),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(document.ID)
+
+	stored := &memoryDocument{Document: document}
+	for field, tokens := range fields {
+		stored.lengths[field] = len(tokens)
+		b.totalLengths[field] += len(tokens)
+		for _, token := range tokens {
+			postings := b.index[token.Term]
+			if postings == nil {
+				postings = map[string]positions{}
+				b.index[token.Term] = postings
+			}
+			termPositions, seen := postings[document.ID]
+			if !seen {
+				stored.terms = append(stored.terms, token.Term)
+			}
+			termPositions[field] = append(termPositions[field], token.Position)
+			postings[document.ID] = termPositions
+		}
+	}
+	b.documents[document.ID] = stored
+	return nil
+}
+
+func (b *MemoryBackend) Remove(_ context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(id)
+	return nil
+}
+
+func (b *MemoryBackend) remove(id string) {
+	stored := b.documents[id]
+	if stored == nil {
+		return
+	}
+	for _, term := range stored.terms {
+		delete(b.index[term], id)
+		if len(b.index[term]) == 0 {
+			delete(b.index, term)
+		}
+	}
+	for field, length := range stored.lengths {
+		b.totalLengths[field] -= length
+	}
+	delete(b.documents, id)
+}
+
+type scoredDocument struct {
+	document *memoryDocument
+	score    float64
+}
+
+func (b *MemoryBackend) Search(_ context.Context, query Query) (Result, error) {
+	clauses := parseQuery(query.Text)
+	if len(clauses) == 0 {
+		return Result{Hits: []Hit{}}, nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var scores map[string]float64
+	for i, cl := range clauses {
+		matches := b.matchClause(cl)
+		if i == 0 {
+			scores = matches
+			continue
+		}
+		for id, score := range scores {
+			if match, ok := matches[id]; ok {
+				scores[id] = score + match
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	scored := make([]scoredDocument, 0, len(scores))
+	for id, score := range scores {
+		stored := b.documents[id]
+		if matchesFilters(stored.Document, query) {
+			scored = append(scored, scoredDocument{document: stored, score: score})
+		}
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		if !scored[i].document.PublishedAt.Equal(scored[j].document.PublishedAt) {
+			return scored[i].document.PublishedAt.After(scored[j].document.PublishedAt)
+		}
+		return scored[i].document.ID < scored[j].document.ID
+	})
+
+	result := Result{Total: len(scored), Hits: []Hit{}}
+	if query.Offset >= len(scored) {
+		return result, nil
+	}
+	scored = scored[query.Offset:]
+	if query.Limit > 0 && query.Limit < len(scored) {
+		scored = scored[:query.Limit]
+	}
+
+	highlight := newHighlighter(clauses)
+	for _, hit := range scored {
+		result.Hits = append(result.Hits, Hit{
+			ID:      hit.document.ID,
+			Score:   hit.score,
+			Title:   highlight.all(hit.document.Title),
+			Snippet: highlight.snippet(hit.document.Content),
+		})
+	}
+	return result, nil
+}
+
+func matchesFilters(document Document, query Query) bool {
+	if query.Author != "" && document.CreatedBy != query.Author {
+		return false
+	}
+	if query.Tag != "" && !containsString(document.Tags, query.Tag) {
+		return false
+	}
+	if query.Since != nil && document.PublishedAt.Before(*query.Since) {
+		return false
+	}
+	if query.Until != nil && !document.PublishedAt.Before(*query.Until) {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// matchClause returns the score of every document matching cl
+func (b *MemoryBackend) matchClause(cl clause) map[string]float64 {
+	switch {
+	case cl.isPhrase():
+		return b.matchPhrase(cl.terms)
+	case cl.prefix:
+		return b.matchPrefix(cl.terms[0])
+	default:
+		return b.matchTerm(cl.terms[0])
+	}
+}
+
+func (b *MemoryBackend) matchTerm(term string) map[string]float64 {
+	postings := b.index[term]
+	idf := b.idf(len(postings))
+
+	scores := make(map[string]float64, len(postings))
+	for id, termPositions := range postings {
+		var frequencies [fieldCount]int
+		for field := range termPositions {
+			frequencies[field] = len(termPositions[field])
+		}
+		scores[id] = b.bm25(idf, frequencies, b.documents[id])
+	}
+	return scores
+}
+
+// matchPrefix scores a document by its best matching expansion of prefix
+func (b *MemoryBackend) matchPrefix(prefix string) map[string]float64 {
+	var expansions []string
+	for term := range b.index {
+		if strings.HasPrefix(term, prefix) {
+			expansions = append(expansions, term)
+		}
+	}
+	sort.Slice(expansions, func(i, j int) bool {
+		if len(b.index[expansions[i]]) != len(b.index[expansions[j]]) {
+			return len(b.index[expansions[i]]) > len(b.index[expansions[j]])
+		}
+		return expansions[i] < expansions[j]
+	})
+	if len(expansions) > maxPrefixExpansions {
+		expansions = expansions[:maxPrefixExpansions]
+	}
+
+	scores := map[string]float64{}
+	for _, term := range expansions {
+		for id, score := range b.matchTerm(term) {
+			scores[id] = math.Max(scores[id], score)
+		}
+	}
+	return scores
+}
+
+// matchPhrase scores documents containing terms at consecutive positions of the same field
+func (b *MemoryBackend) matchPhrase(terms []string) map[string]float64 {
+	occurrences := map[string][fieldCount]int{}
+	for id, firstPositions := range b.index[terms[0]] {
+		var frequencies [fieldCount]int
+		found := false
+		for field := 0; field < fieldCount; field++ {
+			for _, position := range firstPositions[field] {
+				if b.phraseContinues(id, field, terms[1:], position) {
+					frequencies[field]++
+					found = true
+				}
+			}
+		}
+		if found {
+			occurrences[id] = frequencies
+		}
+	}
+
+	idf := b.idf(len(occurrences))
+	scores := make(map[string]float64, len(occurrences))
+	for id, frequencies := range occurrences {
+		scores[id] = b.bm25(idf, frequencies, b.documents[id])
+	}
+	return scores
+}
+
+func (b *MemoryBackend) phraseContinues(id string, field int, terms []string, position int) bool {
+	for offset, term := range terms {
+		if !containsInt(b.index[term][id][field], position+offset+1) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsInt(values []int, value int) bool {
+	i := sort.SearchInts(values, value)
+	return i < len(values) && values[i] == value
+}
+
+func (b *MemoryBackend) idf(documentFrequency int) float64 {
+	n := float64(len(b.documents))
+	df := float64(documentFrequency)
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// bm25 combines the term frequency of every field, each normalized by the field length, into one score
+func (b *MemoryBackend) bm25(idf float64, frequencies [fieldCount]int, document *memoryDocument) float64 {
+	var score float64
+	for field, frequency := range frequencies {
+		if frequency == 0 {
+			continue
+		}
+		averageLength := float64(b.totalLengths[field]) / float64(len(b.documents))
+		lengthRatio := 1.0
+		if averageLength > 0 {
+			lengthRatio = float64(document.lengths[field]) / averageLength
+		}
+		tf := float64(frequency)
+		score += fieldWeights[field] * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*lengthRatio))
+	}
+	return idf * score
+}
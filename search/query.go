@@ -0,0 +1,45 @@
+package search
+
+import "strings"
+
+// clause is a single requirement of a parsed query: a word, a word prefix or a sequence of words
+type clause struct {
+	terms  []string
+	prefix bool
+}
+
+func (cl clause) isPhrase() bool {
+	return len(cl.terms) > 1
+}
+
+// parseQuery splits query text into clauses. Text inside double quotes is a phrase and a word ending with *
+// matches every word starting with it. A word the tokenizer splits, such as node.js, is treated as a phrase
+func parseQuery(text string) []clause {
+	var clauses []clause
+	for i, part := range strings.Split(text, `"`) {
+		if i%2 == 1 {
+			if terms := termsOf(part); len(terms) > 0 {
+				clauses = append(clauses, clause{terms: terms})
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			prefix := strings.HasSuffix(word, "*")
+			terms := termsOf(strings.TrimRight(word, "*"))
+			if len(terms) == 0 {
+				continue
+			}
+			clauses = append(clauses, clause{terms: terms, prefix: prefix && len(terms) == 1})
+		}
+	}
+	return clauses
+}
+
+func termsOf(text string) []string {
+	tokens := Tokenize(text)
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = token.Term
+	}
+	return terms
+}
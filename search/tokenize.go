@@ -0,0 +1,78 @@
+package search
+
+import (
+	"go_blogs/libs"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Token is a normalized word of a text along with its byte range in the original text
+type Token struct {
+	Term     string
+	Start    int
+	End      int
+	Position int
+}
+
+var foldLatinMarks = transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+func isTokenRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// Tokenize splits text into lowercase words. Thai is segmented into dictionary words, and accents are folded
+// off Latin words so that "café" matches "cafe"
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range text {
+		if isTokenRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = appendToken(tokens, text, start, i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = appendToken(tokens, text, start, len(text))
+	}
+	return tokens
+}
+
+func appendToken(tokens []Token, text string, start int, end int) []Token {
+	for _, word := range libs.SegmentThai(text[start:end]) {
+		end = start + len(word)
+		if strings.Trim(word, "ๆฯ") != "" {
+			tokens = append(tokens, Token{
+				Term:     normalizeTerm(word),
+				Start:    start,
+				End:      end,
+				Position: len(tokens),
+			})
+		}
+		start = end
+	}
+	return tokens
+}
+
+func normalizeTerm(word string) string {
+	term := strings.ToLower(word)
+	// marks are part of the spelling in scripts such as Thai, so only Latin words are folded
+	for _, r := range term {
+		if r > unicode.MaxLatin1 && !unicode.Is(unicode.Latin, r) && !unicode.Is(unicode.Mn, r) {
+			return term
+		}
+	}
+	if folded, _, err := transform.String(foldLatinMarks, term); err == nil {
+		return folded
+	}
+	return term
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "pure thai", text: "ฉันรักภาษาไทย", want: []string{"ฉัน", "รัก", "ภาษา", "ไทย"}},
+		{name: "thai and latin without spaces", text: "เขียนGoง่ายมาก", want: []string{"เขียน", "go", "ง่าย", "มาก"}},
+		{name: "thai and latin with punctuation", text: "ใช้ React กับ Next.js", want: []string{"ใช้", "react", "กับ", "next", "js"}},
+		{name: "digits", text: "ประเทศไทยมีจังหวัด77จังหวัด", want: []string{"ประเทศ", "ไทย", "มี", "จังหวัด", "77", "จังหวัด"}},
+		{name: "punctuation dropped", text: "สวัสดี, โลก!", want: []string{"สวัสดี", "โลก"}},
+		{name: "thai marks dropped", text: "เด็กๆ ไปกรุงเทพฯ", want: []string{"เด็ก", "ไป", "กรุงเทพ"}},
+		{name: "latin accents folded", text: "Café au lait", want: []string{"cafe", "au", "lait"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := Tokenize(tt.text)
+			got := make([]string, len(tokens))
+			for i, token := range tokens {
+				got[i] = token.Term
+				if token.Position != i {
+					t.Errorf("token %q is at position %d, want %d", token.Term, token.Position, i)
+				}
+				if term := normalizeTerm(tt.text[token.Start:token.End]); term != token.Term {
+					t.Errorf("token %q spans %q", token.Term, tt.text[token.Start:token.End])
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryBackendIndexesTokens(t *testing.T) {
+	ctx := context.TODO()
+	backend := NewMemoryBackend()
+	document := Document{
+		ID:      "blog",
+		Title:   "เขียนGoง่ายมาก",
+		Content: "ใช้ React กับ Next.js ราคา 250 บาท",
+	}
+	if err := backend.Index(ctx, document); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{}
+	for _, text := range []string{document.Title, document.Content} {
+		for _, token := range Tokenize(text) {
+			want[token.Term] = true
+		}
+	}
+	var wantTerms []string
+	for term := range want {
+		wantTerms = append(wantTerms, term)
+	}
+	gotTerms := append([]string(nil), backend.documents[document.ID].terms...)
+	sort.Strings(wantTerms)
+	sort.Strings(gotTerms)
+	if !reflect.DeepEqual(gotTerms, wantTerms) {
+		t.Errorf("index stores terms %q, want %q", gotTerms, wantTerms)
+	}
+
+	for _, query := range []string{"ง่าย", "go", "เขียนgo", "next.js", "250", "บาท", `"กับ next"`} {
+		result, err := backend.Search(ctx, Query{Text: query, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Total != 1 {
+			t.Errorf("search for %q finds %d blogs, want 1", query, result.Total)
+		}
+	}
+	result, err := backend.Search(ctx, Query{Text: "ภาษา", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 0 {
+		t.Errorf("search for a word not in the blog finds %d blogs", result.Total)
+	}
+}
@@ -11,14 +11,39 @@ func getValidationErrorMessageFromTag(err validator.FieldError) string {
 	switch err.Tag() {
 	case "required":
 		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is empty", err.Param())
 	case "email":
 		return "is invalid email"
+	case "url":
+		return "is invalid URL"
 	case "mongodb":
 		return "is invalid ID"
+	case "slug":
+		return "is invalid slug, use lowercase letters, digits and hyphens"
+	case "len":
+		return fmt.Sprintf("must be %s characters long", err.Param())
+	case "numeric":
+		return "must be numeric"
+	case "hexadecimal":
+		return "must be hexadecimal"
 	case "min":
 		return fmt.Sprintf("must be longer than %s", err.Param())
 	case "max":
 		return fmt.Sprintf("must be shorter than %s", err.Param())
+	case "gte":
+		return fmt.Sprintf("must be at least %s", err.Param())
+	case "gt":
+		if err.Param() == "" {
+			return "must be in the future"
+		}
+		return fmt.Sprintf("must be greater than %s", err.Param())
+	case "gtfield":
+		return fmt.Sprintf("must be after %s", err.Param())
+	case "datetime":
+		return fmt.Sprintf("must be a date formatted as %s", err.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", err.Param())
 	}
 	return "is invalid"
 }
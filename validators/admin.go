@@ -0,0 +1,130 @@
+package validators
+
+import (
+	"go_blogs/constants"
+	"go_blogs/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Query
+type GetUsersQuery struct {
+	Q      string `json:"q" validate:"omitempty,max=100"`
+	Role   string `json:"role" validate:"omitempty,oneof=user editor moderator admin"`
+	Status string `json:"status" validate:"omitempty,oneof=active suspended"`
+	From   int    `json:"from" validate:"gte=0"`
+	Limit  int    `json:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+type GetAuditLogsQuery struct {
+	Actor  string `json:"actor" validate:"omitempty,mongodb"`
+	Target string `json:"target" validate:"omitempty,mongodb"`
+	Action string `json:"action" validate:"omitempty,oneof=impersonation.start impersonation.stop"`
+	From   int    `json:"from" validate:"gte=0"`
+	Limit  int    `json:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+// Params
+type AdminUserParams struct {
+	ID string `json:"id" validate:"required,mongodb"`
+}
+
+// Body
+type UpdateUserRolePayload struct {
+	Role        string   `json:"role" validate:"required,oneof=user editor moderator admin"`
+	Permissions []string `json:"permissions" validate:"omitempty,max=10,dive,oneof=blogs:view_any blogs:edit_any blogs:publish_any blogs:delete_any categories:manage users:manage_roles users:manage users:impersonate"`
+}
+
+type SuspendUserPayload struct {
+	Reason string `json:"reason" validate:"omitempty,max=300"`
+}
+
+func ValidateAdminQuery(routeName string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var query interface{}
+
+		switch routeName {
+		case constants.RouteName.GET_USERS:
+			query = new(GetUsersQuery)
+		case constants.RouteName.GET_AUDIT_LOGS:
+			query = new(GetAuditLogsQuery)
+		}
+
+		if err := c.QueryParser(query); err != nil {
+			return utils.NewAppError(err)
+		}
+
+		errors := validate.Struct(query)
+
+		formattedErrorResponse := utils.TransformValidationErrorFormat(errors)
+
+		if len(formattedErrorResponse) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(formattedErrorResponse)
+		}
+
+		c.Locals("query", query)
+
+		return c.Next()
+	}
+}
+
+func ValidateAdminParams(routeName string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var params interface{}
+
+		switch routeName {
+		case constants.RouteName.GET_USER_STATS,
+			constants.RouteName.UPDATE_USER_ROLE,
+			constants.RouteName.SUSPEND_USER,
+			constants.RouteName.UNSUSPEND_USER,
+			constants.RouteName.FORCE_PASSWORD_RESET,
+			constants.RouteName.IMPERSONATE_USER:
+			params = new(AdminUserParams)
+		}
+
+		if err := c.ParamsParser(params); err != nil {
+			return utils.NewAppError(err)
+		}
+
+		errors := validate.Struct(params)
+
+		formattedErrorResponse := utils.TransformValidationErrorFormat(errors)
+
+		if len(formattedErrorResponse) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(formattedErrorResponse)
+		}
+
+		c.Locals("params", params)
+
+		return c.Next()
+	}
+}
+
+func ValidateAdminPayload(routeName string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var body interface{}
+
+		switch routeName {
+		case constants.RouteName.UPDATE_USER_ROLE:
+			body = new(UpdateUserRolePayload)
+		case constants.RouteName.SUSPEND_USER:
+			body = new(SuspendUserPayload)
+		}
+
+		if err := c.BodyParser(body); err != nil {
+			return utils.NewAppError(err)
+		}
+
+		errors := validate.Struct(body)
+
+		formattedErrorResponse := utils.TransformValidationErrorFormat(errors)
+
+		if len(formattedErrorResponse) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(formattedErrorResponse)
+		}
+
+		c.Locals("payload", body)
+
+		return c.Next()
+	}
+}
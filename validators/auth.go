@@ -18,6 +18,38 @@ type RegisterPayload struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6,max=32"`
 	Name     string `json:"name" validate:"required"`
+	Handle   string `json:"handle" validate:"omitempty,max=30,slug"`
+}
+
+type VerifyEmailPayload struct {
+	Token string `json:"token" validate:"required,max=512"`
+}
+
+type ResendEmailVerificationPayload struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPasswordPayload struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordPayload struct {
+	Token    string `json:"token" validate:"required,max=64"`
+	Password string `json:"password" validate:"required,min=6,max=32"`
+}
+
+type UnlockLoginPayload struct {
+	Token string `json:"token" validate:"required,max=64"`
+}
+
+type LoginTwoFactorPayload struct {
+	Challenge    string `json:"challenge" validate:"required,max=64"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recoveryCode" validate:"omitempty,max=32"`
+}
+
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refreshToken" validate:"required,max=128"`
 }
 
 var validate *validator.Validate = validator.New()
@@ -31,6 +63,20 @@ func ValidateAuthPayload(routeName string) func(*fiber.Ctx) error {
 			body = new(LoginPayload)
 		case constants.RouteName.REGISTER:
 			body = new(RegisterPayload)
+		case constants.RouteName.VERIFY_EMAIL:
+			body = new(VerifyEmailPayload)
+		case constants.RouteName.RESEND_EMAIL_VERIFICATION:
+			body = new(ResendEmailVerificationPayload)
+		case constants.RouteName.FORGOT_PASSWORD:
+			body = new(ForgotPasswordPayload)
+		case constants.RouteName.RESET_PASSWORD:
+			body = new(ResetPasswordPayload)
+		case constants.RouteName.UNLOCK_LOGIN:
+			body = new(UnlockLoginPayload)
+		case constants.RouteName.LOGIN_TWO_FACTOR:
+			body = new(LoginTwoFactorPayload)
+		case constants.RouteName.REFRESH_TOKEN:
+			body = new(RefreshTokenPayload)
 		}
 
 		if err := c.BodyParser(body); err != nil {
@@ -2,14 +2,67 @@ package validators
 
 import (
 	"go_blogs/constants"
+	"go_blogs/libs"
 	"go_blogs/utils"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 // Query
 type GetBlogsQuery struct {
-	From int `json:"from" validate:"gte=0"`
+	Cursor   string `json:"cursor" validate:"omitempty,max=512"`
+	Limit    int    `json:"limit" validate:"omitempty,min=1,max=50"`
+	From     int    `json:"from" validate:"gte=0"`
+	Tag      string `json:"tag" validate:"omitempty,max=32"`
+	Category string `json:"category" validate:"omitempty,max=80"`
+	Expand   string `json:"expand" validate:"omitempty,oneof=author"`
+}
+
+type SearchBlogsQuery struct {
+	Q      string `json:"q" validate:"required,max=200"`
+	From   int    `json:"from" validate:"gte=0"`
+	Limit  int    `json:"limit" validate:"omitempty,min=1,max=50"`
+	Author string `json:"author" validate:"omitempty,mongodb"`
+	Tag    string `json:"tag" validate:"omitempty,max=32"`
+	Since  string `json:"since" validate:"omitempty,datetime=2006-01-02"`
+	Until  string `json:"until" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type GetBlogByIDQuery struct {
+	Expand string `json:"expand" validate:"omitempty,oneof=author"`
+}
+
+type GetUserBlogsQuery struct {
+	Cursor string `json:"cursor" validate:"omitempty,max=512"`
+	Limit  int    `json:"limit" validate:"omitempty,min=1,max=50"`
+	Expand string `json:"expand" validate:"omitempty,oneof=author"`
+}
+
+type GetTagsQuery struct {
+	Limit int `json:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type GetMyBlogsQuery struct {
+	From   int    `json:"from" validate:"gte=0"`
+	Limit  int    `json:"limit" validate:"omitempty,min=1,max=50"`
+	Status string `json:"status" validate:"omitempty,oneof=draft in_review published archived"`
+}
+
+type GetMyTrashQuery struct {
+	From  int `json:"from" validate:"gte=0"`
+	Limit int `json:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+type GetBlogRevisionsQuery struct {
+	From  int `json:"from" validate:"gte=0"`
+	Limit int `json:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+type DiffBlogRevisionsQuery struct {
+	From int `json:"from" validate:"required,gte=1"`
+	To   int `json:"to" validate:"required,gte=1"`
 }
 
 // Params
@@ -17,6 +70,14 @@ type GetBlogByIDParams struct {
 	ID string `json:"id" validate:"mongodb"`
 }
 
+type GetBlogBySlugParams struct {
+	Slug string `json:"slug" validate:"required,max=80"`
+}
+
+type GetUserBlogsParams struct {
+	ID string `json:"id" validate:"required,mongodb"`
+}
+
 type UpdateBlogParams struct {
 	ID string `json:"id" validate:"mongodb"`
 }
@@ -25,15 +86,77 @@ type DeleteBlogParams struct {
 	ID string `json:"id" validate:"mongodb"`
 }
 
+type ChangeBlogStatusParams struct {
+	ID string `json:"id" validate:"mongodb"`
+}
+
+type RestoreBlogParams struct {
+	ID string `json:"id" validate:"mongodb"`
+}
+
+type GetBlogRevisionsParams struct {
+	ID string `json:"id" validate:"mongodb"`
+}
+
+type GetBlogRevisionParams struct {
+	ID  string `json:"id" validate:"mongodb"`
+	Rev int    `json:"rev" validate:"gte=1"`
+}
+
+type DiffBlogRevisionsParams struct {
+	ID string `json:"id" validate:"mongodb"`
+}
+
+type RestoreBlogRevisionParams struct {
+	ID  string `json:"id" validate:"mongodb"`
+	Rev int    `json:"rev" validate:"gte=1"`
+}
+
 // Body
 type CreateBlogPayload struct {
-	Title   string `json:"title" validate:"required,min=10"`
-	Content string `json:"content" validate:"required"`
+	Title       string     `json:"title" validate:"required,min=10"`
+	Content     string     `json:"content" validate:"required,max=100000"`
+	PublishAt   *time.Time `json:"publishAt" validate:"omitempty,gt"`
+	UnpublishAt *time.Time `json:"unpublishAt" validate:"omitempty,gt"`
+	Slug        string     `json:"slug" validate:"omitempty,max=80,slug"`
+	Tags        []string   `json:"tags" validate:"omitempty,max=10,dive,max=32"`
+	CategoryID  string     `json:"categoryId" validate:"omitempty,mongodb"`
 }
 
 type UpdateBlogPayload struct {
-	Title   string `json:"title" validate:"required,min=10"`
-	Content string `json:"content" validate:"required"`
+	Title       string     `json:"title" validate:"required,min=10"`
+	Content     string     `json:"content" validate:"required,max=100000"`
+	PublishAt   *time.Time `json:"publishAt" validate:"omitempty,gt"`
+	UnpublishAt *time.Time `json:"unpublishAt" validate:"omitempty,gt"`
+	Slug        string     `json:"slug" validate:"omitempty,max=80,slug"`
+	Tags        []string   `json:"tags" validate:"omitempty,max=10,dive,max=32"`
+	CategoryID  string     `json:"categoryId" validate:"omitempty,mongodb"`
+}
+
+func init() {
+	validate.RegisterStructValidation(validateBlogSchedule, CreateBlogPayload{}, UpdateBlogPayload{})
+	if err := validate.RegisterValidation("slug", validateSlug); err != nil {
+		panic(err)
+	}
+}
+
+func validateSlug(fl validator.FieldLevel) bool {
+	return libs.SlugPattern.MatchString(fl.Field().String())
+}
+
+// validateBlogSchedule ensures a blog is not scheduled to be unpublished before it is published
+func validateBlogSchedule(sl validator.StructLevel) {
+	var publishAt, unpublishAt *time.Time
+	switch payload := sl.Current().Interface().(type) {
+	case CreateBlogPayload:
+		publishAt, unpublishAt = payload.PublishAt, payload.UnpublishAt
+	case UpdateBlogPayload:
+		publishAt, unpublishAt = payload.PublishAt, payload.UnpublishAt
+	}
+
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		sl.ReportError(unpublishAt, "UnpublishAt", "unpublishAt", "gtfield", "PublishAt")
+	}
 }
 
 func ValidateBlogQuery(routeName string) func(*fiber.Ctx) error {
@@ -43,6 +166,22 @@ func ValidateBlogQuery(routeName string) func(*fiber.Ctx) error {
 		switch routeName {
 		case constants.RouteName.GET_BLOGS:
 			query = new(GetBlogsQuery)
+		case constants.RouteName.GET_BLOG_BY_ID:
+			query = new(GetBlogByIDQuery)
+		case constants.RouteName.SEARCH_BLOGS:
+			query = new(SearchBlogsQuery)
+		case constants.RouteName.GET_USER_BLOGS:
+			query = new(GetUserBlogsQuery)
+		case constants.RouteName.GET_TAGS:
+			query = new(GetTagsQuery)
+		case constants.RouteName.GET_MY_BLOGS:
+			query = new(GetMyBlogsQuery)
+		case constants.RouteName.GET_MY_TRASH:
+			query = new(GetMyTrashQuery)
+		case constants.RouteName.GET_BLOG_REVISIONS:
+			query = new(GetBlogRevisionsQuery)
+		case constants.RouteName.DIFF_BLOG_REVISIONS:
+			query = new(DiffBlogRevisionsQuery)
 		}
 
 		if err := c.QueryParser(query); err != nil {
@@ -70,10 +209,29 @@ func ValidateBlogParams(routeName string) func(*fiber.Ctx) error {
 		switch routeName {
 		case constants.RouteName.GET_BLOG_BY_ID:
 			params = new(GetBlogByIDParams)
+		case constants.RouteName.GET_BLOG_BY_SLUG:
+			params = new(GetBlogBySlugParams)
+		case constants.RouteName.GET_USER_BLOGS:
+			params = new(GetUserBlogsParams)
 		case constants.RouteName.UPDATE_BLOG:
 			params = new(UpdateBlogParams)
 		case constants.RouteName.DELETE_BLOG:
 			params = new(DeleteBlogParams)
+		case constants.RouteName.SUBMIT_BLOG,
+			constants.RouteName.PUBLISH_BLOG,
+			constants.RouteName.UNPUBLISH_BLOG,
+			constants.RouteName.ARCHIVE_BLOG:
+			params = new(ChangeBlogStatusParams)
+		case constants.RouteName.RESTORE_BLOG:
+			params = new(RestoreBlogParams)
+		case constants.RouteName.GET_BLOG_REVISIONS:
+			params = new(GetBlogRevisionsParams)
+		case constants.RouteName.GET_BLOG_REVISION:
+			params = new(GetBlogRevisionParams)
+		case constants.RouteName.DIFF_BLOG_REVISIONS:
+			params = new(DiffBlogRevisionsParams)
+		case constants.RouteName.RESTORE_BLOG_REVISION:
+			params = new(RestoreBlogRevisionParams)
 		}
 
 		if err := c.ParamsParser(params); err != nil {
@@ -0,0 +1,88 @@
+package validators
+
+import (
+	"go_blogs/constants"
+	"go_blogs/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Params
+type UpdateCategoryParams struct {
+	ID string `json:"id" validate:"mongodb"`
+}
+
+type DeleteCategoryParams struct {
+	ID string `json:"id" validate:"mongodb"`
+}
+
+// Body
+type CreateCategoryPayload struct {
+	Name     string `json:"name" validate:"required,max=50"`
+	Slug     string `json:"slug" validate:"omitempty,max=80,slug"`
+	ParentID string `json:"parentId" validate:"omitempty,mongodb"`
+}
+
+type UpdateCategoryPayload struct {
+	Name     string `json:"name" validate:"required,max=50"`
+	Slug     string `json:"slug" validate:"omitempty,max=80,slug"`
+	ParentID string `json:"parentId" validate:"omitempty,mongodb"`
+}
+
+func ValidateCategoryParams(routeName string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var params interface{}
+
+		switch routeName {
+		case constants.RouteName.UPDATE_CATEGORY:
+			params = new(UpdateCategoryParams)
+		case constants.RouteName.DELETE_CATEGORY:
+			params = new(DeleteCategoryParams)
+		}
+
+		if err := c.ParamsParser(params); err != nil {
+			return utils.NewAppError(err)
+		}
+
+		errors := validate.Struct(params)
+
+		formattedErrorResponse := utils.TransformValidationErrorFormat(errors)
+
+		if len(formattedErrorResponse) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(formattedErrorResponse)
+		}
+
+		c.Locals("params", params)
+
+		return c.Next()
+	}
+}
+
+func ValidateCategoryPayload(routeName string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var body interface{}
+
+		switch routeName {
+		case constants.RouteName.CREATE_CATEGORY:
+			body = new(CreateCategoryPayload)
+		case constants.RouteName.UPDATE_CATEGORY:
+			body = new(UpdateCategoryPayload)
+		}
+
+		if err := c.BodyParser(body); err != nil {
+			return utils.NewAppError(err)
+		}
+
+		errors := validate.Struct(body)
+
+		formattedErrorResponse := utils.TransformValidationErrorFormat(errors)
+
+		if len(formattedErrorResponse) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(formattedErrorResponse)
+		}
+
+		c.Locals("payload", body)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,179 @@
+package validators
+
+import (
+	"go_blogs/constants"
+	"go_blogs/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Params
+type GetUserParams struct {
+	ID string `json:"id" validate:"required,mongodb"`
+}
+
+type GetUserByHandleParams struct {
+	Handle string `json:"handle" validate:"required,max=30"`
+}
+
+type DeletePasskeyParams struct {
+	ID string `json:"id" validate:"required,mongodb"`
+}
+
+type DeleteMySessionParams struct {
+	ID string `json:"id" validate:"required,len=32,hexadecimal"`
+}
+
+type DeleteAccessTokenParams struct {
+	ID string `json:"id" validate:"required,mongodb"`
+}
+
+// Query
+type FinishPasskeyRegistrationQuery struct {
+	Name string `json:"name" validate:"omitempty,max=50"`
+}
+
+type DeleteMySessionsQuery struct {
+	ExceptCurrent bool `json:"exceptCurrent"`
+}
+
+// Body
+type UpdateMePayload struct {
+	Name      *string `json:"name" validate:"omitempty,min=1,max=50"`
+	Handle    *string `json:"handle" validate:"omitempty,max=30,slug"`
+	Bio       *string `json:"bio" validate:"omitempty,max=300"`
+	AvatarURL *string `json:"avatarUrl" validate:"omitempty,max=500,url"`
+}
+
+type ChangePasswordPayload struct {
+	CurrentPassword string `json:"currentPassword" validate:"required,max=32"`
+	NewPassword     string `json:"newPassword" validate:"required,min=6,max=32"`
+}
+
+type ChangeEmailPayload struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,max=32"`
+}
+
+type ConfirmEmailChangePayload struct {
+	Token string `json:"token" validate:"required,max=64"`
+}
+
+type ConfirmTOTPPayload struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type DisableTOTPPayload struct {
+	Password     string `json:"password" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recoveryCode" validate:"omitempty,max=32"`
+}
+
+type CreateAccessTokenPayload struct {
+	Name          string   `json:"name" validate:"required,max=50"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,max=10,dive,oneof=blogs:read blogs:write categories:write"`
+	ExpiresInDays int      `json:"expiresInDays" validate:"omitempty,min=1,max=365"`
+}
+
+func ValidateUserParams(routeName string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var params interface{}
+
+		switch routeName {
+		case constants.RouteName.GET_USER:
+			params = new(GetUserParams)
+		case constants.RouteName.GET_USER_BY_HANDLE:
+			params = new(GetUserByHandleParams)
+		case constants.RouteName.DELETE_PASSKEY:
+			params = new(DeletePasskeyParams)
+		case constants.RouteName.DELETE_MY_SESSION:
+			params = new(DeleteMySessionParams)
+		case constants.RouteName.DELETE_ACCESS_TOKEN:
+			params = new(DeleteAccessTokenParams)
+		}
+
+		if err := c.ParamsParser(params); err != nil {
+			return utils.NewAppError(err)
+		}
+
+		errors := validate.Struct(params)
+
+		formattedErrorResponse := utils.TransformValidationErrorFormat(errors)
+
+		if len(formattedErrorResponse) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(formattedErrorResponse)
+		}
+
+		c.Locals("params", params)
+
+		return c.Next()
+	}
+}
+
+func ValidateUserQuery(routeName string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var query interface{}
+
+		switch routeName {
+		case constants.RouteName.FINISH_PASSKEY_REGISTRATION:
+			query = new(FinishPasskeyRegistrationQuery)
+		case constants.RouteName.DELETE_MY_SESSIONS:
+			query = new(DeleteMySessionsQuery)
+		}
+
+		if err := c.QueryParser(query); err != nil {
+			return utils.NewAppError(err)
+		}
+
+		errors := validate.Struct(query)
+
+		formattedErrorResponse := utils.TransformValidationErrorFormat(errors)
+
+		if len(formattedErrorResponse) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(formattedErrorResponse)
+		}
+
+		c.Locals("query", query)
+
+		return c.Next()
+	}
+}
+
+func ValidateUserPayload(routeName string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		var body interface{}
+
+		switch routeName {
+		case constants.RouteName.UPDATE_ME:
+			body = new(UpdateMePayload)
+		case constants.RouteName.CHANGE_PASSWORD:
+			body = new(ChangePasswordPayload)
+		case constants.RouteName.CHANGE_EMAIL:
+			body = new(ChangeEmailPayload)
+		case constants.RouteName.CONFIRM_EMAIL_CHANGE:
+			body = new(ConfirmEmailChangePayload)
+		case constants.RouteName.CONFIRM_TOTP:
+			body = new(ConfirmTOTPPayload)
+		case constants.RouteName.DISABLE_TOTP:
+			body = new(DisableTOTPPayload)
+		case constants.RouteName.CREATE_ACCESS_TOKEN:
+			body = new(CreateAccessTokenPayload)
+		}
+
+		if err := c.BodyParser(body); err != nil {
+			return utils.NewAppError(err)
+		}
+
+		errors := validate.Struct(body)
+
+		formattedErrorResponse := utils.TransformValidationErrorFormat(errors)
+
+		if len(formattedErrorResponse) > 0 {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(formattedErrorResponse)
+		}
+
+		c.Locals("payload", body)
+
+		return c.Next()
+	}
+}